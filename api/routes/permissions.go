@@ -0,0 +1,146 @@
+package routes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/middleware"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// routePermissions is the permission each protected route requires, keyed by
+// middleware.RouteKey. RBACMiddleware refuses routes missing from this table and SetupRouter
+// refuses to start with one, so every new protected route must be added here.
+var routePermissions = map[string]models.Permission{
+	// Users
+	"GET /api/v1/users/me":             models.PermUsersRead,
+	"GET /api/v1/users":                models.PermUsersRead,
+	"GET /api/v1/users/:id":            models.PermUsersRead,
+	"GET /api/v1/users/msisdn/:msisdn": models.PermUsersRead,
+	"POST /api/v1/users/opt-out":       models.PermUsersWrite,
+
+	// Draws
+	"POST /api/v1/draws":                                                 models.PermDrawsManage,
+	"GET /api/v1/draws":                                                  models.PermDrawsRead,
+	"GET /api/v1/draws/:id":                                              models.PermDrawsRead,
+	"PUT /api/v1/draws/:id":                                              models.PermDrawsManage,
+	"DELETE /api/v1/draws/:id":                                           models.PermDrawsManage,
+	"POST /api/v1/draws/schedule":                                        models.PermDrawsManage,
+	"POST /api/v1/draws/execute/:id":                                     models.PermDrawsExecute,
+	"POST /api/v1/draws/simulate/:id":                                    models.PermDrawsManage,
+	"GET /api/v1/draws/verify/:id":                                       models.PermDrawsRead,
+	"POST /api/v1/draws/void/:id":                                        models.PermDrawsExecute,
+	"POST /api/v1/draws/rerun/:id":                                       models.PermDrawsManage,
+	"GET /api/v1/draws/participants/:id":                                 models.PermDrawsRead,
+	"GET /api/v1/draws/participants/:id/export":                          models.PermParticipantsExport,
+	"GET /api/v1/draws/winners/:id":                                      models.PermDrawsRead,
+	"POST /api/v1/draws/winners/claim/:id":                               models.PermClaimsManage,
+	"POST /api/v1/draws/claims/process-expired":                          models.PermClaimsManage,
+	"GET /api/v1/draws/date/:date":                                       models.PermDrawsRead,
+	"GET /api/v1/draws/default-digits/:day":                              models.PermDrawsRead,
+	"GET /api/v1/draws/config":                                           models.PermDrawsRead,
+	"GET /api/v1/draws/prize-structure":                                  models.PermDrawsRead,
+	"PUT /api/v1/draws/prize-structure":                                  models.PermPrizesManage,
+	"PUT /api/v1/draws/base-jackpot":                                     models.PermPrizesManage,
+	"GET /api/v1/draws/prize-structures/:type/versions":                  models.PermDrawsRead,
+	"POST /api/v1/draws/prize-structures/:type/versions":                 models.PermPrizesManage,
+	"GET /api/v1/draws/prize-structures/:type/diff":                      models.PermDrawsRead,
+	"GET /api/v1/draws/jackpot-status":                                   models.PermDrawsRead,
+	"GET /api/v1/draws/types":                                            models.PermDrawsRead,
+	"POST /api/v1/draws/types":                                           models.PermDrawsConfig,
+	"GET /api/v1/draws/types/:code":                                      models.PermDrawsRead,
+	"PUT /api/v1/draws/types/:code":                                      models.PermDrawsConfig,
+	"PUT /api/v1/draws/types/:code/keys":                                 models.PermPrizesManage,
+	"DELETE /api/v1/draws/types/:code":                                   models.PermDrawsConfig,
+	"GET /api/v1/draws/eligibility-window":                               models.PermDrawsRead,
+	"PUT /api/v1/draws/eligibility-window":                               models.PermDrawsConfig,
+	"GET /api/v1/draws/win-rules":                                        models.PermDrawsRead,
+	"PUT /api/v1/draws/win-rules":                                        models.PermDrawsConfig,
+	"POST /api/v1/draws/prize-structures/:type/versions/:version/submit": models.PermPrizesManage,
+	"POST /api/v1/draws/prize-structures/:type/versions/:version/reject": models.PermPrizesManage,
+
+	// Approvals (approvers also need the permission of the action, checked by the handler)
+	"GET /api/v1/approvals":              models.PermApprovalsRead,
+	"GET /api/v1/approvals/:id":          models.PermApprovalsRead,
+	"POST /api/v1/approvals/:id/approve": models.PermApprovalsReview,
+	"POST /api/v1/approvals/:id/reject":  models.PermApprovalsReview,
+	"POST /api/v1/approvals/expire":      models.PermApprovalsReview,
+
+	// Admin users and roles
+	"GET /api/v1/admins":                      models.PermAdminsManage,
+	"GET /api/v1/admins/roles":                models.PermAdminsManage,
+	"PUT /api/v1/admins/:id/role":             models.PermAdminsManage,
+	"POST /api/v1/admins/:id/disable":         models.PermAdminsManage,
+	"POST /api/v1/admins/:id/enable":          models.PermAdminsManage,
+	"POST /api/v1/admins/:id/revoke-sessions": models.PermAdminsManage,
+	"GET /api/v1/admins/invites":              models.PermAdminsManage,
+	"POST /api/v1/admins/invites":             models.PermAdminsManage,
+	"DELETE /api/v1/admins/invites/:id":       models.PermAdminsManage,
+	"POST /api/v1/admins/:id/2fa/reset":       models.PermAdminsManage,
+	"GET /api/v1/admins/lockouts":             models.PermAdminsManage,
+	"POST /api/v1/admins/lockouts/unlock":     models.PermAdminsManage,
+
+	// Audit log
+	"GET /api/v1/audit":        models.PermAuditRead,
+	"GET /api/v1/audit/verify": models.PermAuditRead,
+
+	// The caller's own two-factor settings
+	"POST /api/v1/auth/2fa/setup":          models.PermOwnAccount,
+	"POST /api/v1/auth/2fa/enable":         models.PermOwnAccount,
+	"POST /api/v1/auth/2fa/disable":        models.PermOwnAccount,
+	"POST /api/v1/auth/2fa/recovery-codes": models.PermOwnAccount,
+
+	// Topups
+	"POST /api/v1/topups":               models.PermTopupsWrite,
+	"GET /api/v1/topups":                models.PermTopupsRead,
+	"GET /api/v1/topups/count":          models.PermTopupsRead,
+	"GET /api/v1/topups/msisdn/:msisdn": models.PermTopupsRead,
+	"GET /api/v1/topups/:id":            models.PermTopupsRead,
+	"POST /api/v1/topups/process":       models.PermTopupsWrite,
+
+	// Notifications
+	"GET /api/v1/notifications":           models.PermNotificationsRead,
+	"GET /api/v1/notifications/campaigns": models.PermNotificationsRead,
+	"GET /api/v1/notifications/templates": models.PermNotificationsRead,
+
+	// Dashboard
+	"GET /api/v1/dashboard/stats": models.PermDashboardRead,
+
+	// Events
+	"GET /api/v1/events":        models.PermEventsRead,
+	"GET /api/v1/events/:id":    models.PermEventsRead,
+	"POST /api/v1/events":       models.PermEventsWrite,
+	"PUT /api/v1/events/:id":    models.PermEventsWrite,
+	"DELETE /api/v1/events/:id": models.PermEventsWrite,
+}
+
+// routeKeys returns the keys of the routes registered on a router
+func routeKeys(router *gin.Engine) map[string]bool {
+	keys := make(map[string]bool)
+	for _, route := range router.Routes() {
+		keys[middleware.RouteKey(route.Method, route.Path)] = true
+	}
+	return keys
+}
+
+// checkRoutePermissions returns an error naming every route registered after the public ones
+// that has no entry in routePermissions, and every entry that matches no route
+func checkRoutePermissions(router *gin.Engine, public map[string]bool) error {
+	registered := routeKeys(router)
+	var problems []string
+	for key := range registered {
+		if _, ok := routePermissions[key]; !ok && !public[key] {
+			problems = append(problems, "no permission for "+key)
+		}
+	}
+	for key := range routePermissions {
+		if !registered[key] {
+			problems = append(problems, "permission for unknown route "+key)
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("route permission table is out of date: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/config"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/handlers"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testJWTSecret = "0123456789abcdef0123456789abcdef"
+
+// testPathParam replaces every path parameter; it is a valid ObjectID so handlers parsing IDs get that far
+const testPathParam = "650000000000000000000001"
+
+// stubAuth accepts every token and records nothing
+type stubAuth struct{}
+
+func (stubAuth) IsTokenRevoked(ctx context.Context, jti string) (bool, error) { return false, nil }
+func (stubAuth) BeginRequest(ctx context.Context, req models.AuditRequest) context.Context {
+	return ctx
+}
+func (stubAuth) FinishRequest(ctx context.Context, status int) {}
+
+// newTestRouter builds the production router with handlers that have no services: a request
+// that passes RBAC reaches a handler that fails, never a 403
+func newTestRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.DefaultWriter, gin.DefaultErrorWriter = io.Discard, io.Discard
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() {
+		gin.DefaultWriter, gin.DefaultErrorWriter = os.Stdout, os.Stderr
+		log.SetOutput(os.Stderr)
+	})
+
+	cfg := &config.Config{}
+	cfg.JWT.Secret = testJWTSecret
+	cfg.Server.Env = "production"
+	return SetupRouter(cfg, HandlerDependencies{
+		AuthHandler:   &handlers.AuthHandler{},
+		TopupHandler:  &handlers.TopupHandler{},
+		TokenDenylist: stubAuth{},
+		AuditRecorder: stubAuth{},
+	})
+}
+
+// accessToken signs an access token for an admin with role
+func accessToken(t *testing.T, role string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   testPathParam,
+		"email": role + "@example.com",
+		"role":  role,
+		"typ":   "access",
+		"jti":   "test-" + role,
+	}).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+// requestPath fills the path parameters of a registered route
+func requestPath(route string) string {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = testPathParam
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func TestRoutePermissionsRefuseRolesWithoutThePermission(t *testing.T) {
+	router := newTestRouter(t)
+	roles := append([]string{models.RoleLegacyAdmin, "unknown_role"}, models.Roles...)
+	tokens := make(map[string]string, len(roles))
+	for _, role := range roles {
+		tokens[role] = accessToken(t, role)
+	}
+
+	for key, permission := range routePermissions {
+		method, route, _ := strings.Cut(key, " ")
+		for _, role := range roles {
+			t.Run(key+" as "+role, func(t *testing.T) {
+				req := httptest.NewRequest(method, requestPath(route), strings.NewReader("{}"))
+				req.Header.Set("Authorization", "Bearer "+tokens[role])
+				req.Header.Set("Content-Type", "application/json")
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				if !models.HasPermission(role, permission) {
+					if w.Code != http.StatusForbidden {
+						t.Fatalf("status = %d, want %d", w.Code, http.StatusForbidden)
+					}
+					var body struct {
+						RequiredPermission models.Permission `json:"required_permission"`
+					}
+					if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.RequiredPermission != permission {
+						t.Fatalf("body = %s, want a refusal naming %s", w.Body.String(), permission)
+					}
+					return
+				}
+				if w.Code == http.StatusForbidden {
+					t.Fatalf("role holding %s was refused: %s", permission, w.Body.String())
+				}
+			})
+		}
+	}
+}
+
+func TestCheckRoutePermissionsRefusesUnlistedRoutes(t *testing.T) {
+	// SetupRouter itself refuses to start when the table is out of date
+	router := newTestRouter(t)
+	router.GET("/api/v1/unlisted", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	err := checkRoutePermissions(router, nil)
+	if err == nil || !strings.Contains(err.Error(), "no permission for GET /api/v1/unlisted") {
+		t.Fatalf("checkRoutePermissions = %v, want the unlisted route named", err)
+	}
+}
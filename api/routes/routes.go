@@ -19,6 +19,12 @@ type HandlerDependencies struct {
 	TopupHandler       *handlers.TopupHandler
 	NotificationHandler *handlers.NotificationHandler
 	EventHandler 		*handlers.EventHandler
+	ApprovalHandler     *handlers.ApprovalHandler
+	AdminHandler        *handlers.AdminHandler
+	AuditHandler        *handlers.AuditHandler
+	WebhookHandler      *handlers.WebhookHandler
+	TokenDenylist       middleware.TokenDenylist // Checked by JWTAuthMiddleware on every protected request
+	AuditRecorder       middleware.AuditRecorder // Records every mutating protected request
 	// Add other handlers as needed
 }
 
@@ -40,13 +46,16 @@ func SetupRouter(cfg *config.Config, deps HandlerDependencies) *gin.Engine {
 	// Recovery middleware
 	router.Use(gin.Recovery())
 
+	// Request ID middleware; the ID is echoed in X-Request-ID and recorded in the audit log
+	router.Use(middleware.RequestIDMiddleware())
+
 	// CORS Middleware - Use the configuration from cfg
 	log.Printf("Configuring CORS with AllowedHosts: %v", cfg.Server.AllowedHosts)
 	corsConfig := cors.Config{
 		AllowOrigins:     cfg.Server.AllowedHosts, // Use AllowedHosts from config
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID"},
+		ExposeHeaders:    []string{"Content-Length", "X-Request-ID"},
 		AllowCredentials: true,
 		AllowOriginFunc: func(origin string) bool {
 			// Allow requests from configured origins
@@ -78,19 +87,45 @@ func SetupRouter(cfg *config.Config, deps HandlerDependencies) *gin.Engine {
 		 auth := public.Group("/auth")
 		{
 			 auth.POST("/login", deps.AuthHandler.Login)
-			 auth.POST("/register", deps.AuthHandler.Register) // Assuming register handler exists
+			 auth.POST("/login/verify", deps.AuthHandler.VerifyLogin)
+			 auth.POST("/login/enroll", deps.AuthHandler.BeginLoginEnrollment)
+			 auth.POST("/refresh", deps.AuthHandler.Refresh)
+			 auth.POST("/logout", deps.AuthHandler.Logout)
+			 auth.POST("/invites/accept", deps.AuthHandler.AcceptInvite) // Admins join by invite only; see /admins/invites
 			 auth.POST("/opt-in", deps.UserHandler.OptIn)
 			// Add other public auth routes like refresh token if needed
 		}
 
+		// Recharge events pushed by MTN; authenticated by their signature
+		 webhooks := public.Group("/webhooks")
+		{
+			 webhooks.POST("/topups", deps.WebhookHandler.ReceiveTopup)
+		}
+
 		// Add other public routes here if any
 		// Example: public.GET("/health", func(c *gin.Context) { c.JSON(200, gin.H{"status": "UP"}) })
 	}
 
-	// Protected routes (authentication required)
+	// Routes registered so far need no authentication
+	publicRoutes := routeKeys(router)
+
+	// Protected routes (authentication required). AuditMiddleware records every mutating request,
+	// including refused ones, and RBACMiddleware checks the caller's role against
+	// routePermissions (permissions.go).
 	protected := router.Group("/api/v1")
-	protected.Use(middleware.JWTAuthMiddleware(cfg)) // Apply JWT authentication middleware
+	protected.Use(middleware.JWTAuthMiddleware(cfg, deps.TokenDenylist)) // Apply JWT authentication middleware
+	protected.Use(middleware.AuditMiddleware(deps.AuditRecorder))
+	protected.Use(middleware.RBACMiddleware(routePermissions))
 	{
+		// The logged-in admin's own two-factor settings
+		 twoFactor := protected.Group("/auth/2fa")
+		{
+			 twoFactor.POST("/setup", deps.AuthHandler.BeginTwoFactorEnrollment)
+			 twoFactor.POST("/enable", deps.AuthHandler.EnableTwoFactor)
+			 twoFactor.POST("/disable", deps.AuthHandler.DisableTwoFactor)
+			 twoFactor.POST("/recovery-codes", deps.AuthHandler.RegenerateRecoveryCodes)
+		}
+
 		 users := protected.Group("/users")
 		{
 			 users.GET("/me", deps.UserHandler.GetMe) // Example protected user route
@@ -110,19 +145,79 @@ func SetupRouter(cfg *config.Config, deps HandlerDependencies) *gin.Engine {
 			 draws.DELETE("/:id", deps.DrawHandler.DeleteDraw)
 			 draws.POST("/schedule", deps.DrawHandler.ScheduleDraw)
 			 draws.POST("/execute/:id", deps.DrawHandler.ExecuteDraw)
+			 draws.POST("/simulate/:id", deps.DrawHandler.SimulateDraw)
+			 draws.GET("/verify/:id", deps.DrawHandler.VerifyDraw)
+			 draws.POST("/void/:id", deps.DrawHandler.VoidDraw)
+			 draws.POST("/rerun/:id", deps.DrawHandler.ReRunDraw)
+			 draws.GET("/participants/:id", deps.DrawHandler.GetDrawParticipants)
+			 draws.GET("/participants/:id/export", deps.DrawHandler.ExportDrawParticipants)
 			 draws.GET("/winners/:id", deps.DrawHandler.GetWinners)
+			 draws.POST("/winners/claim/:id", deps.DrawHandler.ClaimPrize)
+			 draws.POST("/claims/process-expired", deps.DrawHandler.ProcessExpiredClaims)
 			 draws.GET("/date/:date", deps.DrawHandler.GetDrawByDate)
 			 draws.GET("/default-digits/:day", deps.DrawHandler.GetDefaultDigitsForDay)
 			 draws.GET("/config", deps.DrawHandler.GetDrawConfig)
 			 draws.GET("/prize-structure", deps.DrawHandler.GetPrizeStructure)
+			 draws.PUT("/prize-structure", deps.DrawHandler.UpdatePrizeStructure)
+			 draws.PUT("/base-jackpot", deps.DrawHandler.UpdateBaseJackpot)
+			 draws.GET("/prize-structures/:type/versions", deps.DrawHandler.ListPrizeStructureVersions)
+			 draws.POST("/prize-structures/:type/versions", deps.DrawHandler.CreatePrizeStructureVersion)
+			 draws.POST("/prize-structures/:type/versions/:version/submit", deps.DrawHandler.SubmitPrizeStructureVersion)
+			 draws.POST("/prize-structures/:type/versions/:version/reject", deps.DrawHandler.RejectPrizeStructureVersion)
+			 draws.GET("/prize-structures/:type/diff", deps.DrawHandler.DiffPrizeStructureVersions)
+			 draws.GET("/jackpot-status", deps.DrawHandler.GetJackpotStatus)
+			 draws.GET("/types", deps.DrawHandler.ListDrawTypes)
+			 draws.POST("/types", deps.DrawHandler.CreateDrawType)
+			 draws.GET("/types/:code", deps.DrawHandler.GetDrawType)
+			 draws.PUT("/types/:code", deps.DrawHandler.UpdateDrawType)
+			 draws.PUT("/types/:code/keys", deps.DrawHandler.UpdateDrawTypeKeys)
+			 draws.DELETE("/types/:code", deps.DrawHandler.DeleteDrawType)
+			 draws.GET("/eligibility-window", deps.DrawHandler.GetEligibilityWindow)
+			 draws.PUT("/eligibility-window", deps.DrawHandler.UpdateEligibilityWindow)
+			 draws.GET("/win-rules", deps.DrawHandler.GetWinFrequencyRules)
+			 draws.PUT("/win-rules", deps.DrawHandler.UpdateWinFrequencyRules)
 			// Add other draw routes
 		}
 
+		 approvals := protected.Group("/approvals")
+		{
+			 approvals.GET("", deps.ApprovalHandler.ListApprovals)
+			 approvals.GET("/:id", deps.ApprovalHandler.GetApproval)
+			 approvals.POST("/:id/approve", deps.ApprovalHandler.ApproveApproval)
+			 approvals.POST("/:id/reject", deps.ApprovalHandler.RejectApproval)
+			 approvals.POST("/expire", deps.ApprovalHandler.ExpireApprovals)
+		}
+
+		 admins := protected.Group("/admins")
+		{
+			 admins.GET("", deps.AdminHandler.ListAdmins)
+			 admins.GET("/roles", deps.AdminHandler.ListRoles)
+			 admins.PUT("/:id/role", deps.AdminHandler.AssignRole)
+			 admins.POST("/:id/disable", deps.AdminHandler.DisableAdmin)
+			 admins.POST("/:id/enable", deps.AdminHandler.EnableAdmin)
+			 admins.POST("/:id/revoke-sessions", deps.AdminHandler.RevokeSessions)
+			 admins.POST("/:id/2fa/reset", deps.AdminHandler.ResetTwoFactor)
+			 admins.GET("/lockouts", deps.AdminHandler.ListLoginLockouts)
+			 admins.POST("/lockouts/unlock", deps.AdminHandler.UnlockLogin)
+			 admins.GET("/invites", deps.AdminHandler.ListInvites)
+			 admins.POST("/invites", deps.AdminHandler.CreateInvite)
+			 admins.DELETE("/invites/:id", deps.AdminHandler.RevokeInvite)
+		}
+
+		 audit := protected.Group("/audit")
+		{
+			 audit.GET("", deps.AuditHandler.ListEvents)
+			 audit.GET("/verify", deps.AuditHandler.VerifyChain)
+		}
+
 		 topups := protected.Group("/topups")
 		{
 			 topups.POST("", deps.TopupHandler.CreateTopup)
 			 topups.GET("", deps.TopupHandler.GetTopups)
-			// Add other topup routes
+			 topups.GET("/count", deps.TopupHandler.GetTopupCount)
+			 topups.GET("/msisdn/:msisdn", deps.TopupHandler.GetTopupsByMSISDN)
+			 topups.GET("/:id", deps.TopupHandler.GetTopupByID)
+			 topups.POST("/process", deps.TopupHandler.ProcessTopups)
 		}
 
 		 notifications := protected.Group("/notifications")
@@ -150,6 +245,11 @@ func SetupRouter(cfg *config.Config, deps HandlerDependencies) *gin.Engine {
 		}
 	}
 
+	// Refuse to start with a protected route that has no permission
+	 if err := checkRoutePermissions(router, publicRoutes); err != nil {
+		log.Fatalf("[FATAL] SetupRouter: %v", err)
+	 }
+
 	// Handle OPTIONS requests for preflight checks (CORS)
 	// Gin-contrib/cors handles this automatically if configured correctly.
 
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -12,11 +13,13 @@ import (
 	"github.com/ArowuTest/bridgetunes-mtn-backend/api/routes"
 	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/config"
 	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/handlers"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
 	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories" // Interface package
 	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/services"
 
 	// Implementation packages
 	"github.com/ArowuTest/bridgetunes-mtn-backend/pkg/mongodb"    // MongoDB client helper
+	"github.com/ArowuTest/bridgetunes-mtn-backend/pkg/mtnapi"     // MTN recharge API client
 	"github.com/ArowuTest/bridgetunes-mtn-backend/pkg/smsgateway" // SMS Gateway implementations
 
 	// Import the specific repository implementation package
@@ -41,6 +44,12 @@ func main() {
 		log.Println("[ERROR] JWT Secret IS EMPTY after config load!")
 	}
 
+	for _, role := range cfg.TwoFactor.RequiredRoles {
+		if !models.ValidRole(role) {
+			log.Fatalf("TOTP_REQUIRED_ROLES: unknown role %q (expected one of %v)", role, models.Roles)
+		}
+	}
+
 	// Connect to MongoDB using the pkg helper
 	mongoClient, err := mongodb.NewClient(cfg.MongoDB.URI)
 	if err != nil {
@@ -59,9 +68,15 @@ func main() {
 	// Initialize ALL Repositories using the implementation package
 	var userRepo repositories.UserRepository = mongorepo.NewUserRepository(db)
 	var drawRepo repositories.DrawRepository = mongorepo.NewDrawRepository(db)
-	// var topupRepo repositories.TopupRepository = mongorepo.NewTopupRepository(db) // Commented out - Unused
+	var topupRepo repositories.TopupRepository = mongorepo.NewTopupRepository(db)
+	var topupWebhookEventRepo repositories.TopupWebhookEventRepository = mongorepo.NewTopupWebhookEventRepository(db)
 	// var notificationRepo repositories.NotificationRepository = mongorepo.NewNotificationRepository(db) // Commented out - Unused
 	var adminUserRepo repositories.AdminUserRepository = mongorepo.NewAdminUserRepository(db)
+	var adminInviteRepo repositories.AdminInviteRepository = mongorepo.NewAdminInviteRepository(db)
+	var refreshTokenRepo repositories.RefreshTokenRepository = mongorepo.NewRefreshTokenRepository(db)
+	var revokedTokenRepo repositories.RevokedTokenRepository = mongorepo.NewRevokedTokenRepository(db)
+	var loginAttemptRepo repositories.LoginAttemptRepository = mongorepo.NewLoginAttemptRepository(db)
+	var auditRepo repositories.AuditRepository = mongorepo.NewAuditRepository(db)
 	var winnerRepo repositories.WinnerRepository = mongorepo.NewWinnerRepository(db) // Added Winner Repo
 	// var templateRepo repositories.TemplateRepository = mongorepo.NewTemplateRepository(db) // Commented out - Unused
 	// var campaignRepo repositories.CampaignRepository = mongorepo.NewCampaignRepository(db) // Commented out - Unused
@@ -72,9 +87,15 @@ func main() {
 	var pointTransactionRepo repositories.PointTransactionRepository = mongorepo.NewPointTransactionRepository(db)
 	var jackpotRolloverRepo repositories.JackpotRolloverRepository = mongorepo.NewJackpotRolloverRepository(db)
 	var eventRepo repositories.EventRepository = mongorepo.NewEventRepository(db)
+	var drawParticipantRepo repositories.DrawParticipantRepository = mongorepo.NewDrawParticipantRepository(db)
+	var drawTypeRepo repositories.DrawTypeRepository = mongorepo.NewDrawTypeRepository(db)
+	var prizeStructureRepo repositories.PrizeStructureRepository = mongorepo.NewPrizeStructureRepository(db)
+	var approvalRepo repositories.ApprovalRepository = mongorepo.NewApprovalRepository(db)
+	var leaderLockRepo repositories.LeaderLockRepository = mongorepo.NewLeaderLockRepository(db)
+	var txManager repositories.TransactionManager = mongorepo.NewTransactionManager(db)
 
 	// Initialize External Clients
-	// mtnClient := mtnapi.NewClient(cfg.MTN.BaseURL, cfg.MTN.APIKey, cfg.MTN.APISecret, cfg.MTN.MockAPI) // Commented out - Currently unused
+	mtnClient := mtnapi.NewClient(cfg.MTN.BaseURL, cfg.MTN.APIKey, cfg.MTN.APISecret, cfg.MTN.MockAPI) // Recharge feed for topup ingestion
 
 	// Initialize SMS Gateways
 	var mtnGateway smsgateway.Gateway // Corrected typo
@@ -90,14 +111,15 @@ func main() {
 
 	// Initialize Services using Legacy constructors with ALL dependencies
 	// Note: Ensure the service instances are stored with the correct type for dependency injection
-	authService := services.NewAuthService(adminUserRepo, cfg.JWT.Secret, cfg.JWT.ExpiresIn) // Pass JWT secret and expiration
-	legacyUserService := services.NewLegacyUserService(userRepo)
+	auditService := services.NewAuditService(auditRepo) // Append-only, hash-chained audit log
+	authService := services.NewAuthService(adminUserRepo, adminInviteRepo, refreshTokenRepo, revokedTokenRepo, loginAttemptRepo, auditService, cfg.JWT.Secret, cfg.JWT.ExpiresIn, cfg.JWT.RefreshExpiresIn, cfg.TwoFactor.Issuer, cfg.TwoFactor.RequiredRoles, loginLimitOptions(cfg.LoginLimits)) // Pass JWT secret, expirations, two-factor and login limit settings
+	legacyUserService := services.NewLegacyUserService(userRepo, auditService)
 	// Pass blacklistRepo and systemConfigRepo to NewDrawService
 	// Use correct constructor name: NewDrawService instead of NewLegacyDrawService
-	drawServiceInstance := services.NewDrawService(drawRepo, userRepo, winnerRepo, blacklistRepo, systemConfigRepo, pointTransactionRepo, jackpotRolloverRepo) // Added missing pointTransactionRepo, jackpotRolloverRepo
+	drawServiceInstance := services.NewDrawService(drawRepo, userRepo, winnerRepo, blacklistRepo, systemConfigRepo, pointTransactionRepo, jackpotRolloverRepo, drawParticipantRepo, drawTypeRepo, prizeStructureRepo, txManager, auditService) // Added missing pointTransactionRepo, jackpotRolloverRepo
 	// Use correct constructor name: NewTopupService instead of NewLegacyTopupService
-	// Pass correct arguments: userRepo, pointTransactionRepo, drawServiceInstance
-	topupServiceInstance := services.NewTopupService(userRepo, pointTransactionRepo, drawServiceInstance)
+	// Topups are ingested from the MTN feed; the checkpoint lives in system config and runs hold a leader lock
+	topupServiceInstance := services.NewTopupService(topupRepo, topupWebhookEventRepo, userRepo, pointTransactionRepo, systemConfigRepo, leaderLockRepo, drawServiceInstance, mtnClient)
 	// Use correct arguments for NewLegacyNotificationService: userRepo, mtnGateway, kodobeGateway, cfg.SMS.DefaultGateway
 	legacyNotificationService := services.NewLegacyNotificationService(
 		userRepo, // Corrected: Pass userRepo
@@ -113,14 +135,20 @@ func main() {
 	var topupService services.TopupService = topupServiceInstance // Use the new instance
 	var notificationService services.NotificationService = legacyNotificationService
 	var eventService services.EventServiceInterface = eventServiceInstance
+	// Execute, void, prize-structure, base-jackpot and draw type key changes wait for a second admin's approval
+	var approvalService services.ApprovalService = services.NewApprovalService(approvalRepo, drawService, time.Duration(cfg.Approvals.TTLHours)*time.Hour)
 
 	// Initialize Handlers (Assuming handlers accept interface types)
 	authHandler := handlers.NewAuthHandler(authService)
-	drawHandler := handlers.NewDrawHandler(drawService)
+	drawHandler := handlers.NewDrawHandler(drawService, approvalService)
 	topupHandler := handlers.NewTopupHandler(topupService)
 	notificationHandler := handlers.NewNotificationHandler(notificationService)
 	userHandler := handlers.NewUserHandler(userService)
 	eventHandler := handlers.NewEventHandler(eventService)
+	approvalHandler := handlers.NewApprovalHandler(approvalService)
+	adminHandler := handlers.NewAdminHandler(authService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	webhookHandler := handlers.NewWebhookHandler(topupService, cfg.MTN.WebhookSecret, time.Duration(cfg.MTN.WebhookMaxSkewSeconds)*time.Second)
 	// Add other handlers as needed
 
 	// Create Handler Dependencies struct (Assuming it uses interface types)
@@ -131,11 +159,70 @@ func main() {
 		TopupHandler:        topupHandler,
 		NotificationHandler: notificationHandler,
 		EventHandler:        eventHandler,
+		ApprovalHandler:     approvalHandler,
+		AdminHandler:        adminHandler,
+		AuditHandler:        auditHandler,
+		WebhookHandler:      webhookHandler,
+		TokenDenylist:       authService,
+		AuditRecorder:       auditService,
 		// Add other handlers here if they are defined in HandlerDependencies
 		// Add missing handlers based on routes.go if needed
 		// Example: BlacklistHandler, SystemConfigHandler, WinnerHandler
 	}
 
+	// Recover draws left EXECUTING by a process that died mid-execution
+	recoverCtx, cancelRecover := context.WithTimeout(context.Background(), 30*time.Second)
+	// Unique transactionRef indexes keep a top-up from being recorded or credited twice
+	if err := topupRepo.EnsureIndexes(recoverCtx); err != nil {
+		log.Printf("[ERROR] Failed to ensure topup indexes: %v", err)
+	}
+	if err := pointTransactionRepo.EnsureIndexes(recoverCtx); err != nil {
+		log.Printf("[ERROR] Failed to ensure point transaction indexes: %v", err)
+	}
+	if err := drawParticipantRepo.EnsureIndexes(recoverCtx); err != nil {
+		log.Printf("[ERROR] Failed to ensure draw participant indexes: %v", err)
+	}
+	// A unique version index keeps concurrent drafts from taking the same prize structure version
+	if err := prizeStructureRepo.EnsureIndexes(recoverCtx); err != nil {
+		log.Printf("[ERROR] Failed to ensure prize structure indexes: %v", err)
+	}
+	// A unique kind and value index keeps a burst of failed logins on one counter
+	if err := loginAttemptRepo.EnsureIndexes(recoverCtx); err != nil {
+		log.Printf("[ERROR] Failed to ensure login attempt indexes: %v", err)
+	}
+	// A unique msisdn index keeps concurrent ingestion from creating a subscriber twice
+	if err := userRepo.EnsureIndexes(recoverCtx); err != nil {
+		log.Printf("[ERROR] Failed to ensure user indexes: %v", err)
+	}
+	// Register the DAILY and SATURDAY draw types on first start
+	if err := drawService.EnsureDefaultDrawTypes(recoverCtx); err != nil {
+		log.Printf("[ERROR] Failed to register default draw types: %v", err)
+	}
+	if recovered, err := drawService.RecoverStaleDraws(recoverCtx, time.Now()); err != nil {
+		log.Printf("[ERROR] Failed to recover stale draws: %v", err)
+	} else if recovered > 0 {
+		log.Printf("[WARN] Recovered %d draws stuck in EXECUTING", recovered)
+	}
+	cancelRecover()
+
+	// Start the background draw scheduler (only the replica holding the leader lock acts)
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+	if cfg.Scheduler.Enabled {
+		schedulerOpts, err := drawSchedulerOptions(cfg.Scheduler)
+		if err != nil {
+			log.Fatalf("Invalid scheduler configuration: %v", err)
+		}
+		drawScheduler := services.NewDrawScheduler(drawService, approvalService, drawRepo, leaderLockRepo, schedulerOpts)
+		go drawScheduler.Run(schedulerCtx)
+	}
+
+	// Process recharges queued by the topup webhook (every replica takes part)
+	go topupServiceInstance.RunWebhookQueue(schedulerCtx, services.TopupWebhookOptions{MaxAttempts: cfg.MTN.WebhookMaxAttempts})
+	if cfg.MTN.WebhookSecret == "" {
+		log.Println("[WARN] MTN_WEBHOOK_SECRET is not set; POST /api/v1/webhooks/topups refuses all events")
+	}
+
 	// Setup Router using the centralized function from routes package
 	router := routes.SetupRouter(cfg, handlerDeps)
 
@@ -165,6 +252,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
+	stopScheduler()
 
 	// Create a context with a timeout for graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -178,4 +266,32 @@ func main() {
 	log.Println("Server exiting")
 }
 
+// loginLimitOptions converts the login limit configuration into service options
+func loginLimitOptions(cfg config.LoginLimitConfig) services.LoginLimitOptions {
+	return services.LoginLimitOptions{
+		MaxFailures:      cfg.MaxFailures,
+		MaxFailuresPerIP: cfg.MaxFailuresPerIP,
+		Lockout:          time.Duration(cfg.LockoutMinutes) * time.Minute,
+		BackoffBase:      time.Duration(cfg.BackoffBaseSeconds) * time.Second,
+	}
+}
 
+// drawSchedulerOptions converts the scheduler configuration into service options
+func drawSchedulerOptions(cfg config.SchedulerConfig) (services.DrawSchedulerOptions, error) {
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return services.DrawSchedulerOptions{}, fmt.Errorf("invalid scheduler timezone %q: %w", cfg.Timezone, err)
+	}
+	executeAt, err := time.Parse("15:04", cfg.ExecuteAt)
+	if err != nil {
+		return services.DrawSchedulerOptions{}, fmt.Errorf("invalid scheduler execute time %q (expected HH:MM): %w", cfg.ExecuteAt, err)
+	}
+	return services.DrawSchedulerOptions{
+		DaysAhead:    cfg.DaysAhead,
+		Interval:     time.Duration(cfg.IntervalSeconds) * time.Second,
+		LockTTL:      time.Duration(cfg.LockTTLSeconds) * time.Second,
+		AutoExecute:  cfg.AutoExecute,
+		ExecuteAfter: time.Duration(executeAt.Hour())*time.Hour + time.Duration(executeAt.Minute())*time.Minute,
+		Location:     loc,
+	}, nil
+}
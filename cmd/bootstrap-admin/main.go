@@ -0,0 +1,72 @@
+// Command bootstrap-admin creates the first super admin of a new deployment. Every later admin
+// joins through an invite (POST /api/v1/admins/invites).
+//
+// It uses the API's configuration (MONGODB_URI, MONGODB_DATABASE, ...) and reads the account
+// from BOOTSTRAP_ADMIN_EMAIL, BOOTSTRAP_ADMIN_PASSWORD, BOOTSTRAP_ADMIN_FIRST_NAME and
+// BOOTSTRAP_ADMIN_LAST_NAME. It refuses to run once an enabled super admin exists.
+//
+//	go run ./cmd/bootstrap-admin
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/config"
+	mongorepo "github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories/mongodb"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/services"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/pkg/mongodb"
+)
+
+// requireEnv returns an environment variable or exits when it is unset
+func requireEnv(name string) string {
+	value := os.Getenv(name)
+	if value == "" {
+		log.Fatalf("%s environment variable is required", name)
+	}
+	return value
+}
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	email := requireEnv("BOOTSTRAP_ADMIN_EMAIL")
+	password := requireEnv("BOOTSTRAP_ADMIN_PASSWORD")
+	firstName := requireEnv("BOOTSTRAP_ADMIN_FIRST_NAME")
+	lastName := requireEnv("BOOTSTRAP_ADMIN_LAST_NAME")
+
+	mongoClient, err := mongodb.NewClient(cfg.MongoDB.URI)
+	if err != nil {
+		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	defer mongoClient.Disconnect(context.Background())
+	db := mongoClient.Database(cfg.MongoDB.Database)
+
+	authService := services.NewAuthService(
+		mongorepo.NewAdminUserRepository(db),
+		mongorepo.NewAdminInviteRepository(db),
+		mongorepo.NewRefreshTokenRepository(db),
+		mongorepo.NewRevokedTokenRepository(db),
+		mongorepo.NewLoginAttemptRepository(db),
+		services.NewAuditService(mongorepo.NewAuditRepository(db)),
+		cfg.JWT.Secret,
+		cfg.JWT.ExpiresIn,
+		cfg.JWT.RefreshExpiresIn,
+		cfg.TwoFactor.Issuer,
+		cfg.TwoFactor.RequiredRoles,
+		services.LoginLimitOptions{}, // The bootstrap command does not log in
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	admin, err := authService.BootstrapSuperAdmin(ctx, email, password, firstName, lastName)
+	if err != nil {
+		log.Fatalf("Failed to create super admin: %v", err)
+	}
+	log.Printf("Created super admin %s (%s)", admin.Email, admin.ID.Hex())
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AdminHandler handles admin user management HTTP requests
+type AdminHandler struct {
+	authService services.AuthService
+}
+
+// NewAdminHandler creates a new AdminHandler
+func NewAdminHandler(authService services.AuthService) *AdminHandler {
+	return &AdminHandler{
+		authService: authService,
+	}
+}
+
+// ListAdmins handles GET /admins
+func (h *AdminHandler) ListAdmins(c *gin.Context) {
+	admins, err := h.authService.ListAdmins(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list admins: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, admins)
+}
+
+// ListRoles handles GET /admins/roles and returns the permission matrix
+func (h *AdminHandler) ListRoles(c *gin.Context) {
+	roles := make(map[string][]models.Permission, len(models.Roles))
+	for _, role := range models.Roles {
+		roles[role] = models.RolePermissions[role]
+	}
+	roles[models.RoleSuperAdmin] = []models.Permission{"*"}
+	c.JSON(http.StatusOK, roles)
+}
+
+// AssignRoleRequest is the body of PUT /admins/:id/role
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// AssignRole handles PUT /admins/:id/role
+func (h *AdminHandler) AssignRole(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	var req AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	admin, err := h.authService.AssignRole(c.Request.Context(), id, req.Role, adminSubject(c))
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admin user not found"})
+	case errors.Is(err, services.ErrInvalidRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrLastSuperAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign role: " + err.Error()})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Role assigned; it applies from the admin's next login or token refresh", "admin": admin})
+	}
+}
+
+// inviteErrorStatus maps invite errors to HTTP status codes
+func inviteErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInviteNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrAdminExists), errors.Is(err, services.ErrInviteAlreadyOpen), errors.Is(err, services.ErrInviteNotOpen):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrInvalidRole), errors.Is(err, services.ErrInvalidInviteExpiry):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// ListInvites handles GET /admins/invites
+func (h *AdminHandler) ListInvites(c *gin.Context) {
+	invites, err := h.authService.ListInvites(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list invites: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, invites)
+}
+
+// CreateInvite handles POST /admins/invites. The token is only returned here; pass it to the
+// invitee, who accepts it at POST /auth/invites/accept.
+func (h *AdminHandler) CreateInvite(c *gin.Context) {
+	var req models.CreateInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	invite, token, err := h.authService.CreateInvite(c.Request.Context(), &req, adminSubject(c))
+	if err != nil {
+		c.JSON(inviteErrorStatus(err), gin.H{"error": "Failed to create invite: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"invite": invite, "token": token})
+}
+
+// RevokeInvite handles DELETE /admins/invites/:id
+func (h *AdminHandler) RevokeInvite(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	invite, err := h.authService.RevokeInvite(c.Request.Context(), id, adminSubject(c))
+	if err != nil {
+		c.JSON(inviteErrorStatus(err), gin.H{"error": "Failed to revoke invite: " + err.Error(), "invite": invite})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invite revoked", "invite": invite})
+}
+
+// setDisabled disables or re-enables the admin in the path
+func (h *AdminHandler) setDisabled(c *gin.Context, disabled bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	admin, err := h.authService.SetDisabled(c.Request.Context(), id, disabled, adminSubject(c))
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admin user not found"})
+	case errors.Is(err, services.ErrSelfDisable), errors.Is(err, services.ErrLastSuperAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update admin: " + err.Error()})
+	default:
+		c.JSON(http.StatusOK, admin)
+	}
+}
+
+// DisableAdmin handles POST /admins/:id/disable. Every session of the admin is revoked.
+func (h *AdminHandler) DisableAdmin(c *gin.Context) {
+	h.setDisabled(c, true)
+}
+
+// EnableAdmin handles POST /admins/:id/enable
+func (h *AdminHandler) EnableAdmin(c *gin.Context) {
+	h.setDisabled(c, false)
+}
+
+// RevokeSessions handles POST /admins/:id/revoke-sessions, logging the admin out everywhere
+func (h *AdminHandler) RevokeSessions(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	err = h.authService.RevokeSessions(c.Request.Context(), id, adminSubject(c))
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admin user not found"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions: " + err.Error()})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Sessions revoked"})
+	}
+}
+
+// ResetTwoFactor handles POST /admins/:id/2fa/reset for an admin who lost their authenticator
+func (h *AdminHandler) ResetTwoFactor(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	admin, err := h.authService.ResetTOTP(c.Request.Context(), id, adminSubject(c))
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admin user not found"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset two-factor authentication: " + err.Error()})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication reset", "admin": admin})
+	}
+}
+
+// ListLoginLockouts handles GET /admins/lockouts and returns the locked emails and IPs
+func (h *AdminHandler) ListLoginLockouts(c *gin.Context) {
+	lockouts, err := h.authService.ListLoginLockouts(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list lockouts: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, lockouts)
+}
+
+// UnlockLogin handles POST /admins/lockouts/unlock
+func (h *AdminHandler) UnlockLogin(c *gin.Context) {
+	var req models.UnlockLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Email == "" && req.IP == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email or ip is required"})
+		return
+	}
+	err := h.authService.UnlockLogin(c.Request.Context(), req.Email, req.IP, adminSubject(c))
+	switch {
+	case errors.Is(err, services.ErrNothingToUnlock):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock: " + err.Error()})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Unlocked"})
+	}
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/middleware"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ApprovalHandler handles maker-checker approval HTTP requests
+type ApprovalHandler struct {
+	approvalService services.ApprovalService
+}
+
+// NewApprovalHandler creates a new ApprovalHandler
+func NewApprovalHandler(approvalService services.ApprovalService) *ApprovalHandler {
+	return &ApprovalHandler{
+		approvalService: approvalService,
+	}
+}
+
+// ReviewApprovalRequest is the optional body of the approve and reject endpoints
+type ReviewApprovalRequest struct {
+	Note string `json:"note"`
+}
+
+// approvalErrorStatus maps approval errors to HTTP status codes
+func approvalErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrApprovalNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrSelfApproval):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrApprovalNotPending):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// ListApprovals handles GET /approvals?status=PENDING
+func (h *ApprovalHandler) ListApprovals(c *gin.Context) {
+	status := models.ApprovalStatus(strings.ToUpper(c.Query("status")))
+	approvals, err := h.approvalService.ListApprovals(c.Request.Context(), status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list approvals: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, approvals)
+}
+
+// GetApproval handles GET /approvals/:id
+func (h *ApprovalHandler) GetApproval(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	approval, err := h.approvalService.GetApproval(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(approvalErrorStatus(err), gin.H{"error": "Failed to get approval: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, approval)
+}
+
+// reviewBody parses the optional review note
+func reviewBody(c *gin.Context) (ReviewApprovalRequest, bool) {
+	var req ReviewApprovalRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return req, false
+		}
+	}
+	return req, true
+}
+
+// mayReview checks that the caller's role holds the permission of the requested action, so
+// that e.g. only finance or super admins approve prize changes
+func (h *ApprovalHandler) mayReview(c *gin.Context, id primitive.ObjectID) bool {
+	approval, err := h.approvalService.GetApproval(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(approvalErrorStatus(err), gin.H{"error": "Failed to get approval: " + err.Error()})
+		return false
+	}
+	permission := models.ApprovalActionPermission(approval.Action)
+	if !models.HasPermission(middleware.RoleFromContext(c), permission) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your role does not allow reviewing this action", "required_permission": permission})
+		return false
+	}
+	return true
+}
+
+// ApproveApproval handles POST /approvals/:id/approve. The approved action runs immediately;
+// a failed action is reported with 500 and the request is left FAILED.
+func (h *ApprovalHandler) ApproveApproval(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	req, ok := reviewBody(c)
+	if !ok {
+		return
+	}
+	if !h.mayReview(c, id) {
+		return
+	}
+	approval, err := h.approvalService.Approve(c.Request.Context(), id, adminSubject(c), req.Note)
+	if err != nil {
+		status := approvalErrorStatus(err)
+		if approval != nil && approval.Status == models.ApprovalStatusFailed {
+			status = http.StatusInternalServerError
+		}
+		c.JSON(status, gin.H{"error": "Failed to approve: " + err.Error(), "approval": approval})
+		return
+	}
+	if approval.Status == models.ApprovalStatusPending {
+		c.JSON(http.StatusAccepted, gin.H{"message": "Endorsed; a second admin must approve", "approval": approval})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Approved and carried out", "approval": approval})
+}
+
+// RejectApproval handles POST /approvals/:id/reject
+func (h *ApprovalHandler) RejectApproval(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+	req, ok := reviewBody(c)
+	if !ok {
+		return
+	}
+	if !h.mayReview(c, id) {
+		return
+	}
+	approval, err := h.approvalService.Reject(c.Request.Context(), id, adminSubject(c), req.Note)
+	if err != nil {
+		c.JSON(approvalErrorStatus(err), gin.H{"error": "Failed to reject: " + err.Error(), "approval": approval})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Rejected", "approval": approval})
+}
+
+// ExpireApprovals handles POST /approvals/expire
+func (h *ApprovalHandler) ExpireApprovals(c *gin.Context) {
+	expired, err := h.approvalService.ExpirePending(c.Request.Context(), time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to expire approvals: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"expired": expired})
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler handles audit log HTTP requests
+type AuditHandler struct {
+	auditService services.AuditService
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(auditService services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// parseAuditTime parses an RFC 3339 timestamp or a YYYY-MM-DD date. A date given as the end
+// of a range covers the whole day.
+func parseAuditTime(value string, end bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// ListEvents handles GET /audit?action=&actor_id=&target_type=&target_id=&request_id=&from=&to=&page=&limit=
+func (h *AuditHandler) ListEvents(c *gin.Context) {
+	from, err := parseAuditTime(c.Query("from"), false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from (use RFC 3339 or YYYY-MM-DD)"})
+		return
+	}
+	to, err := parseAuditTime(c.Query("to"), true)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to (use RFC 3339 or YYYY-MM-DD)"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	filter := models.AuditFilter{
+		Action:     c.Query("action"),
+		ActorID:    c.Query("actor_id"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		RequestID:  c.Query("request_id"),
+		From:       from,
+		To:         to,
+	}
+	events, total, err := h.auditService.ListEvents(c.Request.Context(), filter, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list audit events: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"events": events, "total": total, "page": page, "limit": limit})
+}
+
+// VerifyChain handles GET /audit/verify and checks the hash chain of the whole log
+func (h *AuditHandler) VerifyChain(c *gin.Context) {
+	result, err := h.auditService.VerifyChain(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify audit log: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
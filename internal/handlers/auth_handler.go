@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
 	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/services"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // AuthHandler handles authentication related HTTP requests
@@ -25,33 +29,28 @@ func NewAuthHandler(authService services.AuthService) *AuthHandler {
 	}
 }
 
-// Register handles POST /auth/register
-func (h *AuthHandler) Register(c *gin.Context) {
-	var req models.RegisterRequest
-
-	// Bind JSON request body to the RegisterRequest struct
+// AcceptInvite handles POST /auth/invites/accept. The invitee sets their name and password
+// with the token from their invite; they log in normally afterwards.
+func (h *AuthHandler) AcceptInvite(c *gin.Context) {
+	var req models.AcceptInviteRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		// If binding fails, return a bad request error
-		// c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
-		// More detailed error:
-		 c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	// Call the Register method on the authService
-	user, err := h.authService.Register(c.Request.Context(), &req)
-	// Check for errors from the service
-	 if err != nil {
-	 	// Return an internal server error (or specific error based on service logic)
-	 	// c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user"})
-	 	// More detailed error:
-	 	 c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-	 	return
-	 }
-
-	// Return the newly created user (excluding password) with status 201 Created
-	// Note: The service should ideally handle removing the password before returning
-	 c.JSON(http.StatusCreated, user)
+	user, err := h.authService.AcceptInvite(c.Request.Context(), &req)
+	switch {
+	case errors.Is(err, services.ErrInviteNotFound), errors.Is(err, services.ErrInviteNotOpen):
+		// Don't tell unknown tokens apart from spent ones
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired invite"})
+	case errors.Is(err, services.ErrWeakPassword):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAdminExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invite: " + err.Error()})
+	default:
+		c.JSON(http.StatusCreated, user)
+	}
 }
 
 // Login handles POST /auth/login
@@ -69,9 +68,16 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	 	return
 	 }
 
-	// Call the Login method on the authService, now expecting token and user
-	token, user, err := h.authService.Login(c.Request.Context(), &req)
+	// Call the Login method on the authService, now expecting tokens and user or a challenge
+	result, err := h.authService.Login(c.Request.Context(), &req, c.ClientIP())
 	// Check for errors (e.g., invalid credentials)
+	 if respondThrottled(c, err) {
+	 	return
+	 }
+	 if errors.Is(err, services.ErrAccountDisabled) {
+	 	 c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	 	return
+	 }
 	 if err != nil {
 	 	// Return an unauthorized error
 	 	// c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
@@ -80,9 +86,211 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	 	return
 	 }
 
-	// Return the JWT token AND user object with status 200 OK
-	 c.JSON(http.StatusOK, gin.H{"token": token, "user": user}) // Return token AND user object
+	// Return the tokens AND user object (or the two-factor challenge) with status 200 OK
+	 c.JSON(http.StatusOK, loginResponse(result))
+}
+
+// respondThrottled answers 429 with a Retry-After header when a login attempt was refused
+// after too many failures
+func respondThrottled(c *gin.Context, err error) bool {
+	var throttled *services.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		return false
+	}
+	retryAfter := int(math.Ceil(throttled.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": throttled.Error(), "retryAfter": retryAfter, "locked": throttled.Locked})
+	return true
+}
+
+// loginResponse renders a login result: either the tokens and user, or a two-factor
+// challenge to finish at POST /auth/login/verify
+func loginResponse(result *models.LoginResult) gin.H {
+	if result.MFARequired {
+		return gin.H{
+			"mfaRequired":        true,
+			"enrollmentRequired": result.EnrollmentRequired, // Start with POST /auth/login/enroll
+			"mfaToken":           result.MFAToken,
+		}
+	}
+	response := gin.H{
+		"token":            result.Tokens.Token,
+		"refreshToken":     result.Tokens.RefreshToken,
+		"tokenType":        result.Tokens.TokenType,
+		"expiresIn":        result.Tokens.ExpiresIn,
+		"refreshExpiresIn": result.Tokens.RefreshExpiresIn,
+		"user":             result.User,
+	}
+	if len(result.RecoveryCodes) > 0 {
+		response["recoveryCodes"] = result.RecoveryCodes // Only shown once
+	}
+	return response
+}
+
+// twoFactorErrorStatus maps two-factor errors to HTTP status codes
+func twoFactorErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidMFAToken), errors.Is(err, services.ErrInvalidTOTPCode):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrAccountDisabled), errors.Is(err, services.ErrTOTPRequired):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrTOTPAlreadyEnabled), errors.Is(err, services.ErrTOTPNotEnabled), errors.Is(err, services.ErrTOTPNotEnrolling):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// VerifyLogin handles POST /auth/login/verify, the second login step. The code is a TOTP code
+// or a recovery code; for an admin finishing enrolment the response includes recovery codes.
+func (h *AuthHandler) VerifyLogin(c *gin.Context) {
+	var req models.VerifyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	result, err := h.authService.VerifyLogin(c.Request.Context(), &req, c.ClientIP())
+	if respondThrottled(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, loginResponse(result))
+}
+
+// BeginLoginEnrollment handles POST /auth/login/enroll for admins whose role requires
+// two-factor authentication and who have not enrolled yet
+func (h *AuthHandler) BeginLoginEnrollment(c *gin.Context) {
+	var req models.MFATokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	enrollment, err := h.authService.BeginLoginEnrollment(c.Request.Context(), req.MFAToken)
+	if err != nil {
+		c.JSON(twoFactorErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// currentAdminID returns the ID of the logged-in admin, or responds 401
+func currentAdminID(c *gin.Context) (primitive.ObjectID, bool) {
+	id, err := primitive.ObjectIDFromHex(adminSubject(c))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token subject"})
+		return primitive.NilObjectID, false
+	}
+	return id, true
+}
+
+// BeginTwoFactorEnrollment handles POST /auth/2fa/setup for the logged-in admin. Confirm
+// with a code at POST /auth/2fa/enable.
+func (h *AuthHandler) BeginTwoFactorEnrollment(c *gin.Context) {
+	adminID, ok := currentAdminID(c)
+	if !ok {
+		return
+	}
+	enrollment, err := h.authService.BeginTOTPEnrollment(c.Request.Context(), adminID)
+	if err != nil {
+		c.JSON(twoFactorErrorStatus(err), gin.H{"error": "Failed to start two-factor enrolment: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// EnableTwoFactor handles POST /auth/2fa/enable and returns the recovery codes
+func (h *AuthHandler) EnableTwoFactor(c *gin.Context) {
+	adminID, ok := currentAdminID(c)
+	if !ok {
+		return
+	}
+	var req models.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	codes, err := h.authService.ConfirmTOTPEnrollment(c.Request.Context(), adminID, req.Code)
+	if err != nil {
+		c.JSON(twoFactorErrorStatus(err), gin.H{"error": "Failed to enable two-factor authentication: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication enabled", "recoveryCodes": codes})
+}
+
+// DisableTwoFactor handles POST /auth/2fa/disable
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	adminID, ok := currentAdminID(c)
+	if !ok {
+		return
+	}
+	var req models.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.authService.DisableTOTP(c.Request.Context(), adminID, req.Code); err != nil {
+		c.JSON(twoFactorErrorStatus(err), gin.H{"error": "Failed to disable two-factor authentication: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
 }
 
+// RegenerateRecoveryCodes handles POST /auth/2fa/recovery-codes
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	adminID, ok := currentAdminID(c)
+	if !ok {
+		return
+	}
+	var req models.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	codes, err := h.authService.RegenerateRecoveryCodes(c.Request.Context(), adminID, req.Code)
+	if err != nil {
+		c.JSON(twoFactorErrorStatus(err), gin.H{"error": "Failed to regenerate recovery codes: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recoveryCodes": codes})
+}
 
+// Refresh handles POST /auth/refresh. The refresh token is rotated: the response holds a new
+// one and the old one stops working.
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tokens, err := h.authService.Refresh(c.Request.Context(), req.RefreshToken)
+	switch {
+	case errors.Is(err, services.ErrInvalidRefreshToken), errors.Is(err, services.ErrAccountDisabled), errors.Is(err, services.ErrTOTPRequired):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token: " + err.Error()})
+	default:
+		c.JSON(http.StatusOK, tokens)
+	}
+}
 
+// Logout handles POST /auth/logout. It revokes the session of the refresh token, so its
+// access tokens stop working too.
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	err := h.authService.Logout(c.Request.Context(), req.RefreshToken)
+	switch {
+	case errors.Is(err, services.ErrInvalidRefreshToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out: " + err.Error()})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+	}
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"encoding/csv"
 	"errors" // Added missing import
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,16 +18,36 @@ import (
 
 // DrawHandler handles draw-related HTTP requests
 type DrawHandler struct {
-	 drawService services.DrawService
+	 drawService     services.DrawService
+	 approvalService services.ApprovalService // Execute, void, prize-structure, base-jackpot and draw type key changes need a second admin
 }
 
 // NewDrawHandler creates a new DrawHandler
-func NewDrawHandler(drawService services.DrawService) *DrawHandler {
+func NewDrawHandler(drawService services.DrawService, approvalService services.ApprovalService) *DrawHandler {
 	return &DrawHandler{
-		 drawService: drawService,
+		 drawService:     drawService,
+		 approvalService: approvalService,
 	}
 }
 
+// respondApprovalRequested answers a request that was turned into a maker-checker approval
+// request: 202 with the pending request, or 409 with the one already pending
+func respondApprovalRequested(c *gin.Context, action string, approval *models.ApprovalRequest, err error) {
+	 if errors.Is(err, services.ErrApprovalAlreadyPending) {
+		 c.JSON(http.StatusConflict, gin.H{"error": "Failed to request " + action + ": " + err.Error(), "approval": approval})
+		 return
+	 }
+	 if errors.Is(err, services.ErrUnknownDrawType) || errors.Is(err, services.ErrPrizeStructureVersionNotFound) || errors.Is(err, mongo.ErrNoDocuments) {
+		 c.JSON(http.StatusNotFound, gin.H{"error": "Failed to request " + action + ": " + err.Error()})
+		 return
+	 }
+	 if err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to request " + action + ": " + err.Error()})
+		 return
+	 }
+	 c.JSON(http.StatusAccepted, gin.H{"message": "The " + action + " is waiting for a second admin's approval", "approval": approval})
+}
+
 // Helper function to parse weekday string (case-insensitive)
 func parseWeekday(dayStr string) (time.Weekday, bool) {
 	 dayStrLower := strings.ToLower(dayStr)
@@ -72,7 +94,7 @@ func (h *DrawHandler) GetDrawByID(c *gin.Context) {
 // ScheduleDraw handles POST /draws/schedule
 type ScheduleDrawRequest struct {
 	DrawDate       string `json:"draw_date" binding:"required"`
-	DrawType       string `json:"draw_type" binding:"required"` // DAILY or SATURDAY
+	DrawType       string `json:"draw_type" binding:"required"` // Code of a registered draw type, e.g. DAILY
 	EligibleDigits []int  `json:"eligible_digits"`
 	UseDefault     bool   `json:"use_default"`
 }
@@ -88,13 +110,13 @@ func (h *DrawHandler) ScheduleDraw(c *gin.Context) {
 		 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid draw date format (YYYY-MM-DD)"})
 		 return
 	 }
-	 if request.DrawType != "DAILY" && request.DrawType != "SATURDAY" {
-		 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid draw type (DAILY or SATURDAY)"})
-		 return
-	 }
 
 	 // Service layer now handles default digit logic based on UseDefault flag
 	 draw, err := h.drawService.ScheduleDraw(c.Request.Context(), drawDate, request.DrawType, request.EligibleDigits, request.UseDefault)
+	 if errors.Is(err, services.ErrUnknownDrawType) {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to schedule draw: " + err.Error()})
+		 return
+	 }
 	 if err != nil {
 		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule draw: " + err.Error()})
 		 return
@@ -102,21 +124,337 @@ func (h *DrawHandler) ScheduleDraw(c *gin.Context) {
 	 c.JSON(http.StatusCreated, draw)
 }
 
-// ExecuteDraw handles POST /draws/:id/execute
+// ExecuteDraw handles POST /draws/execute/:id by requesting a second admin's approval. It
+// answers 202 with the pending approval request; the draw runs when the request is approved.
 func (h *DrawHandler) ExecuteDraw(c *gin.Context) {
 	 id, err := primitive.ObjectIDFromHex(c.Param("id"))
 	 if err != nil {
 		 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
 		 return
 	 }
-	 // ExecuteDraw now returns the updated draw object or error
-	 executedDraw, err := h.drawService.ExecuteDraw(c.Request.Context(), id)
+	 // Execution needs a second admin: this only files the approval request.
+	 // The optional body carries the requester's justification.
+	 var req struct {
+		 Reason string `json:"reason"`
+	 }
+	 if c.Request.ContentLength > 0 {
+		 if err := c.ShouldBindJSON(&req); err != nil {
+			 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			 return
+		 }
+	 }
+	 approval, err := h.approvalService.RequestDrawExecution(c.Request.Context(), id, req.Reason, adminSubject(c))
+	 respondApprovalRequested(c, "draw execution", approval, err)
+}
+
+// SimulateDraw handles POST /draws/simulate/:id?iterations=N&participants=M
+func (h *DrawHandler) SimulateDraw(c *gin.Context) {
+	 id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	 if err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		 return
+	 }
+	 iterations, err := strconv.Atoi(c.DefaultQuery("iterations", "0"))
+	 if err != nil || iterations < 0 {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid iterations"})
+		 return
+	 }
+	 participants, err := strconv.Atoi(c.DefaultQuery("participants", "100"))
+	 if err != nil || participants < 1 {
+		 participants = 100
+	 }
+	 if participants > 1000 {
+		 participants = 1000
+	 }
+	 simulation, err := h.drawService.SimulateDraw(c.Request.Context(), id, iterations, participants)
+	 if err != nil {
+		 status := http.StatusInternalServerError
+		 if errors.Is(err, services.ErrInvalidSimulation) {
+			 status = http.StatusBadRequest
+		 } else if errors.Is(err, services.ErrDrawNotScheduled) {
+			 status = http.StatusConflict
+		 }
+		 c.JSON(status, gin.H{"error": "Failed to simulate draw: " + err.Error()})
+		 return
+	 }
+	 c.JSON(http.StatusOK, simulation)
+}
+
+// ListDrawTypes handles GET /draws/types
+func (h *DrawHandler) ListDrawTypes(c *gin.Context) {
+	 drawTypes, err := h.drawService.ListDrawTypes(c.Request.Context())
+	 if err != nil {
+		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list draw types: " + err.Error()})
+		 return
+	 }
+	 c.JSON(http.StatusOK, drawTypes)
+}
+
+// GetDrawType handles GET /draws/types/:code
+func (h *DrawHandler) GetDrawType(c *gin.Context) {
+	 drawType, err := h.drawService.GetDrawType(c.Request.Context(), c.Param("code"))
+	 if errors.Is(err, services.ErrUnknownDrawType) {
+		 c.JSON(http.StatusNotFound, gin.H{"error": "Draw type not found"})
+		 return
+	 }
+	 if err != nil {
+		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get draw type: " + err.Error()})
+		 return
+	 }
+	 c.JSON(http.StatusOK, drawType)
+}
+
+// CreateDrawType handles POST /draws/types
+func (h *DrawHandler) CreateDrawType(c *gin.Context) {
+	 var drawType models.DrawTypeDefinition
+	 if err := c.ShouldBindJSON(&drawType); err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		 return
+	 }
+	 created, err := h.drawService.CreateDrawType(c.Request.Context(), &drawType)
+	 if err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to create draw type: " + err.Error()})
+		 return
+	 }
+	 c.JSON(http.StatusCreated, created)
+}
+
+// UpdateDrawType handles PUT /draws/types/:code. Changing the prize structure or base jackpot
+// key is refused with 409; use PUT /draws/types/:code/keys.
+func (h *DrawHandler) UpdateDrawType(c *gin.Context) {
+	 var drawType models.DrawTypeDefinition
+	 if err := c.ShouldBindJSON(&drawType); err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		 return
+	 }
+	 updated, err := h.drawService.UpdateDrawType(c.Request.Context(), c.Param("code"), &drawType)
+	 if err != nil {
+		 status := http.StatusBadRequest
+		 if errors.Is(err, services.ErrUnknownDrawType) {
+			 status = http.StatusNotFound
+		 } else if errors.Is(err, services.ErrDrawTypeKeysNeedApproval) {
+			 status = http.StatusConflict
+		 }
+		 c.JSON(status, gin.H{"error": "Failed to update draw type: " + err.Error()})
+		 return
+	 }
+	 c.JSON(http.StatusOK, updated)
+}
+
+// UpdateDrawTypeKeysRequest is the body of PUT /draws/types/:code/keys ("" keeps a key)
+type UpdateDrawTypeKeysRequest struct {
+	PrizeStructureKey string `json:"prizeStructureKey"`
+	BaseJackpotKey    string `json:"baseJackpotKey"`
+	Reason            string `json:"reason"`
+}
+
+// UpdateDrawTypeKeys handles PUT /draws/types/:code/keys. The keys change once a second admin
+// approves it.
+func (h *DrawHandler) UpdateDrawTypeKeys(c *gin.Context) {
+	 var request UpdateDrawTypeKeysRequest
+	 if err := c.ShouldBindJSON(&request); err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		 return
+	 }
+	 approval, err := h.approvalService.RequestDrawTypeKeysChange(c.Request.Context(), c.Param("code"), request.PrizeStructureKey, request.BaseJackpotKey, request.Reason, adminSubject(c))
+	 respondApprovalRequested(c, "draw type key change", approval, err)
+}
+
+// DeleteDrawType handles DELETE /draws/types/:code
+func (h *DrawHandler) DeleteDrawType(c *gin.Context) {
+	 err := h.drawService.DeleteDrawType(c.Request.Context(), c.Param("code"))
+	 if errors.Is(err, services.ErrUnknownDrawType) {
+		 c.JSON(http.StatusNotFound, gin.H{"error": "Draw type not found"})
+		 return
+	 }
+	 if err != nil {
+		 c.JSON(http.StatusConflict, gin.H{"error": "Failed to delete draw type: " + err.Error()})
+		 return
+	 }
+	 c.JSON(http.StatusOK, gin.H{"message": "Draw type deleted successfully"})
+}
+
+// GetEligibilityWindow handles GET /draws/eligibility-window?draw_type=X
+func (h *DrawHandler) GetEligibilityWindow(c *gin.Context) {
+	 drawType := c.Query("draw_type")
+	 if drawType == "" {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Missing draw_type query parameter"})
+		 return
+	 }
+	 rule, err := h.drawService.GetEligibilityWindow(c.Request.Context(), drawType)
+	 if err != nil {
+		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get eligibility window: " + err.Error()})
+		 return
+	 }
+	 c.JSON(http.StatusOK, rule)
+}
+
+// UpdateEligibilityWindowRequest is the body of PUT /draws/eligibility-window
+type UpdateEligibilityWindowRequest struct {
+	DrawType string                       `json:"draw_type" binding:"required"`
+	Window   models.EligibilityWindowRule `json:"window" binding:"required"`
+}
+
+// UpdateEligibilityWindow handles PUT /draws/eligibility-window
+func (h *DrawHandler) UpdateEligibilityWindow(c *gin.Context) {
+	 var request UpdateEligibilityWindowRequest
+	 if err := c.ShouldBindJSON(&request); err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		 return
+	 }
+	 if err := h.drawService.UpdateEligibilityWindow(c.Request.Context(), request.DrawType, request.Window); err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to update eligibility window: " + err.Error()})
+		 return
+	 }
+	 c.JSON(http.StatusOK, gin.H{"message": "Eligibility window updated successfully"})
+}
+
+// GetWinFrequencyRules handles GET /draws/win-rules
+func (h *DrawHandler) GetWinFrequencyRules(c *gin.Context) {
+	 rules, err := h.drawService.GetWinFrequencyRules(c.Request.Context())
 	 if err != nil {
-		 // Return the draw object even on error, as it contains logs
-		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to execute draw: " + err.Error(), "draw_details": executedDraw})
+		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get win rules: " + err.Error()})
+		 return
+	 }
+	 c.JSON(http.StatusOK, rules)
+}
+
+// UpdateWinFrequencyRules handles PUT /draws/win-rules
+func (h *DrawHandler) UpdateWinFrequencyRules(c *gin.Context) {
+	 var rules models.WinFrequencyRules
+	 if err := c.ShouldBindJSON(&rules); err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		 return
+	 }
+	 if err := h.drawService.UpdateWinFrequencyRules(c.Request.Context(), rules); err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to update win rules: " + err.Error()})
 		 return
 	 }
-	 c.JSON(http.StatusOK, gin.H{"message": "Draw executed successfully", "draw_details": executedDraw})
+	 c.JSON(http.StatusOK, gin.H{"message": "Win rules updated successfully"})
+}
+
+// DrawActionRequest is the body of the void and re-run endpoints
+type DrawActionRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// adminSubject returns the JWT subject (admin user ID) set by JWTAuthMiddleware
+func adminSubject(c *gin.Context) string {
+	 if sub, ok := c.Get("userID"); ok {
+		 if s, ok := sub.(string); ok {
+			 return s
+		 }
+	 }
+	 return ""
+}
+
+// VoidDraw handles POST /draws/void/:id by requesting a second admin's approval
+func (h *DrawHandler) VoidDraw(c *gin.Context) {
+	 id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	 if err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		 return
+	 }
+	 var req DrawActionRequest
+	 if err := c.ShouldBindJSON(&req); err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		 return
+	 }
+	 approval, err := h.approvalService.RequestDrawVoid(c.Request.Context(), id, req.Reason, adminSubject(c))
+	 respondApprovalRequested(c, "draw void", approval, err)
+}
+
+// ReRunDraw handles POST /draws/rerun/:id
+func (h *DrawHandler) ReRunDraw(c *gin.Context) {
+	 id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	 if err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		 return
+	 }
+	 var req DrawActionRequest
+	 if err := c.ShouldBindJSON(&req); err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		 return
+	 }
+	 draw, err := h.drawService.ReRunDraw(c.Request.Context(), id, req.Reason, adminSubject(c))
+	 if err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to re-run draw: " + err.Error(), "draw_details": draw})
+		 return
+	 }
+	 c.JSON(http.StatusCreated, draw)
+}
+
+// VerifyDraw handles GET /draws/verify/:id
+func (h *DrawHandler) VerifyDraw(c *gin.Context) {
+	 id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	 if err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		 return
+	 }
+	 verification, err := h.drawService.VerifyDraw(c.Request.Context(), id)
+	 if err != nil {
+		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify draw: " + err.Error()})
+		 return
+	 }
+	 c.JSON(http.StatusOK, verification)
+}
+
+// GetDrawParticipants handles GET /draws/participants/:id
+func (h *DrawHandler) GetDrawParticipants(c *gin.Context) {
+	 id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	 if err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		 return
+	 }
+	 page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	 limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	 if page < 1 {
+		 page = 1
+	 }
+	 if limit < 1 || limit > 1000 {
+		 limit = 100
+	 }
+	 participants, total, err := h.drawService.GetDrawParticipants(c.Request.Context(), id, page, limit)
+	 if err != nil {
+		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get draw participants: " + err.Error()})
+		 return
+	 }
+	 c.JSON(http.StatusOK, gin.H{"participants": participants, "total": total, "page": page, "limit": limit})
+}
+
+// ExportDrawParticipants handles GET /draws/participants/:id/export (CSV download)
+func (h *DrawHandler) ExportDrawParticipants(c *gin.Context) {
+	 id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	 if err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		 return
+	 }
+	 c.Header("Content-Type", "text/csv")
+	 c.Header("Content-Disposition", "attachment; filename=draw_"+id.Hex()+"_participants.csv")
+	 writer := csv.NewWriter(c.Writer)
+	 _ = writer.Write([]string{"msisdn", "user_id", "points", "in_jackpot_pool", "in_consolation_pool", "opt_in_status", "opt_in_date", "exclusion_reason"})
+	 err = h.drawService.ExportDrawParticipants(c.Request.Context(), id, func(p *models.DrawParticipant) error {
+		 optInDate := ""
+		 if !p.OptInDate.IsZero() {
+			 optInDate = p.OptInDate.Format(time.RFC3339)
+		 }
+		 return writer.Write([]string{
+			 p.MSISDN,
+			 p.UserID.Hex(),
+			 strconv.Itoa(p.Points),
+			 strconv.FormatBool(p.InJackpotPool),
+			 strconv.FormatBool(p.InConsolationPool),
+			 strconv.FormatBool(p.OptInStatus),
+			 optInDate,
+			 p.ExclusionReason,
+		 })
+	 })
+	 writer.Flush()
+	 if err != nil {
+		 // Headers are already sent, so the best we can do is abort the stream
+		 _ = c.Error(err)
+		 c.Abort()
+	 }
 }
 
 // GetDrawByDate handles GET /draws/date/:date
@@ -167,9 +505,45 @@ func (h *DrawHandler) GetDrawWinners(c *gin.Context) {
 	 c.JSON(http.StatusOK, winners)
 }
 
-// GetJackpotStatus handles GET /draws/jackpot-status
+// ClaimPrize handles POST /draws/winners/claim/:id
+func (h *DrawHandler) ClaimPrize(c *gin.Context) {
+	 id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	 if err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		 return
+	 }
+	 winner, err := h.drawService.ClaimPrize(c.Request.Context(), id)
+	 if err != nil {
+		 if errors.Is(err, services.ErrClaimStatusChanged) {
+			 c.JSON(http.StatusConflict, gin.H{"error": "Failed to claim prize: " + err.Error()})
+		 } else if winner != nil {
+			 // The winner exists but is not claimable (wrong status or deadline passed)
+			 c.JSON(http.StatusConflict, gin.H{"error": "Failed to claim prize: " + err.Error(), "winner": winner})
+		 } else {
+			 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to claim prize: " + err.Error()})
+		 }
+		 return
+	 }
+	 c.JSON(http.StatusOK, winner)
+}
+
+// ProcessExpiredClaims handles POST /draws/claims/process-expired
+func (h *DrawHandler) ProcessExpiredClaims(c *gin.Context) {
+	 forfeited, err := h.drawService.ProcessExpiredJackpotClaims(c.Request.Context(), time.Now())
+	 if err != nil {
+		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process expired claims: " + err.Error()})
+		 return
+	 }
+	 c.JSON(http.StatusOK, gin.H{"message": "Expired jackpot claims processed", "forfeited": forfeited})
+}
+
+// GetJackpotStatus handles GET /draws/jackpot-status?draw_type=X (default: the headline draw type)
 func (h *DrawHandler) GetJackpotStatus(c *gin.Context) {
-	 status, err := h.drawService.GetJackpotStatus(c.Request.Context())
+	 status, err := h.drawService.GetJackpotStatus(c.Request.Context(), c.Query("draw_type"))
+	 if errors.Is(err, services.ErrUnknownDrawType) {
+		 c.JSON(http.StatusNotFound, gin.H{"error": "Failed to get jackpot status: " + err.Error()})
+		 return
+	 }
 	 if err != nil {
 		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get jackpot status: " + err.Error()})
 		 return
@@ -177,14 +551,28 @@ func (h *DrawHandler) GetJackpotStatus(c *gin.Context) {
 	 c.JSON(http.StatusOK, status)
 }
 
-// GetPrizeStructure handles GET /draws/prize-structure
+// GetPrizeStructure handles GET /draws/prize-structure?draw_type=X&date=YYYY-MM-DD
+// (default date: today). It returns the prize structure version in force on the date.
 func (h *DrawHandler) GetPrizeStructure(c *gin.Context) {
 	 drawType := c.Query("draw_type")
 	 if drawType == "" {
 		 c.JSON(http.StatusBadRequest, gin.H{"error": "Missing draw_type query parameter"})
 		 return
 	 }
-	 structure, err := h.drawService.GetPrizeStructure(c.Request.Context(), drawType)
+	 date := time.Now().UTC()
+	 if dateStr := c.Query("date"); dateStr != "" {
+		 parsed, err := time.Parse("2006-01-02", dateStr)
+		 if err != nil {
+			 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format (YYYY-MM-DD)"})
+			 return
+		 }
+		 date = parsed
+	 }
+	 structure, err := h.drawService.GetEffectivePrizeStructure(c.Request.Context(), drawType, date)
+	 if errors.Is(err, services.ErrUnknownDrawType) {
+		 c.JSON(http.StatusNotFound, gin.H{"error": "Failed to get prize structure: " + err.Error()})
+		 return
+	 }
 	 if err != nil {
 		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get prize structure: " + err.Error()})
 		 return
@@ -192,7 +580,119 @@ func (h *DrawHandler) GetPrizeStructure(c *gin.Context) {
 	 c.JSON(http.StatusOK, structure)
 }
 
-// UpdatePrizeStructure handles PUT /draws/prize-structure
+// prizeStructureErrorStatus maps prize structure version errors to HTTP status codes
+func prizeStructureErrorStatus(err error) int {
+	 if errors.Is(err, services.ErrUnknownDrawType) || errors.Is(err, services.ErrPrizeStructureVersionNotFound) {
+		 return http.StatusNotFound
+	 }
+	 if errors.Is(err, services.ErrPrizeStructureVersionNotDraft) {
+		 return http.StatusConflict
+	 }
+	 return http.StatusBadRequest
+}
+
+// ListPrizeStructureVersions handles GET /draws/prize-structures/:type/versions
+func (h *DrawHandler) ListPrizeStructureVersions(c *gin.Context) {
+	 versions, err := h.drawService.ListPrizeStructureVersions(c.Request.Context(), c.Param("type"))
+	 if errors.Is(err, services.ErrUnknownDrawType) {
+		 c.JSON(http.StatusNotFound, gin.H{"error": "Failed to list prize structure versions: " + err.Error()})
+		 return
+	 }
+	 if err != nil {
+		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list prize structure versions: " + err.Error()})
+		 return
+	 }
+	 c.JSON(http.StatusOK, versions)
+}
+
+// CreatePrizeStructureVersionRequest is the body of POST /draws/prize-structures/:type/versions
+type CreatePrizeStructureVersionRequest struct {
+	Prizes        []models.Prize `json:"prizes" binding:"required"`
+	EffectiveFrom string         `json:"effectiveFrom" binding:"required"` // YYYY-MM-DD, first draw date the version applies to
+	Notes         string         `json:"notes"`
+}
+
+// CreatePrizeStructureVersion handles POST /draws/prize-structures/:type/versions
+func (h *DrawHandler) CreatePrizeStructureVersion(c *gin.Context) {
+	 var request CreatePrizeStructureVersionRequest
+	 if err := c.ShouldBindJSON(&request); err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		 return
+	 }
+	 effectiveFrom, err := time.Parse("2006-01-02", request.EffectiveFrom)
+	 if err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid effectiveFrom format (YYYY-MM-DD)"})
+		 return
+	 }
+	 version, err := h.drawService.CreatePrizeStructureVersion(c.Request.Context(), c.Param("type"), request.Prizes, effectiveFrom, request.Notes, adminSubject(c))
+	 if err != nil {
+		 c.JSON(prizeStructureErrorStatus(err), gin.H{"error": "Failed to create prize structure version: " + err.Error()})
+		 return
+	 }
+	 c.JSON(http.StatusCreated, version)
+}
+
+// parseVersionParam reads the :version path parameter
+func parseVersionParam(c *gin.Context) (int, bool) {
+	 version, err := strconv.Atoi(c.Param("version"))
+	 if err != nil || version < 1 {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version"})
+		 return 0, false
+	 }
+	 return version, true
+}
+
+// SubmitPrizeStructureVersion handles POST /draws/prize-structures/:type/versions/:version/submit.
+// It asks a second admin to approve the DRAFT version.
+func (h *DrawHandler) SubmitPrizeStructureVersion(c *gin.Context) {
+	 versionNumber, ok := parseVersionParam(c)
+	 if !ok {
+		 return
+	 }
+	 approval, err := h.approvalService.RequestPrizeStructureApproval(c.Request.Context(), c.Param("type"), versionNumber, "", adminSubject(c))
+	 respondApprovalRequested(c, "prize structure approval", approval, err)
+}
+
+// RejectPrizeStructureVersion handles POST /draws/prize-structures/:type/versions/:version/reject
+func (h *DrawHandler) RejectPrizeStructureVersion(c *gin.Context) {
+	 versionNumber, ok := parseVersionParam(c)
+	 if !ok {
+		 return
+	 }
+	 var req struct {
+		 Reason string `json:"reason" binding:"required"`
+	 }
+	 if err := c.ShouldBindJSON(&req); err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "A rejection reason is required"})
+		 return
+	 }
+	 version, err := h.drawService.RejectPrizeStructureVersion(c.Request.Context(), c.Param("type"), versionNumber, adminSubject(c), req.Reason)
+	 if err != nil {
+		 c.JSON(prizeStructureErrorStatus(err), gin.H{"error": "Failed to reject prize structure version: " + err.Error(), "version": version})
+		 return
+	 }
+	 c.JSON(http.StatusOK, version)
+}
+
+// DiffPrizeStructureVersions handles GET /draws/prize-structures/:type/diff?from=N&to=M
+// (version 0 is the legacy config structure)
+func (h *DrawHandler) DiffPrizeStructureVersions(c *gin.Context) {
+	 from, errFrom := strconv.Atoi(c.Query("from"))
+	 to, errTo := strconv.Atoi(c.Query("to"))
+	 if errFrom != nil || errTo != nil || from < 0 || to < 0 {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be version numbers"})
+		 return
+	 }
+	 diff, err := h.drawService.DiffPrizeStructureVersions(c.Request.Context(), c.Param("type"), from, to)
+	 if err != nil {
+		 c.JSON(prizeStructureErrorStatus(err), gin.H{"error": "Failed to diff prize structure versions: " + err.Error()})
+		 return
+	 }
+	 c.JSON(http.StatusOK, diff)
+}
+
+// UpdatePrizeStructure handles PUT /draws/prize-structure. It drafts a version effective
+// today and asks a second admin to approve it.
 type UpdatePrizeStructureRequest struct {
 	DrawType  string          `json:"draw_type" binding:"required"`
 	Structure []models.Prize `json:"structure" binding:"required"` // Expecting []models.Prize
@@ -204,12 +704,31 @@ func (h *DrawHandler) UpdatePrizeStructure(c *gin.Context) {
 		 c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		 return
 	 }
-	 err := h.drawService.UpdatePrizeStructure(c.Request.Context(), request.DrawType, request.Structure)
+	 version, err := h.drawService.CreatePrizeStructureVersion(c.Request.Context(), request.DrawType, request.Structure, time.Now().UTC(), "", adminSubject(c))
 	 if err != nil {
-		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update prize structure: " + err.Error()})
+		 c.JSON(prizeStructureErrorStatus(err), gin.H{"error": "Failed to update prize structure: " + err.Error()})
+		 return
+	 }
+	 approval, err := h.approvalService.RequestPrizeStructureApproval(c.Request.Context(), version.DrawType, version.Version, "", adminSubject(c))
+	 respondApprovalRequested(c, "prize structure approval", approval, err)
+}
+
+// UpdateBaseJackpotRequest is the body of PUT /draws/base-jackpot
+type UpdateBaseJackpotRequest struct {
+	DrawType string  `json:"draw_type" binding:"required"`
+	Amount   float64 `json:"amount" binding:"required"`
+	Reason   string  `json:"reason"`
+}
+
+// UpdateBaseJackpot handles PUT /draws/base-jackpot. The change runs once a second admin approves it.
+func (h *DrawHandler) UpdateBaseJackpot(c *gin.Context) {
+	 var request UpdateBaseJackpotRequest
+	 if err := c.ShouldBindJSON(&request); err != nil {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		 return
 	 }
-	 c.JSON(http.StatusOK, gin.H{"message": "Prize structure updated successfully"})
+	 approval, err := h.approvalService.RequestBaseJackpotChange(c.Request.Context(), request.DrawType, request.Amount, request.Reason, adminSubject(c))
+	 respondApprovalRequested(c, "base jackpot change", approval, err)
 }
 
 // --- Placeholder/Unused Handlers (Review if needed) ---
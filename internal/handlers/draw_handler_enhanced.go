@@ -5,7 +5,6 @@ import (
 	// "strconv" // Removed unused import
 	"time"
 
-	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
 	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/services"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -41,8 +40,8 @@ func (h *DrawHandlerEnhanced) GetDrawConfig(c *gin.Context) {
 func (h *DrawHandlerEnhanced) GetPrizeStructure(c *gin.Context) {
 	// Get draw type from query parameter
 	 drawType := c.Query("draw_type")
-	 if drawType != "DAILY" && drawType != "WEEKLY" {
-		 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid draw type (DAILY or WEEKLY)"})
+	 if drawType == "" {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": "draw_type is required"})
 		 return
 	 }
 
@@ -56,34 +55,6 @@ func (h *DrawHandlerEnhanced) GetPrizeStructure(c *gin.Context) {
 	 c.JSON(http.StatusOK, structure)
 }
 
-// UpdatePrizeStructure handles PUT /draws/prize-structure
-func (h *DrawHandlerEnhanced) UpdatePrizeStructure(c *gin.Context) {
-	// Parse request body
-	 var request struct {
-		 DrawType string `json:"draw_type" binding:"required"`
-		 Prizes []models.Prize `json:"prizes" binding:"required"` // Changed to models.Prize
-	 }
-	 if err := c.ShouldBindJSON(&request); err != nil {
-		 c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		 return
-	 }
-
-	// Validate draw type
-	 if request.DrawType != "DAILY" && request.DrawType != "WEEKLY" {
-		 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid draw type (DAILY or WEEKLY)"})
-		 return
-	 }
-
-	// Update prize structure
-	 err := h.drawService.UpdatePrizeStructure(c.Request.Context(), request.DrawType, request.Prizes) // Use c.Request.Context()
-	 if err != nil {
-		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update prize structure: " + err.Error()})
-		 return
-	 }
-
-	 c.JSON(http.StatusOK, gin.H{"message": "Prize structure updated successfully"})
-}
-
 // ScheduleDraw handles POST /draws
 func (h *DrawHandlerEnhanced) ScheduleDraw(c *gin.Context) {
 	// Parse request body
@@ -105,12 +76,6 @@ func (h *DrawHandlerEnhanced) ScheduleDraw(c *gin.Context) {
 		 return
 	 }
 
-	// Validate draw type
-	 if request.DrawType != "DAILY" && request.DrawType != "WEEKLY" {
-		 c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid draw type (DAILY or WEEKLY)"})
-		 return
-	 }
-
 	// Validate eligible digits if not using default
 	 if !request.UseDefault && len(request.EligibleDigits) == 0 {
 	 	 c.JSON(http.StatusBadRequest, gin.H{"error": "eligible_digits cannot be empty if use_default is false"})
@@ -138,7 +103,7 @@ func (h *DrawHandlerEnhanced) ExecuteDraw(c *gin.Context) {
 	 }
 
 	// Execute draw - Capture both return values
-	 _, err = h.drawService.ExecuteDraw(c.Request.Context(), drawID) // Use c.Request.Context(), Capture draw object if needed later, otherwise use _
+	 _, err = h.drawService.ExecuteDraw(c.Request.Context(), drawID, c.GetHeader("Idempotency-Key")) // Use c.Request.Context(), Capture draw object if needed later, otherwise use _
 	 if err != nil {
 		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to execute draw: " + err.Error()})
 		 return
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -109,8 +110,16 @@ func (h *TopupHandler) CreateTopup(c *gin.Context) {
 		 return
 	 }
 
-	// Create topup
+	// Create topup; a repeated transaction reference returns the topup already recorded
 	 err := h.topupService.CreateTopup(c, &topup)
+	 if errors.Is(err, services.ErrDuplicateTopup) {
+		 c.JSON(http.StatusOK, topup)
+		 return
+	 }
+	 if errors.Is(err, services.ErrInvalidTopup) {
+		 c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		 return
+	 }
 	 if err != nil {
 		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create topup: " + err.Error() })
 		 return
@@ -149,8 +158,13 @@ func (h *TopupHandler) ProcessTopups(c *gin.Context) {
 
 	// Process topups
 	 processed, err := h.topupService.ProcessTopups(c, startDate, endDate)
+	 if errors.Is(err, services.ErrTopupIngestionRunning) {
+		 c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "processed": processed})
+		 return
+	 }
 	 if err != nil {
-		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process topups: " + err.Error() })
+		 // Topups processed before the failure stay processed; rerunning resumes from the checkpoint
+		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process topups: " + err.Error(), "processed": processed})
 		 return
 	 }
 
@@ -170,22 +184,7 @@ func (h *TopupHandler) GetTopupCount(c *gin.Context) {
 }
 
 
-// GetTopups handles GET /topups
-// Placeholder implementation - Assumes pagination and potentially filtering
+// GetTopups handles GET /topups?start_date=&end_date=&page=&limit=
 func (h *TopupHandler) GetTopups(c *gin.Context) {
-	// TODO: Implement logic to parse query params (pagination, filters), call topupService.GetTopups, and handle response/errors
-	 page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	 limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	 // Add other filters as needed (e.g., msisdn, date range)
-
-	 // Assuming topupService.GetTopups exists and takes context, page, limit, and potentially filters
-	 // topups, err := h.topupService.GetTopups(c.Request.Context(), page, limit /*, filters... */)
-	 // if err != nil {
-	 // 	 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get topups: " + err.Error()})
-	 // 	 return
-	 // }
-	 // c.JSON(http.StatusOK, topups)
-	 c.JSON(http.StatusNotImplemented, gin.H{"message": "GetTopups not implemented", "page": page, "limit": limit})
+	 h.GetTopupsByDateRange(c)
 }
-
-
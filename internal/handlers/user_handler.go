@@ -182,7 +182,7 @@ func (h *UserHandler) OptOut(c *gin.Context) {
 	}
 
 	// Opt out user
-	 err := h.userService.OptOut(c, request.MSISDN)
+	 err := h.userService.OptOut(c.Request.Context(), request.MSISDN)
 	 if err != nil {
 		 c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to opt out user: " + err.Error() })
 		 return
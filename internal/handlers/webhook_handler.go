@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/services"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/pkg/mtnapi"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+// maxWebhookBodyBytes caps the size of a pushed event
+const maxWebhookBodyBytes = 64 << 10
+
+// WebhookHandler handles events MTN pushes to the API
+type WebhookHandler struct {
+	topupService services.TopupService
+	secret       string
+	maxSkew      time.Duration
+}
+
+// NewWebhookHandler creates a new WebhookHandler. Events are refused while secret is empty.
+func NewWebhookHandler(topupService services.TopupService, secret string, maxSkew time.Duration) *WebhookHandler {
+	return &WebhookHandler{
+		topupService: topupService,
+		secret:       secret,
+		maxSkew:      maxSkew,
+	}
+}
+
+// ReceiveTopup handles POST /webhooks/topups. The body is a recharge record signed with
+// X-Timestamp and X-Signature (see mtnapi.SignWebhook). Verified recharges are queued and
+// answered 202; a redelivered TransactionRef is answered 200 without being queued again.
+func (h *WebhookHandler) ReceiveTopup(c *gin.Context) {
+	if h.secret == "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Topup webhook is not configured"})
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBodyBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	if len(body) > maxWebhookBodyBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+		return
+	}
+
+	// The signature covers the timestamp, so an old event cannot be replayed with a fresh one
+	timestamp := c.GetHeader(mtnapi.HeaderTimestamp)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(seconds, 0)).Abs() > h.maxSkew {
+		slog.Warn("Topup webhook refused: missing or stale timestamp", "timestamp", timestamp, "ip", c.ClientIP())
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing or stale timestamp"})
+		return
+	}
+	if !mtnapi.ValidWebhookSignature(h.secret, timestamp, body, c.GetHeader(mtnapi.HeaderSignature)) {
+		slog.Warn("Topup webhook refused: invalid signature", "ip", c.ClientIP())
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+		return
+	}
+
+	var recharge mtnapi.TopupResponse
+	if err := json.Unmarshal(body, &recharge); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event JSON: " + err.Error()})
+		return
+	}
+	if !recharge.Successful() {
+		c.JSON(http.StatusOK, gin.H{"status": "ignored", "transactionRef": recharge.TransactionRef})
+		return
+	}
+	queued, err := h.topupService.EnqueueWebhookTopup(c.Request.Context(), recharge)
+	if errors.Is(err, services.ErrInvalidTopup) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		// MTN redelivers events that were not acknowledged
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue topup: " + err.Error()})
+		return
+	}
+	if !queued {
+		c.JSON(http.StatusOK, gin.H{"status": "duplicate", "transactionRef": recharge.TransactionRef})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"status": "queued", "transactionRef": recharge.TransactionRef})
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"fmt" // Added fmt import
 	"log" // Added log import
@@ -13,12 +14,22 @@ import (
 	"github.com/golang-jwt/jwt/v5" // Use the newer library
 )
 
-// JWTAuthMiddleware is a middleware for JWT authentication
-func JWTAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+// TokenDenylist reports whether an access token has been revoked (logout, a revoked session or
+// a disabled admin) before its expiry
+type TokenDenylist interface {
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// JWTAuthMiddleware is a middleware for JWT authentication. Tokens without a jti, and tokens
+// on the denylist, are refused.
+func JWTAuthMiddleware(cfg *config.Config, denylist TokenDenylist) gin.HandlerFunc {
 	// Check if JWT secret is configured. If not, log a fatal error as middleware cannot function.
 	 if cfg.JWT.Secret == "" {
 	 	log.Fatal("[FATAL] JWTAuthMiddleware: JWT_SECRET is not configured in the environment variables!")
 	 }
+	 if denylist == nil {
+	 	log.Fatal("[FATAL] JWTAuthMiddleware: no token denylist configured")
+	 }
 	 jwtSecret := []byte(cfg.JWT.Secret)
 
 	 return func(c *gin.Context) {
@@ -58,7 +69,31 @@ func JWTAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 	 	 }
 
 	 	 if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+	 	 	// Tokens issued before revocation existed carry no jti and cannot be revoked; login
+	 	 	// challenge tokens (typ "mfa") are not access tokens
+	 	 	 jti, _ := claims["jti"].(string)
+	 	 	 if jti == "" {
+	 	 	 	 c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token cannot be revoked; please log in again"})
+	 	 	 	return
+	 	 	 }
+	 	 	 if claims["typ"] != "access" {
+	 	 	 	 c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Not an access token"})
+	 	 	 	return
+	 	 	 }
+	 	 	 revoked, err := denylist.IsTokenRevoked(c.Request.Context(), jti)
+	 	 	 if err != nil {
+	 	 	 	log.Printf("[ERROR] JWTAuthMiddleware: Failed to check token denylist: %v", err)
+	 	 	 	 c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Could not verify token"})
+	 	 	 	return
+	 	 	 }
+	 	 	 if revoked {
+	 	 	 	 c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+	 	 	 	return
+	 	 	 }
+
 	 	 	// Token is valid. Optionally set claims in context for downstream handlers.
+	 	 	 c.Set("tokenID", jti)
+	 	 	 c.Set("sessionID", claims["sid"])
 	 	 	 c.Set("userID", claims["sub"])
 	 	 	 c.Set("userEmail", claims["email"])
 	 	 	 c.Set("userRole", claims["role"])
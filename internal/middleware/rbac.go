@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// RoleFromContext returns the role JWTAuthMiddleware read from the token, or "" when there is none
+func RoleFromContext(c *gin.Context) string {
+	if role, ok := c.Get("userRole"); ok {
+		if s, ok := role.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// RouteKey identifies a route in a permission table: the method and the registered path,
+// e.g. "GET /api/v1/draws/:id"
+func RouteKey(method, fullPath string) string {
+	return method + " " + fullPath
+}
+
+// RBACMiddleware enforces role-based access control. permissions maps each route (see
+// RouteKey) to the permission it requires; routes without an entry are refused, so a new
+// route stays closed until it is added to the table. Must run after JWTAuthMiddleware.
+func RBACMiddleware(permissions map[string]models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := RouteKey(c.Request.Method, c.FullPath())
+		permission, ok := permissions[route]
+		if !ok {
+			log.Printf("[WARN] RBACMiddleware: No permission is defined for route %s", route)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Access to this route is not configured"})
+			return
+		}
+		role := RoleFromContext(c)
+		if !models.HasPermission(role, permission) {
+			log.Printf("[WARN] RBACMiddleware: Role %q lacks permission %s for route %s", role, permission, route)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Your role does not allow this action", "required_permission": permission})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AdminInvite lets a super admin invite a new admin with a role. Only the SHA-256 hash of
+// the single-use token is stored; the token itself is returned once, when the invite is
+// created.
+type AdminInvite struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	Email       string              `bson:"email" json:"email"`
+	Role        string              `bson:"role" json:"role"`
+	TokenHash   string              `bson:"tokenHash" json:"-"`
+	ExpiresAt   time.Time           `bson:"expiresAt" json:"expiresAt"`
+	CreatedBy   string              `bson:"createdBy" json:"createdBy"` // Admin user ID of the inviter
+	AcceptedAt  *time.Time          `bson:"acceptedAt,omitempty" json:"acceptedAt,omitempty"`
+	AdminUserID *primitive.ObjectID `bson:"adminUserId,omitempty" json:"adminUserId,omitempty"` // Account created on acceptance
+	RevokedAt   *time.Time          `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+	RevokedBy   string              `bson:"revokedBy,omitempty" json:"revokedBy,omitempty"`
+	CreatedAt   time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt   time.Time           `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Open reports whether the invite can still be accepted
+func (i *AdminInvite) Open(now time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
+
+// CreateInviteRequest is the body of POST /admins/invites
+type CreateInviteRequest struct {
+	Email          string `json:"email" binding:"required,email"`
+	Role           string `json:"role" binding:"required"`
+	ExpiresInHours int    `json:"expiresInHours"` // Defaults to 72
+}
+
+// AcceptInviteRequest is the body of POST /auth/invites/accept
+type AcceptInviteRequest struct {
+	Token     string `json:"token" binding:"required"`
+	FirstName string `json:"firstName" binding:"required"`
+	LastName  string `json:"lastName" binding:"required"`
+	Password  string `json:"password" binding:"required,min=8"`
+}
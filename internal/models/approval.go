@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ApprovalAction is a sensitive operation that needs a second admin's approval
+type ApprovalAction string
+
+const (
+	ApprovalActionExecuteDraw    ApprovalAction = "EXECUTE_DRAW"
+	ApprovalActionVoidDraw       ApprovalAction = "VOID_DRAW"
+	ApprovalActionPrizeStructure ApprovalAction = "PRIZE_STRUCTURE" // Approval of a DRAFT prize structure version
+	ApprovalActionBaseJackpot    ApprovalAction = "BASE_JACKPOT"
+	ApprovalActionDrawTypeKeys   ApprovalAction = "DRAW_TYPE_KEYS" // Repointing a draw type's prize structure or base jackpot SystemConfig key
+)
+
+// ApprovalStatus is the state of an approval request
+type ApprovalStatus string
+
+const (
+	ApprovalStatusPending  ApprovalStatus = "PENDING"
+	ApprovalStatusApproved ApprovalStatus = "APPROVED" // Approved and the action succeeded
+	ApprovalStatusFailed   ApprovalStatus = "FAILED"   // Approved but the action returned an error
+	ApprovalStatusRejected ApprovalStatus = "REJECTED"
+	ApprovalStatusExpired  ApprovalStatus = "EXPIRED" // Not reviewed before ExpiresAt
+)
+
+// ApprovalRequest is a maker-checker request (approvals collection): one admin requests an
+// action and a second admin, with a different JWT subject, approves it before it runs
+type ApprovalRequest struct {
+	ID                    primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	Action                ApprovalAction      `bson:"action" json:"action"`
+	Status                ApprovalStatus      `bson:"status" json:"status"`
+	Target                string              `bson:"target" json:"target"` // What the action applies to, e.g. a draw ID or "DAILY v3"; one pending request per action and target
+	DrawID                *primitive.ObjectID `bson:"drawId,omitempty" json:"drawId,omitempty"`
+	DrawType              string              `bson:"drawType,omitempty" json:"drawType,omitempty"`
+	PrizeStructureVersion int                 `bson:"prizeStructureVersion,omitempty" json:"prizeStructureVersion,omitempty"`
+	Amount                float64             `bson:"amount,omitempty" json:"amount,omitempty"`                       // New base jackpot
+	PrizeStructureKey     string              `bson:"prizeStructureKey,omitempty" json:"prizeStructureKey,omitempty"` // New SystemConfig key of DRAW_TYPE_KEYS
+	BaseJackpotKey        string              `bson:"baseJackpotKey,omitempty" json:"baseJackpotKey,omitempty"`       // New SystemConfig key of DRAW_TYPE_KEYS
+	Reason                string              `bson:"reason,omitempty" json:"reason,omitempty"`                       // Requester's justification; the void reason of VOID_DRAW
+	RequestedBy           string              `bson:"requestedBy" json:"requestedBy"`
+	RequestedAt           time.Time           `bson:"requestedAt" json:"requestedAt"`
+	ExpiresAt             time.Time           `bson:"expiresAt" json:"expiresAt"`
+	EndorsedBy            string              `bson:"endorsedBy,omitempty" json:"endorsedBy,omitempty"` // First of the two admins approving a request filed by the scheduler
+	EndorsedAt            *time.Time          `bson:"endorsedAt,omitempty" json:"endorsedAt,omitempty"`
+	ReviewedBy            string              `bson:"reviewedBy,omitempty" json:"reviewedBy,omitempty"`
+	ReviewedAt            *time.Time          `bson:"reviewedAt,omitempty" json:"reviewedAt,omitempty"`
+	ReviewNote            string              `bson:"reviewNote,omitempty" json:"reviewNote,omitempty"`
+	Error                 string              `bson:"error,omitempty" json:"error,omitempty"` // Why the approved action failed
+	CreatedAt             time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt             time.Time           `bson:"updatedAt" json:"updatedAt"`
+}
@@ -0,0 +1,134 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Audit actions
+const (
+	AuditActionRequest                = "API_REQUEST"   // A mutating request no service recorded a more specific event for
+	AuditActionLoginLockout           = "LOGIN_LOCKOUT" // Failed logins locked an email or IP address
+	AuditActionLoginUnlock            = "LOGIN_UNLOCK"  // An admin lifted a login lockout
+	AuditActionDrawScheduled          = "DRAW_SCHEDULED"
+	AuditActionDrawExecuted           = "DRAW_EXECUTED"
+	AuditActionDrawVoided             = "DRAW_VOIDED"
+	AuditActionDrawReRun              = "DRAW_RERUN_SCHEDULED"
+	AuditActionDrawTypeCreated        = "DRAW_TYPE_CREATED"
+	AuditActionDrawTypeUpdated        = "DRAW_TYPE_UPDATED"
+	AuditActionDrawTypeDeleted        = "DRAW_TYPE_DELETED"
+	AuditActionEligibilityWindow      = "ELIGIBILITY_WINDOW_UPDATED"
+	AuditActionWinRules               = "WIN_RULES_UPDATED"
+	AuditActionPrizeStructureCreated  = "PRIZE_STRUCTURE_CREATED"
+	AuditActionPrizeStructureApproved = "PRIZE_STRUCTURE_APPROVED"
+	AuditActionPrizeStructureRejected = "PRIZE_STRUCTURE_REJECTED"
+	AuditActionBaseJackpotUpdated     = "BASE_JACKPOT_UPDATED"
+	AuditActionUserOptedOut           = "USER_OPTED_OUT"
+)
+
+// Audit target types
+const (
+	AuditTargetDraw           = "draw"
+	AuditTargetDrawType       = "draw_type"
+	AuditTargetPrizeStructure = "prize_structure"
+	AuditTargetUser           = "user"
+	AuditTargetRoute          = "route" // Generic request events name the route; TargetID holds its path parameters
+)
+
+// AuditChange is one top-level field that an action changed. Values are JSON-encoded and
+// empty when the field was absent, e.g. Before on a created entity.
+type AuditChange struct {
+	Field  string `bson:"field" json:"field"`
+	Before string `bson:"before,omitempty" json:"before,omitempty"`
+	After  string `bson:"after,omitempty" json:"after,omitempty"`
+}
+
+// AuditEvent records who did what to which entity. Events are only ever appended: each one
+// carries the hash of the previous event, so editing or deleting one breaks the chain.
+type AuditEvent struct {
+	Sequence   int64             `bson:"_id" json:"sequence"` // 1 for the first event, then consecutive
+	Action     string            `bson:"action" json:"action"`
+	ActorID    string            `bson:"actorId,omitempty" json:"actorId,omitempty"` // Admin user ID (JWT subject); empty for the system
+	ActorEmail string            `bson:"actorEmail,omitempty" json:"actorEmail,omitempty"`
+	TargetType string            `bson:"targetType" json:"targetType"`
+	TargetID   string            `bson:"targetId" json:"targetId"`
+	Changes    []AuditChange     `bson:"changes,omitempty" json:"changes,omitempty"`
+	Details    map[string]string `bson:"details,omitempty" json:"details,omitempty"`
+	RequestID  string            `bson:"requestId,omitempty" json:"requestId,omitempty"` // X-Request-ID of the API call
+	IPAddress  string            `bson:"ipAddress,omitempty" json:"ipAddress,omitempty"`
+	Method     string            `bson:"method,omitempty" json:"method,omitempty"`
+	Route      string            `bson:"route,omitempty" json:"route,omitempty"`   // Registered path, e.g. /api/v1/draws/:id
+	Status     int               `bson:"status,omitempty" json:"status,omitempty"` // HTTP status of generic request events
+	CreatedAt  time.Time         `bson:"createdAt" json:"createdAt"`
+	PrevHash   string            `bson:"prevHash" json:"prevHash"` // Hash of the previous event; empty for the first
+	Hash       string            `bson:"hash" json:"hash"`
+}
+
+// ComputeHash returns the SHA-256 over the event's content and PrevHash. CreatedAt counts to
+// the millisecond, the precision Mongo stores.
+func (e *AuditEvent) ComputeHash() string {
+	changes := e.Changes
+	if len(changes) == 0 {
+		changes = nil
+	}
+	details := e.Details
+	if len(details) == 0 {
+		details = nil
+	}
+	content, _ := json.Marshal(struct {
+		Sequence   int64
+		Action     string
+		ActorID    string
+		ActorEmail string
+		TargetType string
+		TargetID   string
+		Changes    []AuditChange
+		Details    map[string]string // Marshalled with sorted keys
+		RequestID  string
+		IPAddress  string
+		Method     string
+		Route      string
+		Status     int
+		CreatedAt  int64
+		PrevHash   string
+	}{
+		e.Sequence, e.Action, e.ActorID, e.ActorEmail, e.TargetType, e.TargetID, changes, details,
+		e.RequestID, e.IPAddress, e.Method, e.Route, e.Status, e.CreatedAt.UnixMilli(), e.PrevHash,
+	})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditFilter selects audit events for GET /audit; empty fields match everything
+type AuditFilter struct {
+	Action     string
+	ActorID    string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	From       *time.Time
+	To         *time.Time
+}
+
+// AuditRequest describes the API call an audit event is recorded in
+type AuditRequest struct {
+	ActorID    string
+	ActorEmail string
+	RequestID  string
+	IPAddress  string
+	Method     string
+	Route      string
+	TargetID   string // The :id path parameter, or else every path parameter
+}
+
+// AuditChainVerification is the result of re-computing the audit log's hash chain
+type AuditChainVerification struct {
+	Valid          bool   `json:"valid"`
+	EventsChecked  int64  `json:"eventsChecked"`
+	LastSequence   int64  `json:"lastSequence"`
+	LastHash       string `json:"lastHash,omitempty"`       // Keep a copy elsewhere to detect removal of the latest events
+	BrokenSequence int64  `json:"brokenSequence,omitempty"` // First event that does not fit the chain
+	Problem        string `json:"problem,omitempty"`
+}
@@ -12,25 +12,60 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// RegisterRequest defines the structure for registration requests
-type RegisterRequest struct {
-	FirstName string `json:"firstName" binding:"required"`
-	LastName  string `json:"lastName" binding:"required"`
-	Email     string `json:"email" binding:"required,email"`
-	Password  string `json:"password" binding:"required,min=6"` // Example validation
+// LoginResult is the outcome of the first login step. Tokens are set when no second factor
+// is needed; otherwise MFAToken must be sent to POST /auth/login/verify with a TOTP code.
+type LoginResult struct {
+	Tokens             *AuthTokens
+	User               *AdminUser
+	MFARequired        bool
+	EnrollmentRequired bool   // The admin's role requires TOTP and they have not enrolled yet
+	MFAToken           string // Short-lived token identifying the half-finished login
+	RecoveryCodes      []string
+}
+
+// VerifyLoginRequest is the body of POST /auth/login/verify. Code is a TOTP code or a
+// recovery code.
+type VerifyLoginRequest struct {
+	MFAToken string `json:"mfaToken" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// MFATokenRequest is the body of POST /auth/login/enroll
+type MFATokenRequest struct {
+	MFAToken string `json:"mfaToken" binding:"required"`
+}
+
+// TOTPCodeRequest is the body of the two-factor endpoints that need a current code
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TOTPEnrollment is returned when enrolment starts. ProvisioningURI is usually shown as a QR
+// code; Secret is for entering by hand.
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningUri"`
 }
 
 // AdminUser represents a user account for the admin backend (separate from promotion users)
 // Note: This is an assumed structure. Adjust fields and validation as needed.
 // Consider storing this in a separate MongoDB collection (e.g., "admin_users").
 type AdminUser struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	FirstName string             `bson:"firstName" json:"firstName"`
-	LastName  string             `bson:"lastName" json:"lastName"`
-	Email     string             `bson:"email" json:"email"`
-	Password  string             `bson:"password" json:"-"` // Store hashed password, omit from JSON responses
-	Role      string             `bson:"role" json:"role"`       // e.g., "admin", "editor"
-	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
-	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	FirstName  string             `bson:"firstName" json:"firstName"`
+	LastName   string             `bson:"lastName" json:"lastName"`
+	Email      string             `bson:"email" json:"email"`
+	Password   string             `bson:"password" json:"-"`        // Store hashed password, omit from JSON responses
+	Role       string             `bson:"role" json:"role"`         // One of models.Roles; see role.go for the permission matrix
+	Disabled   bool               `bson:"disabled" json:"disabled"` // Disabled admins cannot log in and their sessions are revoked
+	DisabledAt *time.Time         `bson:"disabledAt" json:"disabledAt,omitempty"`
+	// Two-factor authentication (TOTP). The secret is kept in TOTPPendingSecret until the
+	// admin confirms it with a code; recovery codes are stored as SHA-256 hashes.
+	TOTPEnabled       bool      `bson:"totpEnabled" json:"totpEnabled"`
+	TOTPSecret        string    `bson:"totpSecret" json:"-"`
+	TOTPPendingSecret string    `bson:"totpPendingSecret" json:"-"`
+	TOTPLastCounter   int64     `bson:"totpLastCounter" json:"-"` // Time step of the last accepted code, so a code works once
+	RecoveryCodes     []string  `bson:"recoveryCodes" json:"-"`
+	CreatedAt         time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt         time.Time `bson:"updatedAt" json:"updatedAt"`
 }
-
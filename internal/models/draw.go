@@ -14,6 +14,7 @@ const (
 	DrawStatusExecuting DrawStatus = "EXECUTING"
 	DrawStatusCompleted DrawStatus = "COMPLETED"
 	DrawStatusFailed    DrawStatus = "FAILED"
+	DrawStatusVoided    DrawStatus = "VOIDED" // Results annulled by an admin; winners are ineligible
 )
 
 // Prize defines the structure for a prize category within a draw
@@ -23,6 +24,17 @@ type Prize struct {
 	NumWinners int     `bson:"numWinners" json:"numWinners"` // Number of winners for this category
 }
 
+// EligibilityWindowRule defines the recharge window of a draw type. The window opens at
+// StartTime, StartDaysBefore days before the draw date, and closes at CutoffTime on the draw
+// date, both in Timezone. Rules live on the draw type registry and are copied onto each draw when
+// it is scheduled, so later changes never affect draws already scheduled.
+type EligibilityWindowRule struct {
+	Timezone        string `bson:"timezone" json:"timezone"`               // IANA zone, e.g. "Africa/Lagos" (WAT)
+	StartDaysBefore int    `bson:"startDaysBefore" json:"startDaysBefore"` // 0 opens the window on the draw date itself
+	StartTime       string `bson:"startTime" json:"startTime"`             // "HH:MM:SS"
+	CutoffTime      string `bson:"cutoffTime" json:"cutoffTime"`           // "HH:MM:SS"
+}
+
 // JackpotValidationStatus represents the validation status of a potential jackpot winner
 type JackpotValidationStatus string
 
@@ -31,9 +43,33 @@ const (
 	JackpotValidationValid          JackpotValidationStatus = "VALID"
 	JackpotValidationInvalidNotOptIn JackpotValidationStatus = "INVALID_NOT_OPT_IN"
 	JackpotValidationNoParticipantsStatus JackpotValidationStatus = "NO_PARTICIPANTS" // Renamed constant
+	JackpotValidationNoValidCandidate JackpotValidationStatus = "NO_VALID_CANDIDATE" // Every pick in the chain was invalid or forfeited
 	// Add other invalid reasons as needed
 )
 
+// JackpotCandidateStatus tracks a pick in the ordered jackpot winner chain
+type JackpotCandidateStatus string
+
+const (
+	JackpotCandidateValid           JackpotCandidateStatus = "VALID"              // Eligible, standing by as an alternate
+	JackpotCandidateInvalidNotOptIn JackpotCandidateStatus = "INVALID_NOT_OPT_IN" // Not opted in before the cut-off
+	JackpotCandidateAwarded         JackpotCandidateStatus = "AWARDED"            // Currently holds the jackpot Winner record
+	JackpotCandidateForfeited       JackpotCandidateStatus = "FORFEITED"          // Was awarded but did not claim in time
+	JackpotCandidateBlacklisted     JackpotCandidateStatus = "INVALID_BLACKLISTED" // Blacklisted after selection, skipped at promotion or payout
+)
+
+// JackpotCandidate is one entry of the ordered jackpot chain: rank 0 is the first weighted pick,
+// higher ranks are the backup winners drawn for the promotion's claim rules
+type JackpotCandidate struct {
+	Rank      int                    `bson:"rank" json:"rank"`
+	UserID    primitive.ObjectID     `bson:"userId" json:"userId"`
+	MSISDN    string                 `bson:"msisdn" json:"msisdn"`
+	Points    int                    `bson:"points" json:"points"`
+	Status    JackpotCandidateStatus `bson:"status" json:"status"`
+	WinnerID  primitive.ObjectID     `bson:"winnerId,omitempty" json:"winnerId,omitempty"` // Winner record while awarded
+	UpdatedAt time.Time              `bson:"updatedAt" json:"updatedAt"`
+}
+
 // Define Prize Categories as constants for consistency
 const (
 	JackpotCategory     string = "JACKPOT"
@@ -49,8 +85,12 @@ type Draw struct {
 	DrawType                  string             `bson:"drawType" json:"drawType"` // e.g., "DAILY", "SATURDAY"
 	EligibleDigits            []int              `bson:"eligibleDigits" json:"eligibleDigits"`
 	UseDefaultDigits          bool               `bson:"useDefaultDigits" json:"useDefaultDigits"`
+	EligibilityWindow         *EligibilityWindowRule `bson:"eligibilityWindow,omitempty" json:"eligibilityWindow,omitempty"` // Rule in force when the draw was scheduled
+	WinRules                  *WinFrequencyRules `bson:"winRules,omitempty" json:"winRules,omitempty"`                 // Rules in force when the draw was executed
+	WinRuleRejections         []WinRuleRejection `bson:"winRuleRejections,omitempty" json:"winRuleRejections,omitempty"` // Picks refused by the win rules, in selection order
 	Status                    DrawStatus         `bson:"status" json:"status"`
 	Prizes                    []Prize            `bson:"prizes" json:"prizes"` // Embed prize structure
+	PrizeStructureVersion     int                `bson:"prizeStructureVersion,omitempty" json:"prizeStructureVersion,omitempty"` // Version the prizes were taken from; 0 for the legacy config
 	BaseJackpotAmount         float64            `bson:"baseJackpotAmount" json:"baseJackpotAmount"`
 	RolloverAmount            float64            `bson:"rolloverAmount" json:"rolloverAmount"` // Rollover amount *into* this draw
 	CalculatedJackpotAmount   float64            `bson:"calculatedJackpotAmount" json:"calculatedJackpotAmount"`
@@ -60,12 +100,44 @@ type Draw struct {
 	ExecutionStartTime        time.Time          `bson:"executionStartTime,omitempty" json:"executionStartTime,omitempty"`
 	ExecutionEndTime          time.Time          `bson:"executionEndTime,omitempty" json:"executionEndTime,omitempty"`
 	ExecutionLog              []string           `bson:"executionLog,omitempty" json:"executionLog,omitempty"`
+	ExecutionLeaseOwner       string             `bson:"executionLeaseOwner,omitempty" json:"executionLeaseOwner,omitempty"`         // Process currently executing the draw
+	ExecutionLeaseExpiresAt   time.Time          `bson:"executionLeaseExpiresAt,omitempty" json:"executionLeaseExpiresAt,omitempty"` // Renewed by the executing process's heartbeat
+	ExecutionIdempotencyKey   string             `bson:"executionIdempotencyKey,omitempty" json:"executionIdempotencyKey,omitempty"` // Key of the execute call that ran the draw
 	ErrorMessage              string             `bson:"errorMessage,omitempty" json:"errorMessage,omitempty"`
 	TotalParticipants         int                `bson:"totalParticipants,omitempty" json:"totalParticipants,omitempty"`           // Pool A count
 	EligibleOptedInParticipants int              `bson:"eligibleOptedInParticipants,omitempty" json:"eligibleOptedInParticipants,omitempty"` // Pool B count
+	ExcludedParticipants      int                `bson:"excludedParticipants,omitempty" json:"excludedParticipants,omitempty"`     // Removed from the pools before selection (e.g. blacklisted)
 	NumWinners                int                `bson:"numWinners,omitempty" json:"numWinners,omitempty"`                     // Total winners created for this draw
+	JackpotCandidates         []JackpotCandidate `bson:"jackpotCandidates,omitempty" json:"jackpotCandidates,omitempty"` // Ordered jackpot pick and alternates
+	JackpotAlternateCount     int                `bson:"jackpotAlternateCount,omitempty" json:"jackpotAlternateCount,omitempty"` // Alternates requested at execution
+	JackpotClaimWindowDays    int                `bson:"jackpotClaimWindowDays,omitempty" json:"jackpotClaimWindowDays,omitempty"` // Days a jackpot winner has to claim
+	SeedCommitment            string             `bson:"seedCommitment,omitempty" json:"seedCommitment,omitempty"` // SHA-256 of the secret seed, published at scheduling
+	Seed                      string             `bson:"seed,omitempty" json:"-"`                                  // Secret seed, never exposed before execution
+	RevealedSeed              string             `bson:"revealedSeed,omitempty" json:"revealedSeed,omitempty"`     // Seed published once the draw has executed
+	PoolDigest                string             `bson:"poolDigest,omitempty" json:"poolDigest,omitempty"`         // SHA-256 of the participant pools used for selection
+	VoidReason                string             `bson:"voidReason,omitempty" json:"voidReason,omitempty"`
+	VoidedBy                  string             `bson:"voidedBy,omitempty" json:"voidedBy,omitempty"` // Admin user ID (JWT subject) that voided the draw
+	VoidedAt                  time.Time          `bson:"voidedAt,omitempty" json:"voidedAt,omitempty"`
+	ReRunOfDrawID             primitive.ObjectID `bson:"reRunOfDrawId,omitempty" json:"reRunOfDrawId,omitempty"` // Original draw this draw re-runs
+	ReRunDrawID               primitive.ObjectID `bson:"reRunDrawId,omitempty" json:"reRunDrawId,omitempty"`     // Draw created to re-run this one
+	ReRunReason               string             `bson:"reRunReason,omitempty" json:"reRunReason,omitempty"`
+	ReRunRequestedBy          string             `bson:"reRunRequestedBy,omitempty" json:"reRunRequestedBy,omitempty"` // Admin user ID (JWT subject) that requested the re-run
 	CreatedAt                 time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt                 time.Time          `bson:"updatedAt" json:"updatedAt"`
 }
 
-
+// DrawVerification is the result of re-running a draw's winner selection from its revealed seed
+type DrawVerification struct {
+	DrawID            primitive.ObjectID `json:"drawId"`
+	SeedCommitment    string             `json:"seedCommitment"`
+	RevealedSeed      string             `json:"revealedSeed"`
+	CommitmentValid   bool               `json:"commitmentValid"`   // SHA-256(revealedSeed) == seedCommitment
+	StoredPoolDigest  string             `json:"storedPoolDigest"`
+	ReplayPoolDigest  string             `json:"replayPoolDigest"`
+	PoolDigestMatch   bool               `json:"poolDigestMatch"`
+	ExpectedWinners   []string           `json:"expectedWinners"` // "CATEGORY:MSISDN" produced by the replay
+	RecordedWinners   []string           `json:"recordedWinners"` // "CATEGORY:MSISDN" recorded for the draw
+	WinnersMatch      bool               `json:"winnersMatch"`
+	Verified          bool               `json:"verified"`
+	Notes             []string           `json:"notes,omitempty"`
+}
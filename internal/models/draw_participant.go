@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DrawParticipant is an immutable snapshot of one eligible user at the moment a draw was executed.
+// Stored in the draw_participants collection so eligibility and weights can be reconstructed after a dispute.
+// Users excluded before selection are recorded too, with ExclusionReason set; they take no part in selection.
+type DrawParticipant struct {
+	ID                primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	DrawID            primitive.ObjectID `bson:"drawId" json:"drawId"`
+	UserID            primitive.ObjectID `bson:"userId" json:"userId"`
+	MSISDN            string             `bson:"msisdn" json:"msisdn"`
+	Points            int                `bson:"points" json:"points"`                       // Points (selection weight) at selection time
+	InJackpotPool     bool               `bson:"inJackpotPool" json:"inJackpotPool"`         // Member of Pool A
+	InConsolationPool bool               `bson:"inConsolationPool" json:"inConsolationPool"` // Member of Pool B
+	OptInStatus       bool               `bson:"optInStatus" json:"optInStatus"`
+	OptInDate         time.Time          `bson:"optInDate,omitempty" json:"optInDate,omitempty"`
+	ExclusionReason   string             `bson:"exclusionReason,omitempty" json:"exclusionReason,omitempty"` // Set when the user was removed from the pools before selection
+	CreatedAt         time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// Reasons a user can be excluded from a draw's pools
+const (
+	ExclusionReasonBlacklisted = "BLACKLISTED"
+)
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DrawSimulation is the read-only preview of a draw produced by the simulate endpoint.
+// Nothing in it is persisted.
+type DrawSimulation struct {
+	DrawID                primitive.ObjectID     `json:"drawId"`
+	DrawType              string                 `json:"drawType"`
+	DrawDate              time.Time              `json:"drawDate"`
+	EligibilityStart      time.Time              `json:"eligibilityStart"`
+	EligibilityCutoff     time.Time              `json:"eligibilityCutoff"`
+	JackpotPoolSize       int                    `json:"jackpotPoolSize"`       // Pool A
+	ConsolationPoolSize   int                    `json:"consolationPoolSize"`   // Pool B
+	ExcludedParticipants  int                    `json:"excludedParticipants"`  // Removed from the pools before selection
+	JackpotPoolWeight     int64                  `json:"jackpotPoolWeight"`     // Sum of Pool A weights
+	ConsolationPoolWeight int64                  `json:"consolationPoolWeight"` // Sum of Pool B weights
+	PoolDigest            string                 `json:"poolDigest"`
+	WinRules              *WinFrequencyRules     `json:"winRules"`      // Current rules, applied to the sample and Monte Carlo selections
+	Participants          []SimulatedParticipant `json:"participants"`  // Heaviest participants first, capped by the request
+	SampleWinners         []string               `json:"sampleWinners"` // "CATEGORY:MSISDN" from one random selection
+	MonteCarlo            *DrawMonteCarloResult  `json:"monteCarlo,omitempty"`
+}
+
+// SimulatedParticipant describes one participant's chances in a simulated draw
+type SimulatedParticipant struct {
+	MSISDN                 string  `json:"msisdn"`
+	Points                 int     `json:"points"`
+	Weight                 int64   `json:"weight"`
+	InJackpotPool          bool    `json:"inJackpotPool"`
+	InConsolationPool      bool    `json:"inConsolationPool"`
+	JackpotProbability     float64 `json:"jackpotProbability"`               // Chance of being the first jackpot pick: weight / Pool A weight
+	ObservedJackpotRate    float64 `json:"observedJackpotRate,omitempty"`    // Monte Carlo: share of iterations picking them first for the jackpot
+	ObservedConsolationWin float64 `json:"observedConsolationWin,omitempty"` // Monte Carlo: share of iterations awarding them a consolation prize
+}
+
+// DrawMonteCarloResult summarises repeated random selections over the same pools
+type DrawMonteCarloResult struct {
+	Iterations int `json:"iterations"`
+	// ChiSquare compares observed first jackpot picks of the listed participants (plus one
+	// bucket for everyone else) against their expected probabilities. With DegreesOfFreedom
+	// degrees of freedom, a value far above DegreesOfFreedom suggests the weighting is off.
+	ChiSquare        float64 `json:"chiSquare"`
+	DegreesOfFreedom int     `json:"degreesOfFreedom"`
+	MaxDeviation     float64 `json:"maxDeviation"` // Largest |observed - expected| jackpot rate among listed participants
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RolloverDestination decides which draw receives the jackpot of a draw that is not won
+type RolloverDestination string
+
+const (
+	RolloverToNextDraw     RolloverDestination = "NEXT_DRAW"      // The next scheduled draw of any type
+	RolloverToNextSameType RolloverDestination = "NEXT_SAME_TYPE" // The next scheduled draw of the same type
+	RolloverToDrawType     RolloverDestination = "DRAW_TYPE"      // The next scheduled draw of RolloverDrawType
+)
+
+// DrawTypeDefinition is an entry of the draw type registry (draw_types collection). It holds
+// everything that differs between draw types, such as DAILY, SATURDAY or a seasonal mega draw.
+type DrawTypeDefinition struct {
+	ID                  primitive.ObjectID    `bson:"_id,omitempty" json:"id,omitempty"`
+	Code                string                `bson:"code" json:"code"` // Upper-case key stored on draws, e.g. "DAILY"
+	Name                string                `bson:"name" json:"name"`
+	Description         string                `bson:"description,omitempty" json:"description,omitempty"`
+	Active              bool                  `bson:"active" json:"active"`                         // Inactive types cannot be scheduled
+	Weekdays            []int                 `bson:"weekdays,omitempty" json:"weekdays,omitempty"` // Days the scheduler creates this draw (0 = Sunday); empty for draws scheduled by hand
+	EligibilityWindow   EligibilityWindowRule `bson:"eligibilityWindow" json:"eligibilityWindow"`
+	PrizeStructureKey   string                `bson:"prizeStructureKey" json:"prizeStructureKey"`             // SystemConfig key of the prize structure JSON
+	BaseJackpotKey      string                `bson:"baseJackpotKey" json:"baseJackpotKey"`                   // SystemConfig key of the base jackpot amount
+	DefaultDigits       []int                 `bson:"defaultDigits,omitempty" json:"defaultDigits,omitempty"` // Default eligible digits; empty uses the weekday defaults
+	RolloverDestination RolloverDestination   `bson:"rolloverDestination" json:"rolloverDestination"`
+	RolloverDrawType    string                `bson:"rolloverDrawType,omitempty" json:"rolloverDrawType,omitempty"` // Target type of RolloverToDrawType
+	Headline            bool                  `bson:"headline" json:"headline"`                                     // Its jackpot is reported by the jackpot status endpoint by default
+	CreatedAt           time.Time             `bson:"createdAt" json:"createdAt"`
+	UpdatedAt           time.Time             `bson:"updatedAt" json:"updatedAt"`
+}
@@ -15,7 +15,11 @@ type JackpotRollover struct {
 	RolloverAmount      float64            `bson:"rolloverAmount" json:"rolloverAmount"` // The amount being rolled over
 	DestinationDrawID   primitive.ObjectID `bson:"destinationDrawId,omitempty" json:"destinationDrawId,omitempty"` // The draw the amount is rolled over *to* (e.g., next Saturday)
 	DestinationDrawDate time.Time          `bson:"destinationDrawDate" json:"destinationDrawDate"`
+	DestinationDrawType string             `bson:"destinationDrawType,omitempty" json:"destinationDrawType,omitempty"` // Empty when any draw type may receive it
 	Reason              string             `bson:"reason" json:"reason"` // e.g., "INVALID_WINNER_NOT_OPTED_IN"
 	CreatedAt           time.Time          `bson:"createdAt" json:"createdAt"` // Timestamp when the rollover was recorded
+	ReversedAt          time.Time          `bson:"reversedAt,omitempty" json:"reversedAt,omitempty"` // Set when the source draw was voided
+	ReversedBy          string             `bson:"reversedBy,omitempty" json:"reversedBy,omitempty"`
+	ReversalReason      string             `bson:"reversalReason,omitempty" json:"reversalReason,omitempty"`
 }
 
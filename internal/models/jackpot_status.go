@@ -6,6 +6,7 @@ import (
 
 // JackpotStatus represents the current status of the jackpot
 type JackpotStatus struct {
+	DrawType         string    `json:"drawType"` // Draw type the status is reported for
 	CurrentAmount    float64   `json:"currentAmount"`
 	LastDrawDate     time.Time `json:"lastDrawDate"` // Date of the last relevant draw
 	LastWinnerMSISDN string    `json:"lastWinnerMsisdn,omitempty"` // Added: MSISDN of the last jackpot winner (if any)
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// LeaderLock is a named lease held by a single replica until ExpiresAt
+type LeaderLock struct {
+	Name      string    `bson:"_id" json:"name"`
+	Owner     string    `bson:"owner" json:"owner"`
+	ExpiresAt time.Time `bson:"expiresAt" json:"expiresAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
+}
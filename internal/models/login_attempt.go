@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Login attempt counter kinds
+const (
+	LoginAttemptEmail = "email"
+	LoginAttemptIP    = "ip"
+)
+
+// LoginAttempt counts recent failed logins for one email address or client IP
+type LoginAttempt struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Kind          string             `bson:"kind" json:"kind"`   // LoginAttemptEmail or LoginAttemptIP
+	Value         string             `bson:"value" json:"value"` // The email address or IP
+	Failures      int                `bson:"failures" json:"failures"`
+	LastFailureAt time.Time          `bson:"lastFailureAt" json:"lastFailureAt"`
+	LockedUntil   *time.Time         `bson:"lockedUntil,omitempty" json:"lockedUntil,omitempty"`
+}
+
+// UnlockLoginRequest is the body of POST /admins/lockouts/unlock; give an email, an IP or both
+type UnlockLoginRequest struct {
+	Email string `json:"email"`
+	IP    string `json:"ip"`
+}
@@ -9,11 +9,18 @@ import (
 // PointTransaction records points awarded for a specific top-up event.
 type PointTransaction struct {
 	ID                 primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	TransactionRef     string             `bson:"transactionRef,omitempty" json:"transactionRef,omitempty"` // Top-up it was awarded for; unique, so a top-up is credited once
 	UserID             primitive.ObjectID `bson:"userId" json:"userId"` // Link to the User model
 	MSISDN             string             `bson:"msisdn" json:"msisdn"` // Denormalized for easier querying?
 	TopupAmount        float64            `bson:"topupAmount" json:"topupAmount"`
 	PointsAwarded      int                `bson:"pointsAwarded" json:"pointsAwarded"`
 	TransactionTimestamp time.Time          `bson:"transactionTimestamp" json:"transactionTimestamp"` // Time of the top-up event
 	CreatedAt          time.Time          `bson:"createdAt" json:"createdAt"` // Time this record was created
+	Applied            *bool              `bson:"applied,omitempty" json:"applied,omitempty"` // Set once the points reach the user's balance; nil on records written before the flag, which were credited with their insert
+}
+
+// Pending reports whether the points were recorded but not yet added to the user's balance
+func (t *PointTransaction) Pending() bool {
+	return t.Applied != nil && !*t.Applied
 }
 
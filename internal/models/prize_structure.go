@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PrizeStructureStatus is the approval state of a prize structure version
+type PrizeStructureStatus string
+
+const (
+	PrizeStructureDraft    PrizeStructureStatus = "DRAFT"    // Staged, not yet used by any draw
+	PrizeStructureApproved PrizeStructureStatus = "APPROVED" // Used by draws scheduled on or after EffectiveFrom
+	PrizeStructureRejected PrizeStructureStatus = "REJECTED" // Never used
+)
+
+// PrizeStructureVersion is one version of the prizes of a draw type (prize_structure_versions
+// collection). Versions are never edited once approved; a change is a new version.
+type PrizeStructureVersion struct {
+	ID              primitive.ObjectID   `bson:"_id,omitempty" json:"id,omitempty"`
+	DrawType        string               `bson:"drawType" json:"drawType"`
+	Version         int                  `bson:"version" json:"version"` // 1, 2, ... per draw type
+	Prizes          []Prize              `bson:"prizes" json:"prizes"`
+	EffectiveFrom   time.Time            `bson:"effectiveFrom" json:"effectiveFrom"` // First draw date (midnight UTC) the version applies to
+	Status          PrizeStructureStatus `bson:"status" json:"status"`
+	Notes           string               `bson:"notes,omitempty" json:"notes,omitempty"`
+	CreatedBy       string               `bson:"createdBy" json:"createdBy"`
+	ReviewedBy      string               `bson:"reviewedBy,omitempty" json:"reviewedBy,omitempty"`
+	ReviewedAt      *time.Time           `bson:"reviewedAt,omitempty" json:"reviewedAt,omitempty"`
+	RejectionReason string               `bson:"rejectionReason,omitempty" json:"rejectionReason,omitempty"`
+	CreatedAt       time.Time            `bson:"createdAt" json:"createdAt"`
+	UpdatedAt       time.Time            `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Kinds of change between two prize structure versions
+const (
+	PrizeChangeAdded   = "ADDED"
+	PrizeChangeRemoved = "REMOVED"
+	PrizeChangeUpdated = "UPDATED"
+)
+
+// PrizeCategoryChange describes how one prize category differs between two versions
+type PrizeCategoryChange struct {
+	Category      string  `json:"category"`
+	Change        string  `json:"change"`
+	OldAmount     float64 `json:"oldAmount"`
+	NewAmount     float64 `json:"newAmount"`
+	OldNumWinners int     `json:"oldNumWinners"`
+	NewNumWinners int     `json:"newNumWinners"`
+}
+
+// PrizeStructureDiff lists the prize categories that differ between two versions
+type PrizeStructureDiff struct {
+	DrawType       string                `json:"drawType"`
+	FromVersion    int                   `json:"fromVersion"`
+	ToVersion      int                   `json:"toVersion"`
+	Changes        []PrizeCategoryChange `json:"changes"`
+	OldTotalPayout float64               `json:"oldTotalPayout"`
+	NewTotalPayout float64               `json:"newTotalPayout"`
+}
@@ -0,0 +1,98 @@
+package models
+
+// Admin roles. A role is stored on the AdminUser and copied into the JWT at login.
+const (
+	RoleSuperAdmin   = "super_admin"   // Everything, including role assignment
+	RoleDrawOperator = "draw_operator" // Runs draws and their configuration
+	RoleFinance      = "finance"       // Prizes, jackpots and claims
+	RoleSupport      = "support"       // Subscriber care: users, opt-outs and claims
+	RoleReadOnly     = "read_only"     // Every read endpoint, nothing else
+	RoleLegacyAdmin  = "admin"         // Assigned before roles existed, including by the old open registration; read-only until a super admin assigns a role
+)
+
+// Permission is an operation a route requires
+type Permission string
+
+const (
+	PermUsersRead          Permission = "users:read"
+	PermUsersWrite         Permission = "users:write"
+	PermDrawsRead          Permission = "draws:read"
+	PermDrawsManage        Permission = "draws:manage"  // Schedule, edit, simulate and re-run draws
+	PermDrawsExecute       Permission = "draws:execute" // Request and approve execution or voiding
+	PermDrawsConfig        Permission = "draws:config"  // Draw types, eligibility windows and win rules
+	PermParticipantsExport Permission = "participants:export"
+	PermPrizesManage       Permission = "prizes:manage" // Prize structures and base jackpots
+	PermClaimsManage       Permission = "claims:manage"
+	PermApprovalsRead      Permission = "approvals:read"
+	PermApprovalsReview    Permission = "approvals:review" // Approvers also need the permission of the action itself
+	PermTopupsRead         Permission = "topups:read"
+	PermTopupsWrite        Permission = "topups:write"
+	PermNotificationsRead  Permission = "notifications:read"
+	PermDashboardRead      Permission = "dashboard:read"
+	PermEventsRead         Permission = "events:read"
+	PermEventsWrite        Permission = "events:write"
+	PermAdminsManage       Permission = "admins:manage"
+	PermAuditRead          Permission = "audit:read" // The admin audit log; super_admin only
+	PermOwnAccount         Permission = "account:own" // The caller's own login settings, e.g. two-factor enrolment
+)
+
+// readPermissions are granted to every role
+var readPermissions = []Permission{
+	PermUsersRead, PermDrawsRead, PermApprovalsRead, PermTopupsRead,
+	PermNotificationsRead, PermDashboardRead, PermEventsRead, PermOwnAccount,
+}
+
+// RolePermissions is the permission matrix. super_admin is granted every permission and is
+// not listed.
+var RolePermissions = map[string][]Permission{
+	RoleDrawOperator: append([]Permission{
+		PermDrawsManage, PermDrawsExecute, PermDrawsConfig, PermParticipantsExport,
+		PermApprovalsReview, PermEventsWrite,
+	}, readPermissions...),
+	RoleFinance: append([]Permission{
+		PermPrizesManage, PermClaimsManage, PermApprovalsReview, PermParticipantsExport,
+	}, readPermissions...),
+	RoleSupport: append([]Permission{
+		PermUsersWrite, PermClaimsManage,
+	}, readPermissions...),
+	RoleReadOnly:    readPermissions,
+	RoleLegacyAdmin: readPermissions,
+}
+
+// Roles lists the roles that can be assigned
+var Roles = []string{RoleSuperAdmin, RoleDrawOperator, RoleFinance, RoleSupport, RoleReadOnly}
+
+// ValidRole reports whether a role can be assigned
+func ValidRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission reports whether a role is granted a permission
+func HasPermission(role string, permission Permission) bool {
+	if role == RoleSuperAdmin {
+		return true
+	}
+	for _, p := range RolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// ApprovalActionPermission is the permission an admin needs to request or approve an action
+func ApprovalActionPermission(action ApprovalAction) Permission {
+	switch action {
+	case ApprovalActionExecuteDraw, ApprovalActionVoidDraw:
+		return PermDrawsExecute
+	case ApprovalActionPrizeStructure, ApprovalActionBaseJackpot, ApprovalActionDrawTypeKeys:
+		return PermPrizesManage
+	default:
+		return PermAdminsManage
+	}
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RefreshToken is a server-side refresh token; only the SHA-256 hash of the token is stored.
+// The tokens of one login share a SessionID. Using a token marks it used and issues its
+// successor, so a used token presented again means it was copied, and the session is revoked.
+type RefreshToken struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	SessionID       primitive.ObjectID `bson:"sessionId" json:"sessionId"`
+	AdminUserID     primitive.ObjectID `bson:"adminUserId" json:"adminUserId"`
+	TokenHash       string             `bson:"tokenHash" json:"-"`
+	AccessJTI       string             `bson:"accessJti" json:"-"` // Access token issued alongside, denylisted when the session is revoked
+	AccessExpiresAt time.Time          `bson:"accessExpiresAt" json:"accessExpiresAt"`
+	ExpiresAt       time.Time          `bson:"expiresAt" json:"expiresAt"`
+	UsedAt          *time.Time         `bson:"usedAt,omitempty" json:"usedAt,omitempty"`
+	RevokedAt       *time.Time         `bson:"revokedAt,omitempty" json:"revokedAt,omitempty"`
+	RevokedReason   string             `bson:"revokedReason,omitempty" json:"revokedReason,omitempty"`
+	CreatedAt       time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// RevokedToken denylists an access token by its jti until it would have expired anyway
+type RevokedToken struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	JTI         string             `bson:"jti" json:"jti"`
+	AdminUserID primitive.ObjectID `bson:"adminUserId" json:"adminUserId"`
+	Reason      string             `bson:"reason" json:"reason"`
+	ExpiresAt   time.Time          `bson:"expiresAt" json:"expiresAt"`
+	CreatedAt   time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// AuthTokens is returned by login and refresh
+type AuthTokens struct {
+	Token            string `json:"token"` // Access token, sent as "Authorization: Bearer <token>"
+	RefreshToken     string `json:"refreshToken"`
+	TokenType        string `json:"tokenType"`
+	ExpiresIn        int    `json:"expiresIn"`        // Seconds until the access token expires
+	RefreshExpiresIn int    `json:"refreshExpiresIn"` // Seconds until the refresh token expires
+}
+
+// RefreshTokenRequest is the body of POST /auth/refresh and POST /auth/logout
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
@@ -6,6 +6,13 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Topup channels
+const (
+	TopupChannelMTNFeed = "MTN_API"     // Pulled from the MTN recharge API
+	TopupChannelManual  = "MANUAL"      // Entered through POST /topups
+	TopupChannelWebhook = "MTN_WEBHOOK" // Pushed by MTN to POST /webhooks/topups
+)
+
 // Topup represents a topup transaction
 type Topup struct {
 	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
@@ -16,6 +23,15 @@ type Topup struct {
 	TransactionRef string             `bson:"transactionRef" json:"transactionRef"`
 	PointsEarned   int                `bson:"pointsEarned" json:"pointsEarned"`
 	Processed      bool               `bson:"processed" json:"processed"`
+	ProcessedAt    time.Time          `bson:"processedAt,omitempty" json:"processedAt,omitempty"` // When points were allocated
 	CreatedAt      time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt      time.Time          `bson:"updatedAt" json:"updatedAt"`
 }
+
+// TopupIngestionCheckpoint is the high-water mark of MTN feed ingestion: every topup dated in
+// [CoveredFrom, Through) has been recorded and processed
+type TopupIngestionCheckpoint struct {
+	CoveredFrom time.Time `json:"coveredFrom"`
+	Through     time.Time `json:"through"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
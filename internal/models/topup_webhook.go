@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Topup webhook event states
+const (
+	WebhookEventPending    = "PENDING"    // Waiting for its first or next attempt
+	WebhookEventProcessing = "PROCESSING" // Claimed by a worker until LeaseExpiresAt
+	WebhookEventProcessed  = "PROCESSED"
+	WebhookEventFailed     = "FAILED" // Given up after the maximum number of attempts
+)
+
+// TopupWebhookEvent is a recharge MTN pushed to the topup webhook, queued until it has been
+// processed into a Topup. The TransactionRef is the _id, so a recharge is queued only once
+// however often it is delivered.
+type TopupWebhookEvent struct {
+	TransactionRef string             `bson:"_id" json:"transactionRef"`
+	MSISDN         string             `bson:"msisdn" json:"msisdn"`
+	Amount         float64            `bson:"amount" json:"amount"`
+	Date           time.Time          `bson:"date" json:"date"` // When the recharge was made
+	Status         string             `bson:"status" json:"status"`
+	Attempts       int                `bson:"attempts" json:"attempts"`
+	LastError      string             `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	NextAttemptAt  time.Time          `bson:"nextAttemptAt" json:"nextAttemptAt"`
+	LeaseExpiresAt time.Time          `bson:"leaseExpiresAt,omitempty" json:"leaseExpiresAt,omitempty"`
+	TopupID        primitive.ObjectID `bson:"topupId,omitempty" json:"topupId,omitempty"`
+	PointsEarned   int                `bson:"pointsEarned" json:"pointsEarned"`
+	ReceivedAt     time.Time          `bson:"receivedAt" json:"receivedAt"`
+	ProcessedAt    time.Time          `bson:"processedAt,omitempty" json:"processedAt,omitempty"`
+	UpdatedAt      time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
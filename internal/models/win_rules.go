@@ -0,0 +1,25 @@
+package models
+
+// WinFrequencyRules limit how often the same subscriber can win. The rules live in
+// SystemConfig and are copied onto each draw when it is executed.
+type WinFrequencyRules struct {
+	MaxWinsPerWindow    int  `bson:"maxWinsPerWindow" json:"maxWinsPerWindow"`       // Consolation picks are refused once a user has this many wins in the window; 0 disables the cap
+	WinWindowDays       int  `bson:"winWindowDays" json:"winWindowDays"`             // Length of the cap window, ending on the draw date
+	JackpotCooldownDays int  `bson:"jackpotCooldownDays" json:"jackpotCooldownDays"` // A jackpot winner cannot win any prize for this many days; 0 disables the cooldown
+	OnePrizePerDraw     bool `bson:"onePrizePerDraw" json:"onePrizePerDraw"`         // Jackpot picks and alternates cannot also win a consolation prize
+}
+
+// Names of the win-frequency rules a pick can be refused by
+const (
+	WinRuleCap             = "WIN_CAP"
+	WinRuleJackpotCooldown = "JACKPOT_COOLDOWN"
+)
+
+// WinRuleRejection records a pick refused by a win-frequency rule during a draw's selection.
+// Verification replays these refusals instead of re-evaluating the rules against a win
+// history that has changed since.
+type WinRuleRejection struct {
+	MSISDN   string `bson:"msisdn" json:"msisdn"`
+	Category string `bson:"category" json:"category"` // Prize category the user was picked for
+	Rule     string `bson:"rule" json:"rule"`
+}
@@ -15,6 +15,7 @@ const (
 	ClaimStatusPaid       ClaimStatus = "PAID"       // Prize has been paid/disbursed
 	ClaimStatusFailed     ClaimStatus = "FAILED"     // Claim processing failed
 	ClaimStatusIneligible ClaimStatus = "INELIGIBLE" // Winner found ineligible post-selection
+	ClaimStatusForfeited  ClaimStatus = "FORFEITED"  // Winner did not claim before the claim deadline
 )
 
 // Winner represents a winning entry in a draw
@@ -29,6 +30,8 @@ type Winner struct {
 	ClaimStatus   ClaimStatus        `bson:"claimStatus" json:"claimStatus"`
 	ClaimNotes    string             `bson:"claimNotes,omitempty" json:"claimNotes,omitempty"`
 	ClaimDate     time.Time          `bson:"claimDate,omitempty" json:"claimDate,omitempty"`
+	ClaimDeadline time.Time          `bson:"claimDeadline,omitempty" json:"claimDeadline,omitempty"` // Jackpot only: forfeited if not claimed by then
+	AlternateRank int                `bson:"alternateRank,omitempty" json:"alternateRank,omitempty"` // Jackpot only: rank in the draw's candidate chain
 	CreatedAt     time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt     time.Time          `bson:"updatedAt" json:"updatedAt"`
 }
@@ -0,0 +1,124 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AdminInviteRepository implements the repositories.AdminInviteRepository interface
+type AdminInviteRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAdminInviteRepository creates a new AdminInviteRepository
+func NewAdminInviteRepository(db *mongo.Database) repositories.AdminInviteRepository {
+	return &AdminInviteRepository{
+		collection: db.Collection("admin_invites"),
+	}
+}
+
+// openFilter matches invites that have not been accepted, revoked or expired
+func openFilter(now time.Time) bson.M {
+	return bson.M{
+		"acceptedAt": nil,
+		"revokedAt":  nil,
+		"expiresAt":  bson.M{"$gt": now},
+	}
+}
+
+// Create inserts a new invite
+func (r *AdminInviteRepository) Create(ctx context.Context, invite *models.AdminInvite) error {
+	now := time.Now()
+	invite.CreatedAt = now
+	invite.UpdatedAt = now
+	result, err := r.collection.InsertOne(ctx, invite)
+	if err != nil {
+		return fmt.Errorf("failed to create admin invite: %w", err)
+	}
+	invite.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindByID finds an invite by ID
+func (r *AdminInviteRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.AdminInvite, error) {
+	var invite models.AdminInvite
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&invite); err != nil {
+		return nil, fmt.Errorf("failed to find admin invite %s: %w", id.Hex(), err)
+	}
+	return &invite, nil
+}
+
+// FindByTokenHash finds an invite by the hash of its token
+func (r *AdminInviteRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*models.AdminInvite, error) {
+	var invite models.AdminInvite
+	if err := r.collection.FindOne(ctx, bson.M{"tokenHash": tokenHash}).Decode(&invite); err != nil {
+		return nil, fmt.Errorf("failed to find admin invite: %w", err)
+	}
+	return &invite, nil
+}
+
+// FindAll returns every invite, newest first
+func (r *AdminInviteRepository) FindAll(ctx context.Context) ([]*models.AdminInvite, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"createdAt": -1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query admin invites: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var invites []*models.AdminInvite
+	if err := cursor.All(ctx, &invites); err != nil {
+		return nil, fmt.Errorf("failed to decode admin invites: %w", err)
+	}
+	if invites == nil {
+		invites = []*models.AdminInvite{}
+	}
+	return invites, nil
+}
+
+// FindOpenByEmail finds the open invite for an email. It returns mongo.ErrNoDocuments
+// (wrapped) when there is none.
+func (r *AdminInviteRepository) FindOpenByEmail(ctx context.Context, email string, now time.Time) (*models.AdminInvite, error) {
+	filter := openFilter(now)
+	filter["email"] = email
+	var invite models.AdminInvite
+	if err := r.collection.FindOne(ctx, filter).Decode(&invite); err != nil {
+		return nil, fmt.Errorf("failed to find open invite for %s: %w", email, err)
+	}
+	return &invite, nil
+}
+
+// MarkAccepted records the account created from an invite. It only matches an open invite,
+// so of two requests racing on the same token only one succeeds.
+func (r *AdminInviteRepository) MarkAccepted(ctx context.Context, id, adminUserID primitive.ObjectID, now time.Time) (bool, error) {
+	filter := openFilter(now)
+	filter["_id"] = id
+	res, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{
+		"acceptedAt":  now,
+		"adminUserId": adminUserID,
+		"updatedAt":   now,
+	}})
+	if err != nil {
+		return false, fmt.Errorf("failed to accept admin invite %s: %w", id.Hex(), err)
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// Revoke withdraws an invite that has not been accepted or revoked yet
+func (r *AdminInviteRepository) Revoke(ctx context.Context, id primitive.ObjectID, revokedBy string, now time.Time) (bool, error) {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "acceptedAt": nil, "revokedAt": nil},
+		bson.M{"$set": bson.M{"revokedAt": now, "revokedBy": revokedBy, "updatedAt": now}},
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke admin invite %s: %w", id.Hex(), err)
+	}
+	return res.MatchedCount > 0, nil
+}
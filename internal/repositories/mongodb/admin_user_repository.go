@@ -2,7 +2,8 @@ package mongodb
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"time"
 
 	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
 	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
@@ -55,21 +56,69 @@ func (r *adminUserRepository) FindByID(ctx context.Context, id primitive.ObjectI
 	 err := r.collection.FindOne(ctx, filter).Decode(&adminUser)
 	 if err != nil {
 	 	 if err == mongo.ErrNoDocuments {
-	 	 	return nil, errors.New("admin user not found") // Or return mongo.ErrNoDocuments
+	 	 	return nil, fmt.Errorf("admin user not found: %w", mongo.ErrNoDocuments)
 	 	 }
 	 	return nil, err
 	 }
 	return &adminUser, nil
 }
 
-// Update updates an existing admin user
+// Update updates an existing admin user. The TOTP counter only moves forward, so saving a copy
+// read before a concurrent login cannot make an accepted code usable again.
 func (r *adminUserRepository) Update(ctx context.Context, adminUser *models.AdminUser) error {
+	raw, err := bson.Marshal(adminUser)
+	if err != nil {
+		return fmt.Errorf("failed to encode admin user: %w", err)
+	}
+	var fields bson.M
+	if err := bson.Unmarshal(raw, &fields); err != nil {
+		return fmt.Errorf("failed to encode admin user: %w", err)
+	}
+	delete(fields, "_id")
+	delete(fields, "totpLastCounter")
+
 	filter := bson.M{"_id": adminUser.ID}
-	update := bson.M{"$set": adminUser}
-	_, err := r.collection.UpdateOne(ctx, filter, update)
+	update := bson.M{
+		"$set": fields,
+		"$max": bson.M{"totpLastCounter": adminUser.TOTPLastCounter},
+	}
+	_, err = r.collection.UpdateOne(ctx, filter, update)
 	return err
 }
 
+// ConsumeTOTPCounter records the time step of an accepted TOTP code, only if it is later than
+// the last one recorded, so that concurrent requests cannot both use the same code
+func (r *adminUserRepository) ConsumeTOTPCounter(ctx context.Context, id primitive.ObjectID, counter int64) (bool, error) {
+	filter := bson.M{
+		"_id": id,
+		"$or": []bson.M{
+			{"totpLastCounter": bson.M{"$lt": counter}},
+			{"totpLastCounter": bson.M{"$exists": false}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"totpLastCounter": counter, "updatedAt": time.Now()}}
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to record TOTP time step: %w", err)
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+// ConsumeRecoveryCode removes a recovery code by its hash. Only the request that removes it
+// may use it.
+func (r *adminUserRepository) ConsumeRecoveryCode(ctx context.Context, id primitive.ObjectID, hash string) (bool, error) {
+	filter := bson.M{"_id": id, "recoveryCodes": hash}
+	update := bson.M{
+		"$pull": bson.M{"recoveryCodes": hash},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	}
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	return res.ModifiedCount > 0, nil
+}
+
 // Delete removes an admin user by ID
 func (r *adminUserRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
 	filter := bson.M{"_id": id}
@@ -0,0 +1,133 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ApprovalRepository implements the repositories.ApprovalRepository interface
+type ApprovalRepository struct {
+	collection *mongo.Collection
+}
+
+// NewApprovalRepository creates a new ApprovalRepository
+func NewApprovalRepository(db *mongo.Database) repositories.ApprovalRepository {
+	return &ApprovalRepository{
+		collection: db.Collection("approvals"),
+	}
+}
+
+// Create inserts a new approval request
+func (r *ApprovalRepository) Create(ctx context.Context, request *models.ApprovalRequest) error {
+	now := time.Now()
+	request.CreatedAt = now
+	request.UpdatedAt = now
+	result, err := r.collection.InsertOne(ctx, request)
+	if err != nil {
+		return fmt.Errorf("failed to create approval request: %w", err)
+	}
+	request.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindByID finds an approval request by ID
+func (r *ApprovalRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.ApprovalRequest, error) {
+	var request models.ApprovalRequest
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&request); err != nil {
+		return nil, fmt.Errorf("failed to find approval request %s: %w", id.Hex(), err)
+	}
+	return &request, nil
+}
+
+// FindByStatus returns approval requests with a status ("" for all), newest first
+func (r *ApprovalRepository) FindByStatus(ctx context.Context, status models.ApprovalStatus) ([]*models.ApprovalRequest, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"requestedAt": -1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query approval requests: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var requests []*models.ApprovalRequest
+	if err := cursor.All(ctx, &requests); err != nil {
+		return nil, fmt.Errorf("failed to decode approval requests: %w", err)
+	}
+	if requests == nil {
+		requests = []*models.ApprovalRequest{}
+	}
+	return requests, nil
+}
+
+// FindPending finds the unexpired PENDING request for an action and target. It returns
+// mongo.ErrNoDocuments (wrapped) when there is none.
+func (r *ApprovalRepository) FindPending(ctx context.Context, action models.ApprovalAction, target string, now time.Time) (*models.ApprovalRequest, error) {
+	filter := bson.M{
+		"action":    action,
+		"target":    target,
+		"status":    models.ApprovalStatusPending,
+		"expiresAt": bson.M{"$gt": now},
+	}
+	var request models.ApprovalRequest
+	if err := r.collection.FindOne(ctx, filter).Decode(&request); err != nil {
+		return nil, fmt.Errorf("failed to find pending %s approval for %s: %w", action, target, err)
+	}
+	return &request, nil
+}
+
+// FindLatest finds the newest request for an action and target, whatever its status. It
+// returns mongo.ErrNoDocuments (wrapped) when there is none.
+func (r *ApprovalRepository) FindLatest(ctx context.Context, action models.ApprovalAction, target string) (*models.ApprovalRequest, error) {
+	opts := options.FindOne().SetSort(bson.M{"requestedAt": -1})
+	var request models.ApprovalRequest
+	if err := r.collection.FindOne(ctx, bson.M{"action": action, "target": target}, opts).Decode(&request); err != nil {
+		return nil, fmt.Errorf("failed to find %s approval for %s: %w", action, target, err)
+	}
+	return &request, nil
+}
+
+// ReplaceIfStatus replaces an approval request only if its stored status still equals
+// expected, so that two reviewers racing on the same request cannot both act on it
+func (r *ApprovalRepository) ReplaceIfStatus(ctx context.Context, request *models.ApprovalRequest, expected models.ApprovalStatus) (bool, error) {
+	request.UpdatedAt = time.Now()
+	res, err := r.collection.ReplaceOne(ctx, bson.M{"_id": request.ID, "status": expected}, request)
+	if err != nil {
+		return false, fmt.Errorf("failed to update approval request %s: %w", request.ID.Hex(), err)
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// Endorse records endorsedBy as the first approver of a PENDING request that has none yet, so
+// that of two admins endorsing at once only one is recorded
+func (r *ApprovalRepository) Endorse(ctx context.Context, id primitive.ObjectID, endorsedBy string, at time.Time) (bool, error) {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": models.ApprovalStatusPending, "endorsedBy": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"endorsedBy": endorsedBy, "endorsedAt": at, "updatedAt": at}},
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to endorse approval request %s: %w", id.Hex(), err)
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+// ExpirePending marks PENDING requests whose expiry has passed as EXPIRED
+func (r *ApprovalRepository) ExpirePending(ctx context.Context, now time.Time) (int64, error) {
+	res, err := r.collection.UpdateMany(ctx,
+		bson.M{"status": models.ApprovalStatusPending, "expiresAt": bson.M{"$lte": now}},
+		bson.M{"$set": bson.M{"status": models.ApprovalStatusExpired, "updatedAt": now}},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire approval requests: %w", err)
+	}
+	return res.ModifiedCount, nil
+}
@@ -0,0 +1,131 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// auditAppendAttempts bounds the retries when concurrent appends race for the next sequence
+const auditAppendAttempts = 10
+
+// AuditRepository implements the repositories.AuditRepository interface
+type AuditRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAuditRepository creates a new AuditRepository
+func NewAuditRepository(db *mongo.Database) repositories.AuditRepository {
+	return &AuditRepository{
+		collection: db.Collection("audit_events"),
+	}
+}
+
+// Append adds an event to the end of the chain. The sequence is the _id, so of two appends
+// racing for the same sequence one collides on _id and retries behind the other.
+func (r *AuditRepository) Append(ctx context.Context, event *models.AuditEvent) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	event.CreatedAt = event.CreatedAt.UTC().Truncate(time.Millisecond)
+
+	for attempt := 0; attempt < auditAppendAttempts; attempt++ {
+		var last models.AuditEvent
+		err := r.collection.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.M{"_id": -1})).Decode(&last)
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			event.Sequence, event.PrevHash = 1, ""
+		case err != nil:
+			return fmt.Errorf("failed to read the latest audit event: %w", err)
+		default:
+			event.Sequence, event.PrevHash = last.Sequence+1, last.Hash
+		}
+		event.Hash = event.ComputeHash()
+
+		_, err = r.collection.InsertOne(ctx, event)
+		if err == nil {
+			return nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("failed to append audit event: %w", err)
+		}
+	}
+	return fmt.Errorf("failed to append audit event: sequence still contended after %d attempts", auditAppendAttempts)
+}
+
+// Find returns a page of the events matching filter, newest first, and the number of matches
+func (r *AuditRepository) Find(ctx context.Context, filter models.AuditFilter, page, limit int) ([]*models.AuditEvent, int64, error) {
+	query := bson.M{}
+	for field, value := range map[string]string{
+		"action":     filter.Action,
+		"actorId":    filter.ActorID,
+		"targetType": filter.TargetType,
+		"targetId":   filter.TargetID,
+		"requestId":  filter.RequestID,
+	} {
+		if value != "" {
+			query[field] = value
+		}
+	}
+	if filter.From != nil || filter.To != nil {
+		createdAt := bson.M{}
+		if filter.From != nil {
+			createdAt["$gte"] = *filter.From
+		}
+		if filter.To != nil {
+			createdAt["$lt"] = *filter.To
+		}
+		query["createdAt"] = createdAt
+	}
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit events: %w", err)
+	}
+	opts := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.M{"_id": -1})
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query audit events: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var events []*models.AuditEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode audit events: %w", err)
+	}
+	if events == nil {
+		events = []*models.AuditEvent{}
+	}
+	return events, total, nil
+}
+
+// Iterate streams every event in sequence order to fn. Iteration stops at the first error
+// returned by fn.
+func (r *AuditRepository) Iterate(ctx context.Context, fn func(*models.AuditEvent) error) error {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return fmt.Errorf("failed to query audit events: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var event models.AuditEvent
+		if err := cursor.Decode(&event); err != nil {
+			return fmt.Errorf("failed to decode audit event: %w", err)
+		}
+		if err := fn(&event); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
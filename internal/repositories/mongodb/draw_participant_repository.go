@@ -0,0 +1,119 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// participantInsertBatchSize bounds the size of each InsertMany call when writing a snapshot
+const participantInsertBatchSize = 10000
+
+// DrawParticipantRepository implements the repositories.DrawParticipantRepository interface
+type DrawParticipantRepository struct {
+	collection *mongo.Collection
+}
+
+// NewDrawParticipantRepository creates a new DrawParticipantRepository
+func NewDrawParticipantRepository(db *mongo.Database) repositories.DrawParticipantRepository {
+	return &DrawParticipantRepository{
+		collection: db.Collection("draw_participants"),
+	}
+}
+
+// CreateMany inserts a participant snapshot in batches
+func (r *DrawParticipantRepository) CreateMany(ctx context.Context, participants []*models.DrawParticipant) error {
+	now := time.Now()
+	for start := 0; start < len(participants); start += participantInsertBatchSize {
+		end := start + participantInsertBatchSize
+		if end > len(participants) {
+			end = len(participants)
+		}
+		docs := make([]interface{}, 0, end-start)
+		for _, p := range participants[start:end] {
+			p.CreatedAt = now
+			docs = append(docs, p)
+		}
+		if _, err := r.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false)); err != nil {
+			return fmt.Errorf("failed to insert draw participants: %w", err)
+		}
+	}
+	return nil
+}
+
+// FindByDrawID finds the participants of a draw with pagination, ordered by MSISDN
+func (r *DrawParticipantRepository) FindByDrawID(ctx context.Context, drawID primitive.ObjectID, page, limit int) ([]*models.DrawParticipant, error) {
+	opts := options.Find().
+		SetSkip(int64((page - 1) * limit)).
+		SetLimit(int64(limit)).
+		SetSort(bson.M{"msisdn": 1})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"drawId": drawID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error finding participants for draw %s: %w", drawID.Hex(), err)
+	}
+	defer cursor.Close(ctx)
+
+	var participants []*models.DrawParticipant
+	if err := cursor.All(ctx, &participants); err != nil {
+		return nil, fmt.Errorf("error decoding participants for draw %s: %w", drawID.Hex(), err)
+	}
+	if participants == nil {
+		participants = []*models.DrawParticipant{}
+	}
+	return participants, nil
+}
+
+// CountByDrawID counts the participants recorded for a draw
+func (r *DrawParticipantRepository) CountByDrawID(ctx context.Context, drawID primitive.ObjectID) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"drawId": drawID})
+}
+
+// IterateByDrawID streams every participant of a draw, ordered by MSISDN, to fn.
+// Iteration stops at the first error returned by fn.
+func (r *DrawParticipantRepository) IterateByDrawID(ctx context.Context, drawID primitive.ObjectID, fn func(*models.DrawParticipant) error) error {
+	opts := options.Find().SetSort(bson.M{"msisdn": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{"drawId": drawID}, opts)
+	if err != nil {
+		return fmt.Errorf("error finding participants for draw %s: %w", drawID.Hex(), err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var participant models.DrawParticipant
+		if err := cursor.Decode(&participant); err != nil {
+			return fmt.Errorf("error decoding participant for draw %s: %w", drawID.Hex(), err)
+		}
+		if err := fn(&participant); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// EnsureIndexes creates the index every snapshot query uses: participants of a draw, by MSISDN
+func (r *DrawParticipantRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "drawId", Value: 1}, {Key: "msisdn", Value: 1}},
+		Options: options.Index().SetName("drawId_msisdn"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create draw participant indexes: %w", err)
+	}
+	return nil
+}
+
+// DeleteByDrawID discards the snapshot of a draw whose execution was interrupted
+func (r *DrawParticipantRepository) DeleteByDrawID(ctx context.Context, drawID primitive.ObjectID) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"drawId": drawID}); err != nil {
+		return fmt.Errorf("failed to delete participants for draw %s: %w", drawID.Hex(), err)
+	}
+	return nil
+}
@@ -48,7 +48,8 @@ func (r *DrawRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*
 	 return &draw, nil
 }
 
-// FindByDate finds a draw by date (matching the start of the day)
+// FindByDate finds the draw of a date (matching the start of the day). A voided or failed draw
+// that has been re-run is superseded by its re-run and is not returned.
 func (r *DrawRepository) FindByDate(ctx context.Context, date time.Time) (*models.Draw, error) {
 	var draw models.Draw
 	 // Match draws where the DrawDate is on the specified day
@@ -59,8 +60,11 @@ func (r *DrawRepository) FindByDate(ctx context.Context, date time.Time) (*model
 			 "$gte": startOfDay,
 			 "$lt":  endOfDay,
 		 },
+		 "reRunDrawId": bson.M{"$exists": false},
 	 }
-	 err := r.collection.FindOne(ctx, filter).Decode(&draw)
+	 // Until the original is linked to its re-run both match; the latest draw wins
+	 opts := options.FindOne().SetSort(bson.M{"createdAt": -1})
+	 err := r.collection.FindOne(ctx, filter, opts).Decode(&draw)
 	 if err != nil {
 		 return nil, err // Returns mongo.ErrNoDocuments if not found
 	}
@@ -137,6 +141,26 @@ func (r *DrawRepository) FindNextScheduledDraw(ctx context.Context, afterDate ti
 	 return &draw, nil
 }
 
+// FindNextScheduledDrawByType finds the earliest scheduled draw of a type after the given date
+func (r *DrawRepository) FindNextScheduledDrawByType(ctx context.Context, drawType string, afterDate time.Time) (*models.Draw, error) {
+	 filter := bson.M{
+		 "status":   models.DrawStatusScheduled,
+		 "drawType": drawType,
+		 "drawDate": bson.M{"$gt": afterDate},
+	 }
+	 opts := options.FindOne().SetSort(bson.M{"drawDate": 1})
+
+	 var draw models.Draw
+	 err := r.collection.FindOne(ctx, filter, opts).Decode(&draw)
+	 if err != nil {
+		 if errors.Is(err, mongo.ErrNoDocuments) {
+			 return nil, fmt.Errorf("no scheduled %s draw found after %s: %w", drawType, afterDate.Format("2006-01-02"), err)
+		 }
+		 return nil, fmt.Errorf("failed to find next scheduled %s draw: %w", drawType, err)
+	 }
+	 return &draw, nil
+}
+
 // Update updates a draw
 func (r *DrawRepository) Update(ctx context.Context, draw *models.Draw) error {
 	 draw.UpdatedAt = time.Now()
@@ -236,3 +260,46 @@ func (r *DrawRepository) FindByDateRangeAndStatus(ctx context.Context, startDate
 
 
 
+
+// ExtendExecutionLease pushes back the execution lease of a draw that owner is still executing.
+// It only touches the lease fields so it never overwrites the executor's own updates.
+func (r *DrawRepository) ExtendExecutionLease(ctx context.Context, id primitive.ObjectID, owner string, expiresAt time.Time) (bool, error) {
+	filter := bson.M{
+		"_id":                 id,
+		"status":              models.DrawStatusExecuting,
+		"executionLeaseOwner": owner,
+	}
+	update := bson.M{"$set": bson.M{"executionLeaseExpiresAt": expiresAt}}
+	res, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to extend execution lease of draw %s: %w", id.Hex(), err)
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// ReplaceIfLeaseHeld replaces a draw only while it is still EXECUTING under owner's lease, so an
+// executor whose draw was recovered or claimed by another process cannot overwrite it
+func (r *DrawRepository) ReplaceIfLeaseHeld(ctx context.Context, draw *models.Draw, owner string) (bool, error) {
+	draw.UpdatedAt = time.Now()
+	filter := bson.M{
+		"_id":                 draw.ID,
+		"status":              models.DrawStatusExecuting,
+		"executionLeaseOwner": owner,
+	}
+	res, err := r.collection.ReplaceOne(ctx, filter, draw)
+	if err != nil {
+		return false, fmt.Errorf("failed to update draw %s: %w", draw.ID.Hex(), err)
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// ReplaceIfStatus replaces a draw only if its stored status still equals expected, so that two
+// callers racing on the same transition cannot both succeed
+func (r *DrawRepository) ReplaceIfStatus(ctx context.Context, draw *models.Draw, expected models.DrawStatus) (bool, error) {
+	draw.UpdatedAt = time.Now()
+	res, err := r.collection.ReplaceOne(ctx, bson.M{"_id": draw.ID, "status": expected}, draw)
+	if err != nil {
+		return false, fmt.Errorf("failed to update draw %s: %w", draw.ID.Hex(), err)
+	}
+	return res.MatchedCount > 0, nil
+}
@@ -0,0 +1,92 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DrawTypeRepository implements the repositories.DrawTypeRepository interface
+type DrawTypeRepository struct {
+	collection *mongo.Collection
+}
+
+// NewDrawTypeRepository creates a new DrawTypeRepository
+func NewDrawTypeRepository(db *mongo.Database) repositories.DrawTypeRepository {
+	return &DrawTypeRepository{
+		collection: db.Collection("draw_types"),
+	}
+}
+
+// Create inserts a new draw type. Codes are unique; callers check for an existing code first.
+func (r *DrawTypeRepository) Create(ctx context.Context, drawType *models.DrawTypeDefinition) error {
+	now := time.Now()
+	drawType.CreatedAt = now
+	drawType.UpdatedAt = now
+	result, err := r.collection.InsertOne(ctx, drawType)
+	if err != nil {
+		return fmt.Errorf("failed to create draw type %s: %w", drawType.Code, err)
+	}
+	drawType.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindByCode finds a draw type by its code. It returns mongo.ErrNoDocuments (wrapped) when
+// the code is not registered.
+func (r *DrawTypeRepository) FindByCode(ctx context.Context, code string) (*models.DrawTypeDefinition, error) {
+	var drawType models.DrawTypeDefinition
+	if err := r.collection.FindOne(ctx, bson.M{"code": code}).Decode(&drawType); err != nil {
+		return nil, fmt.Errorf("failed to find draw type %s: %w", code, err)
+	}
+	return &drawType, nil
+}
+
+// FindAll returns every registered draw type ordered by code
+func (r *DrawTypeRepository) FindAll(ctx context.Context) ([]*models.DrawTypeDefinition, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"code": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query draw types: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var drawTypes []*models.DrawTypeDefinition
+	if err := cursor.All(ctx, &drawTypes); err != nil {
+		return nil, fmt.Errorf("failed to decode draw types: %w", err)
+	}
+	if drawTypes == nil {
+		drawTypes = []*models.DrawTypeDefinition{}
+	}
+	return drawTypes, nil
+}
+
+// Update replaces a draw type
+func (r *DrawTypeRepository) Update(ctx context.Context, drawType *models.DrawTypeDefinition) error {
+	drawType.UpdatedAt = time.Now()
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": drawType.ID}, drawType)
+	if err != nil {
+		return fmt.Errorf("failed to update draw type %s: %w", drawType.Code, err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("failed to update draw type %s: %w", drawType.Code, mongo.ErrNoDocuments)
+	}
+	return nil
+}
+
+// Delete removes a draw type by code
+func (r *DrawTypeRepository) Delete(ctx context.Context, code string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"code": code})
+	if err != nil {
+		return fmt.Errorf("failed to delete draw type %s: %w", code, err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("failed to delete draw type %s: %w", code, mongo.ErrNoDocuments)
+	}
+	return nil
+}
@@ -45,10 +45,13 @@ func (r *JackpotRolloverRepository) FindByID(ctx context.Context, id primitive.O
 	 return &rollover, nil
 }
 
-// FindBySourceDrawID finds a jackpot rollover record by the source draw ID.
+// notReversed matches rollover records that have not been reversed by voiding their source draw
+var notReversed = bson.M{"$exists": false}
+
+// FindBySourceDrawID finds the active (non-reversed) jackpot rollover record created by a source draw.
 func (r *JackpotRolloverRepository) FindBySourceDrawID(ctx context.Context, sourceDrawID primitive.ObjectID) (*models.JackpotRollover, error) {
 	var rollover models.JackpotRollover
-	 err := r.collection.FindOne(ctx, bson.M{"sourceDrawId": sourceDrawID}).Decode(&rollover)
+	 err := r.collection.FindOne(ctx, bson.M{"sourceDrawId": sourceDrawID, "reversedAt": notReversed}).Decode(&rollover)
 	 if err != nil {
 		 // Return error, including mongo.ErrNoDocuments if not found
 		 return nil, err
@@ -56,6 +59,15 @@ func (r *JackpotRolloverRepository) FindBySourceDrawID(ctx context.Context, sour
 	 return &rollover, nil
 }
 
+// Update updates a jackpot rollover record.
+func (r *JackpotRolloverRepository) Update(ctx context.Context, rollover *models.JackpotRollover) error {
+	 _, err := r.collection.ReplaceOne(ctx, bson.M{"_id": rollover.ID}, rollover)
+	 if err != nil {
+		 return fmt.Errorf("failed to update jackpot rollover record: %w", err)
+	 }
+	 return nil
+}
+
 // FindRolloversByDestinationDate finds all rollover records targeting a specific destination date.
 func (r *JackpotRolloverRepository) FindRolloversByDestinationDate(ctx context.Context, destinationDate time.Time) ([]*models.JackpotRollover, error) {
 	 // Match the specific date, ignoring time component if necessary, or use a range
@@ -67,6 +79,7 @@ func (r *JackpotRolloverRepository) FindRolloversByDestinationDate(ctx context.C
 			 "$gte": startOfDay,
 			 "$lt":  endOfDay,
 		 },
+		 "reversedAt": notReversed,
 	 }
 	 opts := options.Find().SetSort(bson.M{"createdAt": 1}) // Sort by creation time ascending
 
@@ -96,6 +109,7 @@ func (r *JackpotRolloverRepository) FindPendingRollovers(ctx context.Context, ef
 		"destinationDrawDate": bson.M{
 			"$gt": effectiveDate,
 		},
+		"reversedAt": notReversed,
 	}
 	// Optionally, could also include rollovers created after effectiveDate, regardless of destination?
 	// filter := bson.M{
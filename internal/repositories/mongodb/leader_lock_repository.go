@@ -0,0 +1,62 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LeaderLockRepository implements the repositories.LeaderLockRepository interface
+type LeaderLockRepository struct {
+	collection *mongo.Collection
+}
+
+// NewLeaderLockRepository creates a new LeaderLockRepository
+func NewLeaderLockRepository(db *mongo.Database) repositories.LeaderLockRepository {
+	return &LeaderLockRepository{
+		collection: db.Collection("leader_locks"),
+	}
+}
+
+// TryAcquire takes the named lock for owner, or extends it if owner already holds it.
+// The upsert only matches when the lock is free, expired or already ours; otherwise the
+// insert collides on _id and the lock is reported as held by someone else.
+func (r *LeaderLockRepository) TryAcquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	filter := bson.M{
+		"_id": name,
+		"$or": []bson.M{
+			{"owner": owner},
+			{"expiresAt": bson.M{"$lt": now}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"owner":     owner,
+			"expiresAt": now.Add(ttl),
+			"updatedAt": now,
+		},
+	}
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to acquire leader lock %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// Release gives up the named lock if owner holds it
+func (r *LeaderLockRepository) Release(ctx context.Context, name, owner string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": name, "owner": owner})
+	if err != nil {
+		return fmt.Errorf("failed to release leader lock %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LoginAttemptRepository implements the repositories.LoginAttemptRepository interface
+type LoginAttemptRepository struct {
+	collection *mongo.Collection
+}
+
+// NewLoginAttemptRepository creates a new LoginAttemptRepository
+func NewLoginAttemptRepository(db *mongo.Database) repositories.LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		collection: db.Collection("login_attempts"),
+	}
+}
+
+// Find returns the counter for an email or IP. It returns mongo.ErrNoDocuments (wrapped)
+// when there were no recent failures.
+func (r *LoginAttemptRepository) Find(ctx context.Context, kind, value string) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	if err := r.collection.FindOne(ctx, bson.M{"kind": kind, "value": value}).Decode(&attempt); err != nil {
+		return nil, fmt.Errorf("failed to find login attempts for %s %s: %w", kind, value, err)
+	}
+	return &attempt, nil
+}
+
+// RecordFailure counts a failed login in a single atomic update, so concurrent attempts are
+// all counted. The count restarts at 1 when the previous failure is older than resetBefore.
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, kind, value string, now, resetBefore time.Time) (*models.LoginAttempt, error) {
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"kind":  kind,
+			"value": value,
+			"failures": bson.M{"$cond": bson.A{
+				bson.M{"$lt": bson.A{"$lastFailureAt", resetBefore}}, // Also true when the field is missing
+				1,
+				bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$failures", 0}}, 1}},
+			}},
+			"lastFailureAt": now,
+		}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	filter := bson.M{"kind": kind, "value": value}
+	var attempt models.LoginAttempt
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&attempt)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent failure inserted the counter between our match and our insert
+		err = r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&attempt)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record login failure for %s %s: %w", kind, value, err)
+	}
+	return &attempt, nil
+}
+
+// Lock locks an email or IP until the given time unless it is already locked at now, and
+// reports whether it did, so each lockout is recorded once
+func (r *LoginAttemptRepository) Lock(ctx context.Context, kind, value string, now, until time.Time) (bool, error) {
+	filter := bson.M{"kind": kind, "value": value, "lockedUntil": bson.M{"$not": bson.M{"$gt": now}}} // Also matches a missing field
+	res, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"lockedUntil": until}})
+	if err != nil {
+		return false, fmt.Errorf("failed to lock %s %s: %w", kind, value, err)
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+// Clear removes the counter for an email or IP and reports whether there was one
+func (r *LoginAttemptRepository) Clear(ctx context.Context, kind, value string) (bool, error) {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"kind": kind, "value": value})
+	if err != nil {
+		return false, fmt.Errorf("failed to clear login attempts for %s %s: %w", kind, value, err)
+	}
+	return res.DeletedCount > 0, nil
+}
+
+// FindLocked returns the emails and IPs that are locked at now
+func (r *LoginAttemptRepository) FindLocked(ctx context.Context, now time.Time) ([]*models.LoginAttempt, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"lockedUntil": bson.M{"$gt": now}}, options.Find().SetSort(bson.M{"lockedUntil": -1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query login lockouts: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var attempts []*models.LoginAttempt
+	if err := cursor.All(ctx, &attempts); err != nil {
+		return nil, fmt.Errorf("failed to decode login lockouts: %w", err)
+	}
+	if attempts == nil {
+		attempts = []*models.LoginAttempt{}
+	}
+	return attempts, nil
+}
+
+// EnsureIndexes creates the unique index on kind and value, so a burst of concurrent failures
+// upserts a single counter
+func (r *LoginAttemptRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "kind", Value: 1}, {Key: "value", Value: 1}},
+		Options: options.Index().SetName("kind_value_unique").SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create login attempt indexes: %w", err)
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package mongodb
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
 	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
@@ -33,6 +35,59 @@ func (r *PointTransactionRepository) Create(ctx context.Context, transaction *mo
 	return err
 }
 
+// CreateIfAbsent inserts transaction unless one with its TransactionRef is stored, in which
+// case the stored one is returned untouched. It must not run inside a transaction: a
+// duplicate key error aborts the transaction, and the stored one is re-read after it.
+func (r *PointTransactionRepository) CreateIfAbsent(ctx context.Context, transaction *models.PointTransaction) (*models.PointTransaction, bool, error) {
+	if transaction.TransactionRef == "" {
+		return nil, false, errors.New("point transaction has no transaction reference")
+	}
+	transaction.ID = primitive.NewObjectID()
+	filter := bson.M{"transactionRef": transaction.TransactionRef}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var stored models.PointTransaction
+	err := r.collection.FindOneAndUpdate(ctx, filter, bson.M{"$setOnInsert": transaction}, opts).Decode(&stored)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent call inserted it between our match and our insert
+		err = r.collection.FindOne(ctx, filter).Decode(&stored)
+		if err == nil {
+			return &stored, false, nil
+		}
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to record point transaction %s: %w", transaction.TransactionRef, err)
+	}
+	return &stored, stored.ID == transaction.ID, nil
+}
+
+// MarkApplied flags a pending transaction as credited to the user. The update matches only
+// while the flag is false, so of two callers applying the same transaction one gets true.
+func (r *PointTransactionRepository) MarkApplied(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "applied": false},
+		bson.M{"$set": bson.M{"applied": true}})
+	if err != nil {
+		return false, fmt.Errorf("failed to mark point transaction %s applied: %w", id.Hex(), err)
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// EnsureIndexes creates the unique index on transactionRef. Transactions recorded before
+// references were kept have none and are left out of the index.
+func (r *PointTransactionRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "transactionRef", Value: 1}},
+		Options: options.Index().
+			SetName("transactionRef_unique").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"transactionRef": bson.M{"$gt": ""}}), // Non-empty strings only
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create point transaction indexes: %w", err)
+	}
+	return nil
+}
+
 // FindByUserID finds all point transactions for a specific user
 // Corrected signature to match interface: returns ([]*models.PointTransaction, error)
 func (r *PointTransactionRepository) FindByUserID(ctx context.Context, userID primitive.ObjectID) ([]*models.PointTransaction, error) {
@@ -0,0 +1,108 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PrizeStructureRepository implements the repositories.PrizeStructureRepository interface
+type PrizeStructureRepository struct {
+	collection *mongo.Collection
+}
+
+// NewPrizeStructureRepository creates a new PrizeStructureRepository
+func NewPrizeStructureRepository(db *mongo.Database) repositories.PrizeStructureRepository {
+	return &PrizeStructureRepository{
+		collection: db.Collection("prize_structure_versions"),
+	}
+}
+
+// Create inserts a new prize structure version
+func (r *PrizeStructureRepository) Create(ctx context.Context, version *models.PrizeStructureVersion) error {
+	now := time.Now()
+	version.CreatedAt = now
+	version.UpdatedAt = now
+	result, err := r.collection.InsertOne(ctx, version)
+	if err != nil {
+		return fmt.Errorf("failed to create prize structure version %s v%d: %w", version.DrawType, version.Version, err)
+	}
+	version.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindByDrawType returns every version of a draw type's prize structure, newest first
+func (r *PrizeStructureRepository) FindByDrawType(ctx context.Context, drawType string) ([]*models.PrizeStructureVersion, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"drawType": drawType}, options.Find().SetSort(bson.M{"version": -1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prize structure versions of %s: %w", drawType, err)
+	}
+	defer cursor.Close(ctx)
+
+	var versions []*models.PrizeStructureVersion
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, fmt.Errorf("failed to decode prize structure versions of %s: %w", drawType, err)
+	}
+	if versions == nil {
+		versions = []*models.PrizeStructureVersion{}
+	}
+	return versions, nil
+}
+
+// FindByVersion finds one version of a draw type's prize structure. It returns
+// mongo.ErrNoDocuments (wrapped) when the version does not exist.
+func (r *PrizeStructureRepository) FindByVersion(ctx context.Context, drawType string, version int) (*models.PrizeStructureVersion, error) {
+	var result models.PrizeStructureVersion
+	if err := r.collection.FindOne(ctx, bson.M{"drawType": drawType, "version": version}).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to find prize structure %s v%d: %w", drawType, version, err)
+	}
+	return &result, nil
+}
+
+// FindEffective finds the approved version in force on a date: the one with the latest
+// effective date not after it, the highest version number winning a tie. It returns
+// mongo.ErrNoDocuments (wrapped) when no approved version applies yet.
+func (r *PrizeStructureRepository) FindEffective(ctx context.Context, drawType string, date time.Time) (*models.PrizeStructureVersion, error) {
+	filter := bson.M{
+		"drawType":      drawType,
+		"status":        models.PrizeStructureApproved,
+		"effectiveFrom": bson.M{"$lte": date},
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "effectiveFrom", Value: -1}, {Key: "version", Value: -1}})
+	var result models.PrizeStructureVersion
+	if err := r.collection.FindOne(ctx, filter, opts).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to find prize structure of %s effective on %s: %w", drawType, date.Format("2006-01-02"), err)
+	}
+	return &result, nil
+}
+
+// ReplaceIfStatus replaces a prize structure version only if its stored status still equals
+// expected, so that an approval and a rejection racing on the same draft cannot both succeed
+func (r *PrizeStructureRepository) ReplaceIfStatus(ctx context.Context, version *models.PrizeStructureVersion, expected models.PrizeStructureStatus) (bool, error) {
+	version.UpdatedAt = time.Now()
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": version.ID, "status": expected}, version)
+	if err != nil {
+		return false, fmt.Errorf("failed to update prize structure %s v%d: %w", version.DrawType, version.Version, err)
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// EnsureIndexes creates the unique index on drawType and version, so two drafts created
+// concurrently cannot take the same version number
+func (r *PrizeStructureRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "drawType", Value: 1}, {Key: "version", Value: 1}},
+		Options: options.Index().SetName("drawType_version_unique").SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create prize structure indexes: %w", err)
+	}
+	return nil
+}
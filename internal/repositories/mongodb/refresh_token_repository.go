@@ -0,0 +1,104 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RefreshTokenRepository implements the repositories.RefreshTokenRepository interface
+type RefreshTokenRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRefreshTokenRepository creates a new RefreshTokenRepository
+func NewRefreshTokenRepository(db *mongo.Database) repositories.RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		collection: db.Collection("refresh_tokens"),
+	}
+}
+
+// Create inserts a new refresh token
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	token.CreatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, token)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	token.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindByTokenHash finds a refresh token by the hash of the token
+func (r *RefreshTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.collection.FindOne(ctx, bson.M{"tokenHash": tokenHash}).Decode(&token); err != nil {
+		return nil, fmt.Errorf("failed to find refresh token: %w", err)
+	}
+	return &token, nil
+}
+
+// MarkUsed marks a token as used. It only matches an unused, unrevoked token, so of two
+// requests racing on the same token only one rotates it.
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, id primitive.ObjectID, now time.Time) (bool, error) {
+	res, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "usedAt": nil, "revokedAt": nil},
+		bson.M{"$set": bson.M{"usedAt": now}},
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark refresh token %s used: %w", id.Hex(), err)
+	}
+	return res.MatchedCount > 0, nil
+}
+
+// findLive returns the tokens matching filter whose access token has not expired
+func (r *RefreshTokenRepository) findLive(ctx context.Context, filter bson.M, now time.Time) ([]*models.RefreshToken, error) {
+	filter["accessExpiresAt"] = bson.M{"$gt": now}
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query refresh tokens: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var tokens []*models.RefreshToken
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to decode refresh tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// FindLiveBySession returns the tokens of a session whose access token has not expired
+func (r *RefreshTokenRepository) FindLiveBySession(ctx context.Context, sessionID primitive.ObjectID, now time.Time) ([]*models.RefreshToken, error) {
+	return r.findLive(ctx, bson.M{"sessionId": sessionID}, now)
+}
+
+// FindLiveByAdmin returns the tokens of an admin whose access token has not expired
+func (r *RefreshTokenRepository) FindLiveByAdmin(ctx context.Context, adminUserID primitive.ObjectID, now time.Time) ([]*models.RefreshToken, error) {
+	return r.findLive(ctx, bson.M{"adminUserId": adminUserID}, now)
+}
+
+// revoke marks the unrevoked tokens matching filter as revoked
+func (r *RefreshTokenRepository) revoke(ctx context.Context, filter bson.M, reason string, now time.Time) (int64, error) {
+	filter["revokedAt"] = nil
+	res, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"revokedAt": now, "revokedReason": reason}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return res.ModifiedCount, nil
+}
+
+// RevokeSession revokes every token of a session
+func (r *RefreshTokenRepository) RevokeSession(ctx context.Context, sessionID primitive.ObjectID, reason string, now time.Time) (int64, error) {
+	return r.revoke(ctx, bson.M{"sessionId": sessionID}, reason, now)
+}
+
+// RevokeByAdmin revokes every token of an admin user
+func (r *RefreshTokenRepository) RevokeByAdmin(ctx context.Context, adminUserID primitive.ObjectID, reason string, now time.Time) (int64, error) {
+	return r.revoke(ctx, bson.M{"adminUserId": adminUserID}, reason, now)
+}
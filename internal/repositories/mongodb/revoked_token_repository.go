@@ -0,0 +1,67 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RevokedTokenRepository implements the repositories.RevokedTokenRepository interface
+type RevokedTokenRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRevokedTokenRepository creates a new RevokedTokenRepository
+func NewRevokedTokenRepository(db *mongo.Database) repositories.RevokedTokenRepository {
+	return &RevokedTokenRepository{
+		collection: db.Collection("revoked_tokens"),
+	}
+}
+
+// Add denylists a jti. Adding a jti twice keeps a single entry.
+func (r *RevokedTokenRepository) Add(ctx context.Context, token *models.RevokedToken) error {
+	token.CreatedAt = time.Now()
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"jti": token.JTI},
+		bson.M{"$setOnInsert": bson.M{
+			"jti":         token.JTI,
+			"adminUserId": token.AdminUserID,
+			"reason":      token.Reason,
+			"expiresAt":   token.ExpiresAt,
+			"createdAt":   token.CreatedAt,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token %s: %w", token.JTI, err)
+	}
+	return nil
+}
+
+// IsRevoked reports whether a jti is denylisted
+func (r *RevokedTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	err := r.collection.FindOne(ctx, bson.M{"jti": jti}).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check token %s: %w", jti, err)
+	}
+	return true, nil
+}
+
+// DeleteExpired removes entries for tokens that have expired and are refused anyway
+func (r *RevokedTokenRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	res, err := r.collection.DeleteMany(ctx, bson.M{"expiresAt": bson.M{"$lte": now}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired revoked tokens: %w", err)
+	}
+	return res.DeletedCount, nil
+}
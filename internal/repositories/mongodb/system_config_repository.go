@@ -108,6 +108,27 @@ func (r *SystemConfigRepository) UpsertByKey(ctx context.Context, key string, va
 	 return nil
 }
 
+// CompareAndSwapByKey sets the value of key only if the stored value still equals expected,
+// and reports whether it did. A nil expected creates the key only if it does not exist yet.
+func (r *SystemConfigRepository) CompareAndSwapByKey(ctx context.Context, key string, expected, value interface{}) (bool, error) {
+	 now := time.Now()
+	 if expected == nil {
+		 update := bson.M{"$setOnInsert": bson.M{"key": key, "value": value, "createdAt": now, "updatedAt": now}}
+		 res, err := r.collection.UpdateOne(ctx, bson.M{"key": key}, update, options.Update().SetUpsert(true))
+		 if err != nil {
+			 return false, fmt.Errorf("failed to create system config for key %s: %w", key, err)
+		 }
+		 return res.UpsertedCount > 0, nil
+	 }
+	 res, err := r.collection.UpdateOne(ctx,
+		 bson.M{"key": key, "value": expected},
+		 bson.M{"$set": bson.M{"value": value, "updatedAt": now}})
+	 if err != nil {
+		 return false, fmt.Errorf("failed to update system config for key %s: %w", key, err)
+	 }
+	 return res.MatchedCount > 0, nil
+}
+
 // Delete deletes a system configuration by ID
 func (r *SystemConfigRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
 	 _, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
@@ -2,6 +2,7 @@ package mongodb
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
@@ -106,6 +107,62 @@ func (r *TopupRepository) Count(ctx context.Context) (int64, error) {
 }
 
 
+// UpsertByTransactionRef inserts topup unless a topup with its TransactionRef is stored, in
+// which case the stored one is returned untouched
+func (r *TopupRepository) UpsertByTransactionRef(ctx context.Context, topup *models.Topup) (*models.Topup, bool, error) {
+	if topup.ID.IsZero() {
+		topup.ID = primitive.NewObjectID()
+	}
+	topup.CreatedAt = time.Now()
+	topup.UpdatedAt = topup.CreatedAt
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var stored models.Topup
+	filter := bson.M{"transactionRef": topup.TransactionRef}
+	err := r.collection.FindOneAndUpdate(ctx, filter, bson.M{"$setOnInsert": topup}, opts).Decode(&stored)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent call inserted it between our match and our insert
+		err = r.collection.FindOne(ctx, filter).Decode(&stored)
+		if err == nil {
+			return &stored, false, nil
+		}
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return &stored, stored.ID == topup.ID, nil
+}
+
+// MarkProcessed records the points allocated for a topup
+func (r *TopupRepository) MarkProcessed(ctx context.Context, id primitive.ObjectID, pointsEarned int) error {
+	now := time.Now()
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"processed": true, "processedAt": now, "pointsEarned": pointsEarned, "updatedAt": now},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// EnsureIndexes creates the unique index on transactionRef that keeps UpsertByTransactionRef
+// from recording a topup twice when two callers race. Topups without a reference are left out.
+func (r *TopupRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "transactionRef", Value: 1}},
+		Options: options.Index().
+			SetName("transactionRef_unique").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"transactionRef": bson.M{"$gt": ""}}), // Non-empty strings only
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create topup indexes: %w", err)
+	}
+	return nil
+}
+
 // FindByMSISDNAndRef finds topups by MSISDN and Transaction Reference
 func (r *TopupRepository) FindByMSISDNAndRef(ctx context.Context, msisdn string, transactionRef string) ([]*models.Topup, error) {
 	filter := bson.M{
@@ -0,0 +1,100 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TopupWebhookEventRepository implements the repositories.TopupWebhookEventRepository interface
+type TopupWebhookEventRepository struct {
+	collection *mongo.Collection
+}
+
+// NewTopupWebhookEventRepository creates a new TopupWebhookEventRepository
+func NewTopupWebhookEventRepository(db *mongo.Database) repositories.TopupWebhookEventRepository {
+	return &TopupWebhookEventRepository{
+		collection: db.Collection("topup_webhook_events"),
+	}
+}
+
+// Enqueue queues a pending event. A second delivery of the same recharge collides on _id
+// (the TransactionRef) and is reported as already queued.
+func (r *TopupWebhookEventRepository) Enqueue(ctx context.Context, event *models.TopupWebhookEvent) (bool, error) {
+	now := time.Now()
+	event.Status = models.WebhookEventPending
+	event.ReceivedAt = now
+	event.NextAttemptAt = now
+	event.UpdatedAt = now
+	if _, err := r.collection.InsertOne(ctx, event); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to queue topup webhook event %s: %w", event.TransactionRef, err)
+	}
+	return true, nil
+}
+
+// ClaimDue takes the oldest event that is due for an attempt, or whose worker's lease has
+// expired, and leases it to the caller. The update is atomic, so concurrent workers never
+// claim the same event.
+func (r *TopupWebhookEventRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration) (*models.TopupWebhookEvent, error) {
+	filter := bson.M{
+		"$or": []bson.M{
+			{"status": models.WebhookEventPending, "nextAttemptAt": bson.M{"$lte": now}},
+			{"status": models.WebhookEventProcessing, "leaseExpiresAt": bson.M{"$lt": now}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":         models.WebhookEventProcessing,
+			"leaseExpiresAt": now.Add(lease),
+			"updatedAt":      now,
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.M{"nextAttemptAt": 1}).
+		SetReturnDocument(options.After)
+	var event models.TopupWebhookEvent
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// MarkProcessed records the topup an event was processed into
+func (r *TopupWebhookEventRepository) MarkProcessed(ctx context.Context, transactionRef string, topupID primitive.ObjectID, pointsEarned int) error {
+	now := time.Now()
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": transactionRef}, bson.M{
+		"$set":   bson.M{"status": models.WebhookEventProcessed, "topupId": topupID, "pointsEarned": pointsEarned, "processedAt": now, "updatedAt": now},
+		"$unset": bson.M{"leaseExpiresAt": "", "lastError": ""},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark topup webhook event %s processed: %w", transactionRef, err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed attempt and schedules the next one, or gives the event up
+func (r *TopupWebhookEventRepository) MarkFailed(ctx context.Context, transactionRef string, lastError string, nextAttemptAt time.Time, giveUp bool) error {
+	status := models.WebhookEventPending
+	if giveUp {
+		status = models.WebhookEventFailed
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": transactionRef}, bson.M{
+		"$set":   bson.M{"status": status, "lastError": lastError, "nextAttemptAt": nextAttemptAt, "updatedAt": time.Now()},
+		"$unset": bson.M{"leaseExpiresAt": ""},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record failed attempt of topup webhook event %s: %w", transactionRef, err)
+	}
+	return nil
+}
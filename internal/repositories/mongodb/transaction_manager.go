@@ -0,0 +1,63 @@
+package mongodb
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TransactionManager implements the repositories.TransactionManager interface
+type TransactionManager struct {
+	db        *mongo.Database
+	mu        sync.Mutex
+	checked   bool
+	supported bool
+}
+
+// NewTransactionManager creates a new TransactionManager
+func NewTransactionManager(db *mongo.Database) repositories.TransactionManager {
+	return &TransactionManager{db: db}
+}
+
+// TransactionsSupported reports whether the deployment is a replica set or sharded cluster.
+// The answer is cached after the first successful check; a failed check reports false.
+func (m *TransactionManager) TransactionsSupported(ctx context.Context) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.checked {
+		return m.supported
+	}
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := m.db.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false
+	}
+	m.checked = true
+	m.supported = hello.SetName != "" || hello.Msg == "isdbgrid"
+	return m.supported
+}
+
+// WithTransaction runs fn in a session transaction, retrying on transient errors as the
+// driver recommends. fn may therefore run more than once and must be safe to repeat.
+func (m *TransactionManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !m.TransactionsSupported(ctx) {
+		return fn(ctx)
+	}
+
+	session, err := m.db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+	return err
+}
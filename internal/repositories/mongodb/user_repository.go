@@ -12,7 +12,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	// "go.mongodb.org/mongo-driver/mongo/options" // Removed unused import
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // UserRepository implements the repositories.UserRepository interface
@@ -35,6 +35,22 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 	 return err
 }
 
+// EnsureIndexes creates the unique index on msisdn that keeps two callers creating the same
+// subscriber at once from both succeeding. Users without an MSISDN are left out.
+func (r *UserRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "msisdn", Value: 1}},
+		Options: options.Index().
+			SetName("msisdn_unique").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"msisdn": bson.M{"$gt": ""}}), // Non-empty strings only
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create user indexes: %w", err)
+	}
+	return nil
+}
+
 // FindByEmail finds a user by email
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
@@ -65,10 +81,20 @@ func (r *UserRepository) FindByMSISDN(ctx context.Context, msisdn string) (*mode
 	 return &user, nil
 }
 
-// Update updates a user
+// Update updates a user. Fields the model does not carry, such as the appliedRefs kept by
+// CreditTopupPoints, are left alone.
 func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 	 user.UpdatedAt = time.Now()
-	 _, err := r.collection.ReplaceOne(ctx, bson.M{"_id": user.ID}, user)
+	 fields, err := bson.Marshal(user)
+	 if err != nil {
+		 return fmt.Errorf("failed to encode user %s: %w", user.ID.Hex(), err)
+	 }
+	 var set bson.M
+	 if err := bson.Unmarshal(fields, &set); err != nil {
+		 return fmt.Errorf("failed to encode user %s: %w", user.ID.Hex(), err)
+	 }
+	 delete(set, "_id")
+	 _, err = r.collection.UpdateOne(ctx, bson.M{"_id": user.ID}, bson.M{"$set": set})
 	 return err
 }
 
@@ -141,7 +167,8 @@ func (r *UserRepository) FindUsersByRechargeWindow(ctx context.Context, startTim
 }
 
 // FindEligibleConsolationUsers finds users eligible for consolation prizes.
-// Combines digit matching, opt-in status/time, recharge window, and blacklist check.
+// Combines digit matching, opt-in status/time and recharge window; blacklisted users are
+// excluded by the draw service.
 func (r *UserRepository) FindEligibleConsolationUsers(ctx context.Context, digits []int, optInCutoff, rechargeStart, rechargeEnd time.Time) ([]*models.User, error) {
 	 // 1. Base filter: Opt-in status and time
 	 filter := bson.M{
@@ -162,9 +189,7 @@ func (r *UserRepository) FindEligibleConsolationUsers(ctx context.Context, digit
 	 // filter["lastRechargeDate"] = bson.M{"gte": rechargeStart, "lt": rechargeEnd}
 	 fmt.Println("WARN: FindEligibleConsolationUsers recharge window filter is a placeholder")
 
-	 // 4. Add blacklist filter (Assuming IsBlacklisted is handled elsewhere or needs integration)
-	 // filter["isBlacklisted"] = false // Or query blacklist collection separately
-	 fmt.Println("WARN: FindEligibleConsolationUsers blacklist filter is not implemented")
+	 // 4. Blacklisted users are excluded by the draw service, which records every exclusion
 
 	 cursor, err := r.collection.Find(ctx, filter)
 	 if err != nil {
@@ -180,7 +205,7 @@ func (r *UserRepository) FindEligibleConsolationUsers(ctx context.Context, digit
 		 users = []*models.User{}
 	 }
 
-	 // TODO: Post-query filtering might be needed if recharge/blacklist checks aren't in the main query
+	 // TODO: Post-query filtering might be needed if the recharge check isn't in the main query
 
 	 return users, nil
 }
@@ -205,6 +230,35 @@ func (r *UserRepository) IncrementPoints(ctx context.Context, userID primitive.O
 	 return nil
 }
 
+// CreditTopupPoints adds the points of a top-up to a user and records its transactionRef in
+// the user's appliedRefs, in one write that only matches while the reference is not recorded.
+// It reports whether the points were added; false means the top-up was credited before.
+func (r *UserRepository) CreditTopupPoints(ctx context.Context, userID primitive.ObjectID, transactionRef string, points int) (bool, error) {
+	 if points <= 0 {
+		 return false, errors.New("points to add must be positive")
+	 }
+	 filter := bson.M{"_id": userID, "appliedRefs": bson.M{"$ne": transactionRef}}
+	 update := bson.M{
+		 "$inc":      bson.M{"points": points},
+		 "$addToSet": bson.M{"appliedRefs": transactionRef},
+		 "$set":      bson.M{"updatedAt": time.Now()},
+	 }
+	 result, err := r.collection.UpdateOne(ctx, filter, update)
+	 if err != nil {
+		 return false, fmt.Errorf("failed to credit top-up %s to user %s: %w", transactionRef, userID.Hex(), err)
+	 }
+	 if result.MatchedCount > 0 {
+		 return true, nil
+	 }
+	 count, err := r.collection.CountDocuments(ctx, bson.M{"_id": userID})
+	 if err != nil {
+		 return false, fmt.Errorf("failed to credit top-up %s to user %s: %w", transactionRef, userID.Hex(), err)
+	 }
+	 if count == 0 {
+		 return false, fmt.Errorf("user %s not found for point increment", userID.Hex())
+	 }
+	 return false, nil
+}
 
 // Count returns the total number of users.
 func (r *UserRepository) Count(ctx context.Context) (int64, error) {
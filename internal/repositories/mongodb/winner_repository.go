@@ -145,6 +145,17 @@ func (r *WinnerRepository) Update(ctx context.Context, winner *models.Winner) er
 	 return err
 }
 
+// ReplaceIfClaimStatus replaces a winner only if its stored claim status still equals expected,
+// so a claim and a forfeit racing on the same winner cannot both succeed
+func (r *WinnerRepository) ReplaceIfClaimStatus(ctx context.Context, winner *models.Winner, expected models.ClaimStatus) (bool, error) {
+	winner.UpdatedAt = time.Now()
+	res, err := r.collection.ReplaceOne(ctx, bson.M{"_id": winner.ID, "claimStatus": expected}, winner)
+	if err != nil {
+		return false, fmt.Errorf("failed to update winner %s: %w", winner.ID.Hex(), err)
+	}
+	return res.MatchedCount > 0, nil
+}
+
 // Delete deletes a winner
 func (r *WinnerRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
 	 _, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
@@ -225,4 +236,26 @@ func (r *WinnerRepository) FindByUserID(ctx context.Context, userID primitive.Ob
 	 return winners, nil
 }
 
+// FindExpiredClaims finds pending winners of a prize category whose claim deadline is before the given time.
+func (r *WinnerRepository) FindExpiredClaims(ctx context.Context, category string, before time.Time) ([]*models.Winner, error) {
+	filter := bson.M{
+		"prizeCategory": category,
+		"claimStatus":   models.ClaimStatusPending,
+		"claimDeadline": bson.M{"$lt": before},
+	}
+	 opts := options.Find().SetSort(bson.M{"claimDeadline": 1})
+	 cursor, err := r.collection.Find(ctx, filter, opts)
+	 if err != nil {
+		 return nil, fmt.Errorf("error finding expired %s claims: %w", category, err)
+	}
+	 defer cursor.Close(ctx)
 
+	 var winners []*models.Winner
+	 if err := cursor.All(ctx, &winners); err != nil {
+		 return nil, fmt.Errorf("error decoding expired %s claims: %w", category, err)
+	}
+	 if winners == nil {
+		 winners = []*models.Winner{}
+	 }
+	 return winners, nil
+}
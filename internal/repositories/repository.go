@@ -22,16 +22,18 @@ type UserRepository interface {
 	FindUsersByRechargeWindow(ctx context.Context, startTime, endTime time.Time) ([]*models.User, error)
 	FindEligibleConsolationUsers(ctx context.Context, digits []int, optInCutoff, rechargeStart, rechargeEnd time.Time) ([]*models.User, error)
 	IncrementPoints(ctx context.Context, userID primitive.ObjectID, points int) error
+	CreditTopupPoints(ctx context.Context, userID primitive.ObjectID, transactionRef string, points int) (bool, error) // Adds points once per transactionRef; false if already credited
 	FindByOptInStatus(ctx context.Context, optInStatus bool) ([]*models.User, error) // Added missing method
 	FindByEligibleDigits(ctx context.Context, digits []int) ([]*models.User, error) // Added missing method
 	Count(ctx context.Context) (int64, error) // Added missing method
+	EnsureIndexes(ctx context.Context) error // Unique msisdn, so Create of a known MSISDN fails with a duplicate key error
 }
 
 // DrawRepository defines the interface for draw data operations
 type DrawRepository interface {
 	Create(ctx context.Context, draw *models.Draw) error
 	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Draw, error)
-	FindByDate(ctx context.Context, date time.Time) (*models.Draw, error)
+	FindByDate(ctx context.Context, date time.Time) (*models.Draw, error) // Skips draws superseded by a re-run
 	Update(ctx context.Context, draw *models.Draw) error
 	Delete(ctx context.Context, id primitive.ObjectID) error
 	FindAll(ctx context.Context) ([]*models.Draw, error)
@@ -39,8 +41,12 @@ type DrawRepository interface {
 	FindByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.Draw, error)
 	FindByStatus(ctx context.Context, status string) ([]*models.Draw, error)
 	FindNextScheduledDraw(ctx context.Context, currentDate time.Time) (*models.Draw, error)
+	FindNextScheduledDrawByType(ctx context.Context, drawType string, currentDate time.Time) (*models.Draw, error)
 	FindLatestDrawByTypeAndStatus(ctx context.Context, drawType string, statuses []string) (*models.Draw, error) // Added missing method used in GetJackpotStatus
 	FindByDateRangeAndStatus(ctx context.Context, startDate, endDate time.Time, statuses []string) ([]*models.Draw, error) // Added missing method used in GetJackpotHistory
+	ExtendExecutionLease(ctx context.Context, id primitive.ObjectID, owner string, expiresAt time.Time) (bool, error) // Heartbeat; false once the draw is no longer EXECUTING under owner
+	ReplaceIfStatus(ctx context.Context, draw *models.Draw, expected models.DrawStatus) (bool, error)               // Compare-and-set on the stored status; false if it no longer matches
+	ReplaceIfLeaseHeld(ctx context.Context, draw *models.Draw, owner string) (bool, error)                          // Replace only while the draw is EXECUTING under owner's lease
 }
 
 // WinnerRepository defines the interface for winner data operations
@@ -55,6 +61,97 @@ type WinnerRepository interface {
 	// New methods for redesign
 	CreateMany(ctx context.Context, winners []*models.Winner) error
 	FindByDrawIDAndCategory(ctx context.Context, drawID primitive.ObjectID, category string) ([]*models.Winner, error) // Added missing method used in GetJackpotStatus
+	FindExpiredClaims(ctx context.Context, category string, before time.Time) ([]*models.Winner, error) // Pending claims whose deadline has passed
+	ReplaceIfClaimStatus(ctx context.Context, winner *models.Winner, expected models.ClaimStatus) (bool, error) // Compare-and-set on the stored claim status; false if it no longer matches
+}
+
+// DrawParticipantRepository defines the interface for the per-draw participant snapshot.
+// Snapshots are append-only: the only removal is discarding the snapshot of an execution
+// that was interrupted before it completed.
+type DrawParticipantRepository interface {
+	CreateMany(ctx context.Context, participants []*models.DrawParticipant) error
+	FindByDrawID(ctx context.Context, drawID primitive.ObjectID, page, limit int) ([]*models.DrawParticipant, error)
+	CountByDrawID(ctx context.Context, drawID primitive.ObjectID) (int64, error)
+	IterateByDrawID(ctx context.Context, drawID primitive.ObjectID, fn func(*models.DrawParticipant) error) error // Streams the snapshot ordered by MSISDN
+	DeleteByDrawID(ctx context.Context, drawID primitive.ObjectID) error
+	EnsureIndexes(ctx context.Context) error
+}
+
+// DrawTypeRepository defines the interface for the draw type registry
+type DrawTypeRepository interface {
+	Create(ctx context.Context, drawType *models.DrawTypeDefinition) error
+	FindByCode(ctx context.Context, code string) (*models.DrawTypeDefinition, error)
+	FindAll(ctx context.Context) ([]*models.DrawTypeDefinition, error) // Ordered by code
+	Update(ctx context.Context, drawType *models.DrawTypeDefinition) error
+	Delete(ctx context.Context, code string) error
+}
+
+// PrizeStructureRepository defines the interface for prize structure versions
+type PrizeStructureRepository interface {
+	Create(ctx context.Context, version *models.PrizeStructureVersion) error
+	FindByDrawType(ctx context.Context, drawType string) ([]*models.PrizeStructureVersion, error) // Newest version first
+	FindByVersion(ctx context.Context, drawType string, version int) (*models.PrizeStructureVersion, error)
+	FindEffective(ctx context.Context, drawType string, date time.Time) (*models.PrizeStructureVersion, error) // Approved version in force on date
+	ReplaceIfStatus(ctx context.Context, version *models.PrizeStructureVersion, expected models.PrizeStructureStatus) (bool, error) // Compare-and-set on the stored status
+	EnsureIndexes(ctx context.Context) error // Unique drawType and version, so Create of a taken version fails with a duplicate key error
+}
+
+// ApprovalRepository defines the interface for maker-checker approval requests
+type ApprovalRepository interface {
+	Create(ctx context.Context, request *models.ApprovalRequest) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.ApprovalRequest, error)
+	FindByStatus(ctx context.Context, status models.ApprovalStatus) ([]*models.ApprovalRequest, error) // "" for all, newest first
+	FindPending(ctx context.Context, action models.ApprovalAction, target string, now time.Time) (*models.ApprovalRequest, error)
+	FindLatest(ctx context.Context, action models.ApprovalAction, target string) (*models.ApprovalRequest, error) // Newest request in any status
+	ReplaceIfStatus(ctx context.Context, request *models.ApprovalRequest, expected models.ApprovalStatus) (bool, error) // Compare-and-set on the stored status
+	Endorse(ctx context.Context, id primitive.ObjectID, endorsedBy string, at time.Time) (bool, error) // Records the first approval of a PENDING request; false if it was endorsed or reviewed already
+	ExpirePending(ctx context.Context, now time.Time) (int64, error)
+}
+
+// AdminInviteRepository defines the interface for admin invites
+type AdminInviteRepository interface {
+	Create(ctx context.Context, invite *models.AdminInvite) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.AdminInvite, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*models.AdminInvite, error)
+	FindAll(ctx context.Context) ([]*models.AdminInvite, error) // Newest first
+	FindOpenByEmail(ctx context.Context, email string, now time.Time) (*models.AdminInvite, error)
+	MarkAccepted(ctx context.Context, id, adminUserID primitive.ObjectID, now time.Time) (bool, error) // Only while the invite is open, so a token is used once
+	Revoke(ctx context.Context, id primitive.ObjectID, revokedBy string, now time.Time) (bool, error)
+}
+
+// RefreshTokenRepository defines the interface for server-side refresh tokens
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	FindByTokenHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	MarkUsed(ctx context.Context, id primitive.ObjectID, now time.Time) (bool, error) // Only while unused and unrevoked, so a token rotates once
+	FindLiveBySession(ctx context.Context, sessionID primitive.ObjectID, now time.Time) ([]*models.RefreshToken, error) // Tokens whose access token has not expired
+	FindLiveByAdmin(ctx context.Context, adminUserID primitive.ObjectID, now time.Time) ([]*models.RefreshToken, error)
+	RevokeSession(ctx context.Context, sessionID primitive.ObjectID, reason string, now time.Time) (int64, error)
+	RevokeByAdmin(ctx context.Context, adminUserID primitive.ObjectID, reason string, now time.Time) (int64, error)
+}
+
+// RevokedTokenRepository defines the interface for the access token (jti) denylist
+type RevokedTokenRepository interface {
+	Add(ctx context.Context, token *models.RevokedToken) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// LoginAttemptRepository defines the interface for failed login counters
+type LoginAttemptRepository interface {
+	Find(ctx context.Context, kind, value string) (*models.LoginAttempt, error)
+	RecordFailure(ctx context.Context, kind, value string, now, resetBefore time.Time) (*models.LoginAttempt, error) // Counting restarts when the last failure is older than resetBefore
+	Lock(ctx context.Context, kind, value string, now, until time.Time) (bool, error) // False if it is already locked at now
+	Clear(ctx context.Context, kind, value string) (bool, error)
+	FindLocked(ctx context.Context, now time.Time) ([]*models.LoginAttempt, error)
+	EnsureIndexes(ctx context.Context) error // Unique kind and value, so concurrent failures share one counter
+}
+
+// AuditRepository defines the interface for the append-only audit log
+type AuditRepository interface {
+	Append(ctx context.Context, event *models.AuditEvent) error // Sets Sequence, PrevHash, Hash and CreatedAt
+	Find(ctx context.Context, filter models.AuditFilter, page, limit int) ([]*models.AuditEvent, int64, error) // Newest first, with the total count
+	Iterate(ctx context.Context, fn func(*models.AuditEvent) error) error // Every event in sequence order
 }
 
 // BlacklistRepository defines the interface for blacklist operations
@@ -69,12 +166,16 @@ type BlacklistRepository interface {
 type SystemConfigRepository interface {
 	FindByKey(ctx context.Context, key string) (*models.SystemConfig, error)
 	UpsertByKey(ctx context.Context, key string, value interface{}) error
+	CompareAndSwapByKey(ctx context.Context, key string, expected, value interface{}) (bool, error) // Sets value only while the stored one equals expected (nil: only while the key is absent)
 	FindAll(ctx context.Context) ([]*models.SystemConfig, error)
 }
 
 // PointTransactionRepository defines the interface for point transaction operations
 type PointTransactionRepository interface {
 	Create(ctx context.Context, transaction *models.PointTransaction) error
+	CreateIfAbsent(ctx context.Context, transaction *models.PointTransaction) (*models.PointTransaction, bool, error) // Returns the stored transaction for its TransactionRef and whether it was inserted
+	MarkApplied(ctx context.Context, id primitive.ObjectID) (bool, error) // Sets Applied on a pending transaction; false if it was already applied
+	EnsureIndexes(ctx context.Context) error
 	FindByUserID(ctx context.Context, userID primitive.ObjectID) ([]*models.PointTransaction, error)
 }
 
@@ -83,6 +184,22 @@ type JackpotRolloverRepository interface {
 	Create(ctx context.Context, rollover *models.JackpotRollover) error
 	FindRolloversByDestinationDate(ctx context.Context, destinationDate time.Time) ([]*models.JackpotRollover, error)
 	FindPendingRollovers(ctx context.Context, effectiveDate time.Time) ([]*models.JackpotRollover, error) // Added missing method used in GetJackpotStatus
+	FindBySourceDrawID(ctx context.Context, sourceDrawID primitive.ObjectID) (*models.JackpotRollover, error) // Active (non-reversed) rollover created by a draw
+	Update(ctx context.Context, rollover *models.JackpotRollover) error
+}
+
+// TransactionManager runs a unit of work in a multi-document transaction. Repositories join the
+// transaction through the ctx passed to fn. On deployments without transaction support (a
+// standalone mongod) fn runs without one.
+type TransactionManager interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	TransactionsSupported(ctx context.Context) bool
+}
+
+// LeaderLockRepository defines the interface for Mongo-backed leader election between replicas
+type LeaderLockRepository interface {
+	TryAcquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) // Acquires or renews the lock; false if held by another owner
+	Release(ctx context.Context, name, owner string) error
 }
 
 // AdminUserRepository defines the interface for admin user data operations (Added)
@@ -90,9 +207,11 @@ type AdminUserRepository interface {
 	Create(ctx context.Context, adminUser *models.AdminUser) error
 	FindByEmail(ctx context.Context, email string) (*models.AdminUser, error)
 	FindByID(ctx context.Context, id primitive.ObjectID) (*models.AdminUser, error)
-	Update(ctx context.Context, adminUser *models.AdminUser) error
+	Update(ctx context.Context, adminUser *models.AdminUser) error // Never lowers the stored TOTPLastCounter
 	Delete(ctx context.Context, id primitive.ObjectID) error
 	FindAll(ctx context.Context) ([]*models.AdminUser, error)
+	ConsumeTOTPCounter(ctx context.Context, id primitive.ObjectID, counter int64) (bool, error) // False if a code of this or a later time step was already accepted
+	ConsumeRecoveryCode(ctx context.Context, id primitive.ObjectID, hash string) (bool, error) // False if the code is not (or no longer) stored
 }
 
 
@@ -103,6 +222,17 @@ type TopupRepository interface {
 	FindByMSISDN(ctx context.Context, msisdn string, page, limit int) ([]*models.Topup, error)
 	FindByDateRange(ctx context.Context, start, end time.Time, page, limit int) ([]*models.Topup, error)
 	Count(ctx context.Context) (int64, error)
+	UpsertByTransactionRef(ctx context.Context, topup *models.Topup) (*models.Topup, bool, error) // Returns the stored topup and whether it was inserted
+	MarkProcessed(ctx context.Context, id primitive.ObjectID, pointsEarned int) error
+	EnsureIndexes(ctx context.Context) error
+}
+
+// TopupWebhookEventRepository defines the interface for the queue of recharges pushed by MTN
+type TopupWebhookEventRepository interface {
+	Enqueue(ctx context.Context, event *models.TopupWebhookEvent) (bool, error) // False when the TransactionRef is already queued
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration) (*models.TopupWebhookEvent, error) // mongo.ErrNoDocuments when nothing is due
+	MarkProcessed(ctx context.Context, transactionRef string, topupID primitive.ObjectID, pointsEarned int) error
+	MarkFailed(ctx context.Context, transactionRef string, lastError string, nextAttemptAt time.Time, giveUp bool) error
 }
 
 // NotificationRepository defines the interface for notification data operations
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	defaultInviteTTL  = 72 * time.Hour
+	maxInviteTTL      = 30 * 24 * time.Hour
+	minPasswordLength = 8
+)
+
+var (
+	// ErrAdminExists is returned when inviting or creating an admin whose email is taken
+	ErrAdminExists = errors.New("admin user with this email already exists")
+	// ErrInviteAlreadyOpen is returned when the email already has an open invite
+	ErrInviteAlreadyOpen = errors.New("an open invite already exists for this email")
+	// ErrInviteNotFound is returned for an unknown invite ID or token
+	ErrInviteNotFound = errors.New("invite not found")
+	// ErrInviteNotOpen is returned for an invite that was used, revoked or has expired
+	ErrInviteNotOpen = errors.New("invite has been used, revoked or has expired")
+	// ErrAlreadyBootstrapped is returned by BootstrapSuperAdmin once an enabled super admin exists
+	ErrAlreadyBootstrapped = errors.New("a super admin already exists; invite new admins instead")
+	// ErrInvalidInviteExpiry is returned for an invite lifetime outside 1 hour to maxInviteTTL
+	ErrInvalidInviteExpiry = fmt.Errorf("expiresInHours must be between 1 and %d", int(maxInviteTTL.Hours()))
+	// ErrWeakPassword is returned for passwords shorter than minPasswordLength
+	ErrWeakPassword = fmt.Errorf("password must be at least %d characters", minPasswordLength)
+)
+
+// hashSecretToken returns the stored form of an invite or refresh token
+func hashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newSecretToken returns a random URL-safe token
+func newSecretToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ensureEmailFree checks that no admin user has the email
+func (s *authService) ensureEmailFree(ctx context.Context, email string) error {
+	_, err := s.adminUserRepo.FindByEmail(ctx, email)
+	switch {
+	case err == nil:
+		return ErrAdminExists
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return nil
+	default:
+		return fmt.Errorf("error checking for existing admin user: %w", err)
+	}
+}
+
+// createAdmin hashes the password and stores a new admin user
+func (s *authService) createAdmin(ctx context.Context, email, password, firstName, lastName, role string) (*models.AdminUser, error) {
+	if len(password) < minPasswordLength {
+		return nil, ErrWeakPassword
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	now := time.Now()
+	adminUser := &models.AdminUser{
+		FirstName: firstName,
+		LastName:  lastName,
+		Email:     email,
+		Password:  string(hashedPassword),
+		Role:      role,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.adminUserRepo.Create(ctx, adminUser); err != nil {
+		return nil, fmt.Errorf("failed to create admin user: %w", err)
+	}
+	adminUser.Password = ""
+	return adminUser, nil
+}
+
+// CreateInvite invites an admin with a role. The returned token is not stored and must be
+// passed to the invitee, who accepts it with AcceptInvite before the invite expires.
+func (s *authService) CreateInvite(ctx context.Context, req *models.CreateInviteRequest, actor string) (*models.AdminInvite, string, error) {
+	email := strings.TrimSpace(req.Email)
+	if !models.ValidRole(req.Role) {
+		return nil, "", fmt.Errorf("%w %q (expected one of %v)", ErrInvalidRole, req.Role, models.Roles)
+	}
+	ttl := defaultInviteTTL
+	if req.ExpiresInHours != 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+		if ttl <= 0 || ttl > maxInviteTTL {
+			return nil, "", ErrInvalidInviteExpiry
+		}
+	}
+	if err := s.ensureEmailFree(ctx, email); err != nil {
+		return nil, "", err
+	}
+	now := time.Now()
+	if _, err := s.inviteRepo.FindOpenByEmail(ctx, email, now); err == nil {
+		return nil, "", ErrInviteAlreadyOpen
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, "", err
+	}
+
+	token, err := newSecretToken()
+	if err != nil {
+		return nil, "", err
+	}
+	invite := &models.AdminInvite{
+		Email:     email,
+		Role:      req.Role,
+		TokenHash: hashSecretToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedBy: actor,
+	}
+	if err := s.inviteRepo.Create(ctx, invite); err != nil {
+		return nil, "", err
+	}
+	log.Printf("[INFO] CreateInvite: %s invited %s as %q until %s", actor, email, invite.Role, invite.ExpiresAt.Format(time.RFC3339))
+	return invite, token, nil
+}
+
+// ListInvites returns every invite, newest first
+func (s *authService) ListInvites(ctx context.Context) ([]*models.AdminInvite, error) {
+	return s.inviteRepo.FindAll(ctx)
+}
+
+// RevokeInvite withdraws an invite that has not been accepted yet
+func (s *authService) RevokeInvite(ctx context.Context, inviteID primitive.ObjectID, actor string) (*models.AdminInvite, error) {
+	revoked, err := s.inviteRepo.Revoke(ctx, inviteID, actor, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	invite, err := s.inviteRepo.FindByID(ctx, inviteID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrInviteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		return invite, ErrInviteNotOpen
+	}
+	log.Printf("[INFO] RevokeInvite: Invite %s for %s revoked by %s", inviteID.Hex(), invite.Email, actor)
+	return invite, nil
+}
+
+// AcceptInvite creates the invited admin with the invite's role and the chosen password.
+// The token can be used once.
+func (s *authService) AcceptInvite(ctx context.Context, req *models.AcceptInviteRequest) (*models.AdminUser, error) {
+	invite, err := s.inviteRepo.FindByTokenHash(ctx, hashSecretToken(req.Token))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrInviteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !invite.Open(time.Now()) {
+		return nil, ErrInviteNotOpen
+	}
+	if err := s.ensureEmailFree(ctx, invite.Email); err != nil {
+		return nil, err
+	}
+
+	adminUser, err := s.createAdmin(ctx, invite.Email, req.Password, req.FirstName, req.LastName, invite.Role)
+	if err != nil {
+		return nil, err
+	}
+	accepted, err := s.inviteRepo.MarkAccepted(ctx, invite.ID, adminUser.ID, time.Now())
+	if err == nil && !accepted {
+		err = ErrInviteNotOpen
+	}
+	if err != nil {
+		// Another request used the token first (or the invite could not be updated); undo
+		if delErr := s.adminUserRepo.Delete(ctx, adminUser.ID); delErr != nil {
+			log.Printf("[ERROR] AcceptInvite: Failed to remove admin user %s after failed acceptance: %v", adminUser.ID.Hex(), delErr)
+		}
+		return nil, err
+	}
+	log.Printf("[INFO] AcceptInvite: %s accepted invite %s as %q", adminUser.Email, invite.ID.Hex(), adminUser.Role)
+	return adminUser, nil
+}
+
+// BootstrapSuperAdmin creates the first super admin of a deployment. It refuses once an enabled
+// super admin exists; admins with the legacy role alone do not count, so a deployment that
+// only has those can still bootstrap one.
+func (s *authService) BootstrapSuperAdmin(ctx context.Context, email, password, firstName, lastName string) (*models.AdminUser, error) {
+	admins, err := s.adminUserRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error checking existing admin users: %w", err)
+	}
+	for _, admin := range admins {
+		if isSuperAdmin(admin.Role) && !admin.Disabled {
+			return nil, ErrAlreadyBootstrapped
+		}
+	}
+	adminUser, err := s.createAdmin(ctx, strings.TrimSpace(email), password, firstName, lastName, models.RoleSuperAdmin)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[INFO] BootstrapSuperAdmin: Created super admin %s", adminUser.Email)
+	return adminUser, nil
+}
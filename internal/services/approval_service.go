@@ -0,0 +1,383 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/exp/slog"
+)
+
+var (
+	// ErrApprovalNotFound is returned when an approval request does not exist
+	ErrApprovalNotFound = errors.New("approval request not found")
+	// ErrApprovalNotPending is returned when reviewing a request that is no longer PENDING
+	ErrApprovalNotPending = errors.New("approval request is not pending")
+	// ErrApprovalAlreadyPending is returned when the same action on the same target is already
+	// waiting for approval
+	ErrApprovalAlreadyPending = errors.New("an approval request for this action is already pending")
+	// ErrSelfApproval is returned when the requester tries to approve their own request
+	ErrSelfApproval = errors.New("approval requires a second admin")
+)
+
+// defaultApprovalTTL is used when no positive TTL is configured
+const defaultApprovalTTL = 24 * time.Hour
+
+// ApprovalServiceImpl implements maker-checker approval: sensitive DrawService calls are stored
+// as requests and only run once a second admin approves them
+type ApprovalServiceImpl struct {
+	approvalRepo repositories.ApprovalRepository
+	drawService  DrawService
+	ttl          time.Duration
+}
+
+// NewApprovalService creates a new ApprovalServiceImpl. Pending requests expire after ttl.
+func NewApprovalService(approvalRepo repositories.ApprovalRepository, drawService DrawService, ttl time.Duration) *ApprovalServiceImpl {
+	if ttl <= 0 {
+		ttl = defaultApprovalTTL
+	}
+	return &ApprovalServiceImpl{
+		approvalRepo: approvalRepo,
+		drawService:  drawService,
+		ttl:          ttl,
+	}
+}
+
+// RequestDrawExecution asks for approval to execute a scheduled draw
+func (s *ApprovalServiceImpl) RequestDrawExecution(ctx context.Context, drawID primitive.ObjectID, reason, requestedBy string) (*models.ApprovalRequest, error) {
+	draw, err := s.drawService.GetDrawByID(ctx, drawID)
+	if err != nil {
+		return nil, err
+	}
+	if draw.Status != models.DrawStatusScheduled {
+		return nil, fmt.Errorf("draw is not in SCHEDULED state (current: %s)", draw.Status)
+	}
+	return s.createRequest(ctx, &models.ApprovalRequest{
+		Action:   models.ApprovalActionExecuteDraw,
+		Target:   drawID.Hex(),
+		DrawID:   &drawID,
+		DrawType: draw.DrawType,
+		Reason:   reason,
+	}, requestedBy)
+}
+
+// drawSchedulerRequester is the requester recorded on execution requests filed by the draw
+// scheduler. No admin asked for those, so they need two: one endorses, a second approves.
+const drawSchedulerRequester = "scheduler"
+
+// RequestScheduledDrawExecution files the execution request of a due draw on behalf of the
+// draw scheduler and reports whether it filed one. Nothing is filed once any request exists
+// for the draw, so a rejected or expired request is not filed again on the next pass; an
+// admin can still request the execution by hand.
+func (s *ApprovalServiceImpl) RequestScheduledDrawExecution(ctx context.Context, drawID primitive.ObjectID) (*models.ApprovalRequest, bool, error) {
+	existing, err := s.approvalRepo.FindLatest(ctx, models.ApprovalActionExecuteDraw, drawID.Hex())
+	if err == nil {
+		return existing, false, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, false, err
+	}
+	request, err := s.RequestDrawExecution(ctx, drawID, "Scheduled execution time reached", drawSchedulerRequester)
+	if err != nil {
+		return request, false, err
+	}
+	return request, true, nil
+}
+
+// RequestDrawVoid asks for approval to void a completed or failed draw
+func (s *ApprovalServiceImpl) RequestDrawVoid(ctx context.Context, drawID primitive.ObjectID, reason, requestedBy string) (*models.ApprovalRequest, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, errors.New("a reason is required to void a draw")
+	}
+	draw, err := s.drawService.GetDrawByID(ctx, drawID)
+	if err != nil {
+		return nil, err
+	}
+	if draw.Status != models.DrawStatusCompleted && draw.Status != models.DrawStatusFailed {
+		return nil, fmt.Errorf("only COMPLETED or FAILED draws can be voided (current: %s)", draw.Status)
+	}
+	return s.createRequest(ctx, &models.ApprovalRequest{
+		Action:   models.ApprovalActionVoidDraw,
+		Target:   drawID.Hex(),
+		DrawID:   &drawID,
+		DrawType: draw.DrawType,
+		Reason:   reason,
+	}, requestedBy)
+}
+
+// RequestPrizeStructureApproval asks for approval of a DRAFT prize structure version
+func (s *ApprovalServiceImpl) RequestPrizeStructureApproval(ctx context.Context, drawType string, version int, reason, requestedBy string) (*models.ApprovalRequest, error) {
+	structure, err := s.drawService.GetPrizeStructureVersion(ctx, drawType, version)
+	if err != nil {
+		return nil, err
+	}
+	if structure.Status != models.PrizeStructureDraft {
+		return nil, fmt.Errorf("only DRAFT versions can be approved (current: %s)", structure.Status)
+	}
+	return s.createRequest(ctx, &models.ApprovalRequest{
+		Action:                models.ApprovalActionPrizeStructure,
+		Target:                fmt.Sprintf("%s v%d", structure.DrawType, structure.Version),
+		DrawType:              structure.DrawType,
+		PrizeStructureVersion: structure.Version,
+		Reason:                reason,
+	}, requestedBy)
+}
+
+// RequestBaseJackpotChange asks for approval to change the base jackpot of a draw type
+func (s *ApprovalServiceImpl) RequestBaseJackpotChange(ctx context.Context, drawType string, amount float64, reason, requestedBy string) (*models.ApprovalRequest, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("base jackpot must be positive (got %.2f)", amount)
+	}
+	drawTypeDef, err := s.drawService.GetDrawType(ctx, drawType)
+	if err != nil {
+		return nil, err
+	}
+	return s.createRequest(ctx, &models.ApprovalRequest{
+		Action:   models.ApprovalActionBaseJackpot,
+		Target:   drawTypeDef.Code,
+		DrawType: drawTypeDef.Code,
+		Amount:   amount,
+		Reason:   reason,
+	}, requestedBy)
+}
+
+// RequestDrawTypeKeysChange asks for approval to repoint the SystemConfig keys a draw type
+// reads its prize structure and base jackpot from ("" keeps the current key)
+func (s *ApprovalServiceImpl) RequestDrawTypeKeysChange(ctx context.Context, drawType, prizeStructureKey, baseJackpotKey, reason, requestedBy string) (*models.ApprovalRequest, error) {
+	drawTypeDef, err := s.drawService.GetDrawType(ctx, drawType)
+	if err != nil {
+		return nil, err
+	}
+	prizeStructureKey = strings.TrimSpace(prizeStructureKey)
+	baseJackpotKey = strings.TrimSpace(baseJackpotKey)
+	if prizeStructureKey == "" {
+		prizeStructureKey = drawTypeDef.PrizeStructureKey
+	}
+	if baseJackpotKey == "" {
+		baseJackpotKey = drawTypeDef.BaseJackpotKey
+	}
+	if prizeStructureKey == drawTypeDef.PrizeStructureKey && baseJackpotKey == drawTypeDef.BaseJackpotKey {
+		return nil, errors.New("the draw type already uses these keys")
+	}
+	return s.createRequest(ctx, &models.ApprovalRequest{
+		Action:            models.ApprovalActionDrawTypeKeys,
+		Target:            drawTypeDef.Code,
+		DrawType:          drawTypeDef.Code,
+		PrizeStructureKey: prizeStructureKey,
+		BaseJackpotKey:    baseJackpotKey,
+		Reason:            reason,
+	}, requestedBy)
+}
+
+// createRequest stores a PENDING request unless one is already pending for the same action
+// and target
+func (s *ApprovalServiceImpl) createRequest(ctx context.Context, request *models.ApprovalRequest, requestedBy string) (*models.ApprovalRequest, error) {
+	if requestedBy == "" {
+		return nil, errors.New("the requesting admin is unknown")
+	}
+	now := time.Now()
+	existing, err := s.approvalRepo.FindPending(ctx, request.Action, request.Target, now)
+	if err == nil {
+		return existing, ErrApprovalAlreadyPending
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		slog.Error("Failed to check for pending approval", "error", err, "action", request.Action, "target", request.Target)
+		return nil, err
+	}
+
+	request.Status = models.ApprovalStatusPending
+	request.RequestedBy = requestedBy
+	request.RequestedAt = now
+	request.ExpiresAt = now.Add(s.ttl)
+	if err := s.approvalRepo.Create(ctx, request); err != nil {
+		slog.Error("Failed to create approval request", "error", err, "action", request.Action, "target", request.Target)
+		return nil, err
+	}
+	slog.Info("Approval requested", "approvalId", request.ID, "action", request.Action, "target", request.Target, "requestedBy", requestedBy)
+	return request, nil
+}
+
+// ListApprovals returns approval requests with a status ("" for all), newest first. Requests
+// past their expiry are marked EXPIRED first.
+func (s *ApprovalServiceImpl) ListApprovals(ctx context.Context, status models.ApprovalStatus) ([]*models.ApprovalRequest, error) {
+	if _, err := s.ExpirePending(ctx, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.approvalRepo.FindByStatus(ctx, status)
+}
+
+// GetApproval returns one approval request
+func (s *ApprovalServiceImpl) GetApproval(ctx context.Context, id primitive.ObjectID) (*models.ApprovalRequest, error) {
+	request, err := s.approvalRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("%w: %s", ErrApprovalNotFound, id.Hex())
+		}
+		return nil, err
+	}
+	return request, nil
+}
+
+// ExpirePending marks PENDING requests past their expiry as EXPIRED
+func (s *ApprovalServiceImpl) ExpirePending(ctx context.Context, now time.Time) (int64, error) {
+	expired, err := s.approvalRepo.ExpirePending(ctx, now)
+	if err != nil {
+		slog.Error("Failed to expire approval requests", "error", err)
+		return 0, err
+	}
+	if expired > 0 {
+		slog.Info("Approval requests expired", "count", expired)
+	}
+	return expired, nil
+}
+
+// reviewable loads a request and checks that it can still be reviewed by reviewer
+func (s *ApprovalServiceImpl) reviewable(ctx context.Context, id primitive.ObjectID, reviewer string, now time.Time) (*models.ApprovalRequest, error) {
+	if reviewer == "" {
+		return nil, errors.New("the reviewing admin is unknown")
+	}
+	request, err := s.GetApproval(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != models.ApprovalStatusPending {
+		return request, fmt.Errorf("%w (current: %s)", ErrApprovalNotPending, request.Status)
+	}
+	if !now.Before(request.ExpiresAt) {
+		request.Status = models.ApprovalStatusExpired
+		if _, err := s.approvalRepo.ReplaceIfStatus(ctx, request, models.ApprovalStatusPending); err != nil {
+			return request, err
+		}
+		return request, fmt.Errorf("%w (expired at %s)", ErrApprovalNotPending, request.ExpiresAt.Format(time.RFC3339))
+	}
+	return request, nil
+}
+
+// Approve approves a PENDING request and runs its action. The approver must differ from the
+// requester (and, for a prize structure, from the admin who drafted the version). A request
+// filed by the scheduler is only endorsed by its first approval and stays PENDING; it runs
+// when a different admin approves it. The request ends APPROVED when the action succeeds and
+// FAILED, with the error, when it does not.
+func (s *ApprovalServiceImpl) Approve(ctx context.Context, id primitive.ObjectID, approvedBy, note string) (*models.ApprovalRequest, error) {
+	now := time.Now()
+	request, err := s.reviewable(ctx, id, approvedBy, now)
+	if err != nil {
+		return request, err
+	}
+	if approvedBy == request.RequestedBy {
+		return request, ErrSelfApproval
+	}
+	if request.RequestedBy == drawSchedulerRequester {
+		if request.EndorsedBy == "" {
+			endorsed, err := s.approvalRepo.Endorse(ctx, id, approvedBy, now)
+			if err != nil {
+				slog.Error("Failed to record endorsement", "error", err, "approvalId", id)
+				return nil, err
+			}
+			if !endorsed {
+				return request, fmt.Errorf("%w (reviewed concurrently)", ErrApprovalNotPending)
+			}
+			request.EndorsedBy = approvedBy
+			request.EndorsedAt = &now
+			slog.Info("Scheduled request endorsed", "approvalId", id, "action", request.Action, "target", request.Target, "endorsedBy", approvedBy)
+			return request, nil
+		}
+		if approvedBy == request.EndorsedBy {
+			return request, fmt.Errorf("%w: the approver endorsed this scheduled request", ErrSelfApproval)
+		}
+	}
+	if request.Action == models.ApprovalActionPrizeStructure {
+		structure, err := s.drawService.GetPrizeStructureVersion(ctx, request.DrawType, request.PrizeStructureVersion)
+		if err != nil {
+			return request, err
+		}
+		if structure.CreatedBy == approvedBy {
+			return request, fmt.Errorf("%w: the version was drafted by the approver", ErrSelfApproval)
+		}
+	}
+
+	request.Status = models.ApprovalStatusApproved
+	request.ReviewedBy = approvedBy
+	request.ReviewedAt = &now
+	request.ReviewNote = note
+	claimed, err := s.approvalRepo.ReplaceIfStatus(ctx, request, models.ApprovalStatusPending)
+	if err != nil {
+		slog.Error("Failed to record approval", "error", err, "approvalId", id)
+		return nil, err
+	}
+	if !claimed {
+		return request, fmt.Errorf("%w (reviewed concurrently)", ErrApprovalNotPending)
+	}
+	slog.Info("Approval granted", "approvalId", id, "action", request.Action, "target", request.Target, "approvedBy", approvedBy)
+
+	if actionErr := s.runAction(ctx, request); actionErr != nil {
+		slog.Error("Approved action failed", "error", actionErr, "approvalId", id, "action", request.Action)
+		request.Status = models.ApprovalStatusFailed
+		request.Error = actionErr.Error()
+		if _, err := s.approvalRepo.ReplaceIfStatus(ctx, request, models.ApprovalStatusApproved); err != nil {
+			slog.Error("Failed to record failed approval action", "error", err, "approvalId", id)
+		}
+		return request, actionErr
+	}
+	return request, nil
+}
+
+// runAction performs the DrawService call of an approved request
+func (s *ApprovalServiceImpl) runAction(ctx context.Context, request *models.ApprovalRequest) error {
+	switch request.Action {
+	case models.ApprovalActionExecuteDraw:
+		// The approval ID doubles as the idempotency key, so the draw runs at most once per approval
+		_, err := s.drawService.ExecuteDraw(ctx, *request.DrawID, "approval-"+request.ID.Hex())
+		return err
+	case models.ApprovalActionVoidDraw:
+		_, err := s.drawService.VoidDraw(ctx, *request.DrawID, request.Reason, request.RequestedBy)
+		return err
+	case models.ApprovalActionPrizeStructure:
+		_, err := s.drawService.ApprovePrizeStructureVersion(ctx, request.DrawType, request.PrizeStructureVersion, request.ReviewedBy)
+		return err
+	case models.ApprovalActionBaseJackpot:
+		return s.drawService.UpdateBaseJackpot(ctx, request.DrawType, request.Amount)
+	case models.ApprovalActionDrawTypeKeys:
+		_, err := s.drawService.UpdateDrawTypeKeys(ctx, request.DrawType, request.PrizeStructureKey, request.BaseJackpotKey)
+		return err
+	default:
+		return fmt.Errorf("unknown approval action %s", request.Action)
+	}
+}
+
+// Reject rejects a PENDING request; its action never runs. Rejecting a prize structure
+// approval also rejects the DRAFT version.
+func (s *ApprovalServiceImpl) Reject(ctx context.Context, id primitive.ObjectID, rejectedBy, note string) (*models.ApprovalRequest, error) {
+	now := time.Now()
+	request, err := s.reviewable(ctx, id, rejectedBy, now)
+	if err != nil {
+		return request, err
+	}
+
+	request.Status = models.ApprovalStatusRejected
+	request.ReviewedBy = rejectedBy
+	request.ReviewedAt = &now
+	request.ReviewNote = note
+	claimed, err := s.approvalRepo.ReplaceIfStatus(ctx, request, models.ApprovalStatusPending)
+	if err != nil {
+		slog.Error("Failed to record rejection", "error", err, "approvalId", id)
+		return nil, err
+	}
+	if !claimed {
+		return request, fmt.Errorf("%w (reviewed concurrently)", ErrApprovalNotPending)
+	}
+	slog.Info("Approval rejected", "approvalId", id, "action", request.Action, "target", request.Target, "rejectedBy", rejectedBy)
+
+	if request.Action == models.ApprovalActionPrizeStructure {
+		if _, err := s.drawService.RejectPrizeStructureVersion(ctx, request.DrawType, request.PrizeStructureVersion, rejectedBy, note); err != nil {
+			return request, err
+		}
+	}
+	return request, nil
+}
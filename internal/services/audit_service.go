@@ -0,0 +1,181 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync/atomic"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
+)
+
+// AuditService records and queries the append-only admin audit log
+type AuditService interface {
+	// Record appends an event, filling in the actor and request from ctx when it belongs to an
+	// API call. A failure is logged rather than returned, so it never undoes the audited action.
+	Record(ctx context.Context, event *models.AuditEvent)
+	// BeginRequest returns a context for an API call; events recorded with it carry req
+	BeginRequest(ctx context.Context, req models.AuditRequest) context.Context
+	// FinishRequest records a generic request event if nothing was recorded during the call
+	FinishRequest(ctx context.Context, status int)
+	ListEvents(ctx context.Context, filter models.AuditFilter, page, limit int) ([]*models.AuditEvent, int64, error)
+	VerifyChain(ctx context.Context) (*models.AuditChainVerification, error)
+}
+
+// auditRequestKey is the context key of the *auditRequestState of an API call
+type auditRequestKey struct{}
+
+// auditRequestState is the API call an audit event is recorded in
+type auditRequestState struct {
+	req      models.AuditRequest
+	recorded atomic.Bool // An event was recorded during the call
+}
+
+// auditServiceImpl implements AuditService
+type auditServiceImpl struct {
+	auditRepo repositories.AuditRepository
+}
+
+// NewAuditService creates a new AuditService
+func NewAuditService(auditRepo repositories.AuditRepository) AuditService {
+	return &auditServiceImpl{
+		auditRepo: auditRepo,
+	}
+}
+
+// BeginRequest attaches the API call to ctx
+func (s *auditServiceImpl) BeginRequest(ctx context.Context, req models.AuditRequest) context.Context {
+	return context.WithValue(ctx, auditRequestKey{}, &auditRequestState{req: req})
+}
+
+// Record appends an event on behalf of the caller in ctx
+func (s *auditServiceImpl) Record(ctx context.Context, event *models.AuditEvent) {
+	if state, ok := ctx.Value(auditRequestKey{}).(*auditRequestState); ok {
+		state.recorded.Store(true)
+		if event.ActorID == "" {
+			event.ActorID = state.req.ActorID
+		}
+		if event.ActorEmail == "" && event.ActorID == state.req.ActorID {
+			event.ActorEmail = state.req.ActorEmail
+		}
+		if event.RequestID == "" {
+			event.RequestID = state.req.RequestID
+		}
+		if event.IPAddress == "" {
+			event.IPAddress = state.req.IPAddress
+		}
+		if event.Method == "" {
+			event.Method, event.Route = state.req.Method, state.req.Route
+		}
+	}
+	if err := s.auditRepo.Append(ctx, event); err != nil {
+		log.Printf("[ERROR] Failed to record audit event %s for %s %s: %v", event.Action, event.TargetType, event.TargetID, err)
+	}
+}
+
+// FinishRequest records the call itself when no service recorded a more specific event, so
+// every mutating endpoint leaves a trace even without a service hook
+func (s *auditServiceImpl) FinishRequest(ctx context.Context, status int) {
+	state, ok := ctx.Value(auditRequestKey{}).(*auditRequestState)
+	if !ok || state.recorded.Load() {
+		return
+	}
+	s.Record(ctx, &models.AuditEvent{
+		Action:     models.AuditActionRequest,
+		TargetType: models.AuditTargetRoute,
+		TargetID:   state.req.TargetID,
+		Status:     status,
+	})
+}
+
+// ListEvents returns a page of the events matching filter, newest first
+func (s *auditServiceImpl) ListEvents(ctx context.Context, filter models.AuditFilter, page, limit int) ([]*models.AuditEvent, int64, error) {
+	return s.auditRepo.Find(ctx, filter, page, limit)
+}
+
+// errAuditChainBroken stops the walk over the log at the first event that breaks the chain
+var errAuditChainBroken = errors.New("audit chain broken")
+
+// VerifyChain re-computes every event's hash and checks that each event follows the previous
+// one. An edited event no longer matches its hash, and a deleted one leaves a gap.
+func (s *auditServiceImpl) VerifyChain(ctx context.Context) (*models.AuditChainVerification, error) {
+	result := &models.AuditChainVerification{Valid: true}
+	err := s.auditRepo.Iterate(ctx, func(event *models.AuditEvent) error {
+		var problem string
+		switch {
+		case event.Sequence != result.LastSequence+1:
+			problem = fmt.Sprintf("expected sequence %d, found %d", result.LastSequence+1, event.Sequence)
+		case event.PrevHash != result.LastHash:
+			problem = "previous hash does not match the preceding event"
+		case event.Hash != event.ComputeHash():
+			problem = "hash does not match the event's content"
+		}
+		if problem != "" {
+			result.Valid = false
+			result.BrokenSequence = event.Sequence
+			result.Problem = problem
+			return errAuditChainBroken
+		}
+		result.EventsChecked++
+		result.LastSequence = event.Sequence
+		result.LastHash = event.Hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, errAuditChainBroken) {
+		return nil, err
+	}
+	if !result.Valid {
+		log.Printf("[WARN] VerifyChain: Audit log broken at sequence %d: %s", result.BrokenSequence, result.Problem)
+	}
+	return result, nil
+}
+
+// auditChanges lists the top-level JSON fields that differ between before and after, sorted by
+// name. Either side may be nil, for a created or deleted entity. Fields in ignore are skipped.
+func auditChanges(before, after interface{}, ignore ...string) []models.AuditChange {
+	beforeFields, afterFields := auditFields(before), auditFields(after)
+	skip := make(map[string]bool, len(ignore))
+	for _, field := range ignore {
+		skip[field] = true
+	}
+	names := make(map[string]bool, len(beforeFields)+len(afterFields))
+	for name := range beforeFields {
+		names[name] = true
+	}
+	for name := range afterFields {
+		names[name] = true
+	}
+
+	var changes []models.AuditChange
+	for name := range names {
+		b, a := beforeFields[name], afterFields[name]
+		if skip[name] || bytes.Equal(b, a) {
+			continue
+		}
+		changes = append(changes, models.AuditChange{Field: name, Before: string(b), After: string(a)})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// auditFields splits the JSON encoding of v into its top-level fields
+func auditFields(v interface{}) map[string]json.RawMessage {
+	fields := map[string]json.RawMessage{}
+	if v == nil {
+		return fields
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("[ERROR] auditFields: Failed to encode %T: %v", v, err)
+		return fields
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		log.Printf("[ERROR] auditFields: %T is not a JSON object: %v", v, err)
+	}
+	return fields
+}
@@ -9,88 +9,86 @@ import (
 
 	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
 	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
-	"github.com/golang-jwt/jwt/v5" // Import JWT library
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrInvalidRole is returned when assigning a role that does not exist
+var ErrInvalidRole = errors.New("invalid role")
+
+// ErrLastSuperAdmin is returned when a role change would leave no super admin
+var ErrLastSuperAdmin = errors.New("at least one super admin must remain")
+
 // AuthService defines the interface for authentication operations
 type AuthService interface {
-	Register(ctx context.Context, req *models.RegisterRequest) (*models.AdminUser, error) // Return AdminUser
-	Login(ctx context.Context, req *models.LoginRequest) (string, *models.AdminUser, error) // Returns JWT token AND User
+	Login(ctx context.Context, req *models.LoginRequest, clientIP string) (*models.LoginResult, error) // Returns tokens AND User, or a two-factor challenge
+	VerifyLogin(ctx context.Context, req *models.VerifyLoginRequest, clientIP string) (*models.LoginResult, error)
+	BeginLoginEnrollment(ctx context.Context, mfaToken string) (*models.TOTPEnrollment, error)
+	BeginTOTPEnrollment(ctx context.Context, adminID primitive.ObjectID) (*models.TOTPEnrollment, error)
+	ConfirmTOTPEnrollment(ctx context.Context, adminID primitive.ObjectID, code string) ([]string, error) // Returns the recovery codes
+	DisableTOTP(ctx context.Context, adminID primitive.ObjectID, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, adminID primitive.ObjectID, code string) ([]string, error)
+	ResetTOTP(ctx context.Context, adminID primitive.ObjectID, actor string) (*models.AdminUser, error)
+	ListLoginLockouts(ctx context.Context) ([]*models.LoginAttempt, error)
+	UnlockLogin(ctx context.Context, email, clientIP, actor string) error
+	Refresh(ctx context.Context, refreshToken string) (*models.AuthTokens, error) // Rotates the refresh token
+	Logout(ctx context.Context, refreshToken string) error                       // Revokes the token's session
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	RevokeSessions(ctx context.Context, adminID primitive.ObjectID, actor string) error
+	SetDisabled(ctx context.Context, adminID primitive.ObjectID, disabled bool, actor string) (*models.AdminUser, error) // Disabling revokes every session
+	ListAdmins(ctx context.Context) ([]*models.AdminUser, error)
+	AssignRole(ctx context.Context, adminID primitive.ObjectID, role, actor string) (*models.AdminUser, error) // Takes effect at the admin's next login
+	CreateInvite(ctx context.Context, req *models.CreateInviteRequest, actor string) (*models.AdminInvite, string, error) // Returns the invite and its one-time token
+	ListInvites(ctx context.Context) ([]*models.AdminInvite, error)
+	RevokeInvite(ctx context.Context, inviteID primitive.ObjectID, actor string) (*models.AdminInvite, error)
+	AcceptInvite(ctx context.Context, req *models.AcceptInviteRequest) (*models.AdminUser, error)
+	BootstrapSuperAdmin(ctx context.Context, email, password, firstName, lastName string) (*models.AdminUser, error) // Only while no admin exists
 }
 
 type authService struct {
-	adminUserRepo repositories.AdminUserRepository // Use AdminUserRepository
-	jwtSecret     string
-	jwtExpiresIn  int
+	adminUserRepo    repositories.AdminUserRepository // Use AdminUserRepository
+	inviteRepo       repositories.AdminInviteRepository
+	refreshRepo      repositories.RefreshTokenRepository
+	revokedRepo      repositories.RevokedTokenRepository
+	attemptRepo      repositories.LoginAttemptRepository
+	audit            AuditService // Records lockouts and unlocks
+	jwtSecret        string
+	jwtExpiresIn     int // Access token lifetime in seconds
+	refreshExpiresIn int // Refresh token lifetime in seconds
+	totpIssuer        string
+	totpRequiredRoles []string
+	loginLimits       LoginLimitOptions
 }
 
 // NewAuthService creates a new AuthService implementation
-func NewAuthService(adminUserRepo repositories.AdminUserRepository, jwtSecret string, jwtExpiresIn int) AuthService { // Accept AdminUserRepository and JWT config
+func NewAuthService(adminUserRepo repositories.AdminUserRepository, inviteRepo repositories.AdminInviteRepository, refreshRepo repositories.RefreshTokenRepository, revokedRepo repositories.RevokedTokenRepository, attemptRepo repositories.LoginAttemptRepository, audit AuditService, jwtSecret string, jwtExpiresIn, refreshExpiresIn int, totpIssuer string, totpRequiredRoles []string, loginLimits LoginLimitOptions) AuthService { // Accept AdminUserRepository and JWT config
 	return &authService{
-		adminUserRepo: adminUserRepo,
-		jwtSecret:     jwtSecret,
-		jwtExpiresIn:  jwtExpiresIn,
+		adminUserRepo:    adminUserRepo,
+		inviteRepo:       inviteRepo,
+		refreshRepo:      refreshRepo,
+		revokedRepo:      revokedRepo,
+		attemptRepo:      attemptRepo,
+		audit:            audit,
+		jwtSecret:        jwtSecret,
+		jwtExpiresIn:     jwtExpiresIn,
+		refreshExpiresIn: refreshExpiresIn,
+		totpIssuer:        totpIssuer,
+		totpRequiredRoles: totpRequiredRoles,
+		loginLimits:       loginLimits,
 	}
 }
 
-// Register handles admin user registration
-func (s *authService) Register(ctx context.Context, req *models.RegisterRequest) (*models.AdminUser, error) {
-	log.Printf("[DEBUG] Register: Attempting to register user with email: %s", req.Email)
-	// Check if admin user already exists
-	_, err := s.adminUserRepo.FindByEmail(ctx, req.Email)
-
-	// Handle potential errors from FindByEmail
-	 switch {
-	 case err == nil:
-	 	log.Printf("[DEBUG] Register: User with email %s already exists.", req.Email)
-	 	return nil, errors.New("admin user with this email already exists")
-	 case err != mongo.ErrNoDocuments:
-	 	log.Printf("[ERROR] Register: Error checking for existing user %s: %v", req.Email, err)
-	 	return nil, fmt.Errorf("error checking for existing admin user: %w", err)
-	 case err == mongo.ErrNoDocuments:
-	 	log.Printf("[DEBUG] Register: User with email %s not found. Proceeding with registration.", req.Email)
-	 }
-
-	// Hash password
-	 hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-	 if err != nil {
-	 	log.Printf("[ERROR] Register: Failed to hash password for %s: %v", req.Email, err)
-	 	return nil, fmt.Errorf("failed to hash password: %w", err)
-	 }
-	log.Printf("[DEBUG] Register: Password hashed successfully for %s.", req.Email)
-
-	adminUser := &models.AdminUser{
-		FirstName: req.FirstName,
-		LastName:  req.LastName,
-		Email:     req.Email,
-		Password:  string(hashedPassword),
-		Role:      "admin", // Default role for registration, adjust as needed
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}
+// Login handles admin user login. Admins who use two-factor authentication, or whose role
+// requires it, get a challenge to finish with VerifyLogin instead of tokens. Failures are
+// counted per email and client IP; see login_limits.go.
+func (s *authService) Login(ctx context.Context, req *models.LoginRequest, clientIP string) (*models.LoginResult, error) {
+	log.Printf("[DEBUG] Login: Attempting login for email: %s", req.Email)
 
-	// Save admin user using AdminUserRepository
-	// Corrected line 75: Create only returns an error
-	 err = s.adminUserRepo.Create(ctx, adminUser)
-	 if err != nil {
-	 	log.Printf("[ERROR] Register: Failed to create admin user %s: %v", req.Email, err)
-	 	return nil, fmt.Errorf("failed to create admin user: %w", err)
+	 if err := s.checkLoginAllowed(ctx, req.Email, clientIP); err != nil {
+	 	log.Printf("[WARN] Login: Refused attempt for %s from %s: %v", req.Email, clientIP, err)
+	 	return nil, err
 	 }
-	// Since Create doesn't return the created user, we can't log the ID here directly.
-	// We might need to adjust Create or fetch the user again if the ID is needed immediately.
-	log.Printf("[DEBUG] Register: Admin user %s creation initiated successfully.", req.Email)
-
-	// Don't return password hash
-	adminUser.Password = ""
-	// Return the user object we attempted to create (without the ID from DB yet)
-	return adminUser, nil
-}
-
-// Login handles admin user login
-func (s *authService) Login(ctx context.Context, req *models.LoginRequest) (string, *models.AdminUser, error) {
-	log.Printf("[DEBUG] Login: Attempting login for email: %s", req.Email)
 
 	// Find admin user by email using AdminUserRepository
 	adminUser, err := s.adminUserRepo.FindByEmail(ctx, req.Email)
@@ -99,47 +97,115 @@ func (s *authService) Login(ctx context.Context, req *models.LoginRequest) (stri
 	 switch {
 	 case err == mongo.ErrNoDocuments:
 	 	log.Printf("[DEBUG] Login: User not found for email: %s", req.Email)
-	 	return "", nil, errors.New("invalid email or password") // Keep generic error for security
+	 	 s.recordLoginFailure(ctx, req.Email, clientIP)
+	 	return nil, errors.New("invalid email or password") // Keep generic error for security
 	 case err != nil:
 	 	log.Printf("[ERROR] Login: Error finding user %s: %v", req.Email, err)
-	 	return "", nil, fmt.Errorf("error finding admin user: %w", err)
+	 	return nil, fmt.Errorf("error finding admin user: %w", err)
 	 case err == nil:
 	 	log.Printf("[DEBUG] Login: User found for email: %s. User ID: %s", req.Email, adminUser.ID.Hex())
-	 	log.Printf("[DEBUG] Login: Stored hash for %s: %s", req.Email, adminUser.Password) // Log the stored hash
 	 }
 
 	// Compare submitted password with the stored hash
-	log.Printf("[DEBUG] Login: Comparing provided password with stored hash for %s", req.Email)
+	log.Printf("[DEBUG] Login: Comparing provided password for %s", req.Email)
 	 err = bcrypt.CompareHashAndPassword([]byte(adminUser.Password), []byte(req.Password))
 	 if err != nil {
 	 	// Passwords don't match (or bcrypt error)
-	 	log.Printf("[DEBUG] Login: Password comparison failed for %s: %v", req.Email, err) // Log the specific bcrypt error
-	 	return "", nil, errors.New("invalid email or password") // Keep generic error for security
+	 	log.Printf("[DEBUG] Login: Password comparison failed for %s", req.Email)
+	 	 s.recordLoginFailure(ctx, req.Email, clientIP)
+	 	return nil, errors.New("invalid email or password") // Keep generic error for security
 	 }
 
 	log.Printf("[DEBUG] Login: Password comparison successful for %s", req.Email)
 
-	// Generate JWT token
-	log.Printf("[DEBUG] Login: Generating JWT token for %s", req.Email)
-	now := time.Now()
-	claims := jwt.MapClaims{
-		"sub":   adminUser.ID.Hex(), // Subject (user ID)
-		"email": adminUser.Email,
-		"role":  adminUser.Role,
-		"iat":   now.Unix(),                                       // Issued At
-		"exp":   now.Add(time.Second * time.Duration(s.jwtExpiresIn)).Unix(), // Expiration Time
-	}
+	 if adminUser.Disabled {
+	 	log.Printf("[WARN] Login: Refused login for disabled admin %s", req.Email)
+	 	return nil, ErrAccountDisabled
+	 }
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+	 if adminUser.TOTPEnabled || s.totpRequired(adminUser.Role) {
+	 	 mfaToken, err := s.issueMFAToken(adminUser)
+	 	 if err != nil {
+	 	 	return nil, err
+	 	 }
+	 	log.Printf("[DEBUG] Login: Two-factor challenge issued for %s", req.Email)
+	 	return &models.LoginResult{MFARequired: true, EnrollmentRequired: !adminUser.TOTPEnabled, MFAToken: mfaToken}, nil
+	 }
+
+	// Every login starts a new session
+	 tokens, err := s.issueTokens(ctx, adminUser, primitive.NewObjectID())
 	 if err != nil {
-	 	log.Printf("[ERROR] Login: Failed to sign JWT token for %s: %v", req.Email, err)
-	 	return "", nil, fmt.Errorf("failed to generate token: %w", err) // Return nil user on error
+	 	log.Printf("[ERROR] Login: Failed to issue tokens for %s: %v", req.Email, err)
+	 	return nil, err
 	 }
 
-	log.Printf("[DEBUG] Login: JWT token generated successfully for %s", req.Email)
+	log.Printf("[DEBUG] Login: Tokens issued successfully for %s", req.Email)
+	 s.clearLoginFailures(ctx, req.Email)
 	adminUser.Password = "" // Clear password before returning user object
-	return tokenString, adminUser, nil // Return token AND user object
+	return &models.LoginResult{Tokens: tokens, User: adminUser}, nil // Return tokens AND user object
 }
 
+// ListAdmins returns every admin user without password hashes
+func (s *authService) ListAdmins(ctx context.Context) ([]*models.AdminUser, error) {
+	admins, err := s.adminUserRepo.FindAll(ctx)
+	if err != nil {
+		log.Printf("[ERROR] ListAdmins: Failed to list admin users: %v", err)
+		return nil, fmt.Errorf("failed to list admin users: %w", err)
+	}
+	if admins == nil {
+		admins = []*models.AdminUser{}
+	}
+	for _, admin := range admins {
+		admin.Password = ""
+	}
+	return admins, nil
+}
+
+// AssignRole changes the role of an admin user. The last super admin cannot be demoted. The
+// new role is put into the admin's tokens from their next login or token refresh.
+func (s *authService) AssignRole(ctx context.Context, adminID primitive.ObjectID, role, actor string) (*models.AdminUser, error) {
+	if !models.ValidRole(role) {
+		return nil, fmt.Errorf("%w %q (expected one of %v)", ErrInvalidRole, role, models.Roles)
+	}
+	adminUser, err := s.adminUserRepo.FindByID(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	if isSuperAdmin(adminUser.Role) && !isSuperAdmin(role) {
+		if err := s.ensureOtherSuperAdmin(ctx, adminUser.ID); err != nil {
+			return nil, err
+		}
+	}
 
+	previous := adminUser.Role
+	adminUser.Role = role
+	adminUser.UpdatedAt = time.Now()
+	if err := s.adminUserRepo.Update(ctx, adminUser); err != nil {
+		log.Printf("[ERROR] AssignRole: Failed to update admin user %s: %v", adminID.Hex(), err)
+		return nil, fmt.Errorf("failed to update admin user: %w", err)
+	}
+	log.Printf("[INFO] AssignRole: Admin user %s changed from %q to %q by %s", adminID.Hex(), previous, role, actor)
+	adminUser.Password = ""
+	return adminUser, nil
+}
+
+// isSuperAdmin reports whether a role grants every permission
+func isSuperAdmin(role string) bool {
+	return role == models.RoleSuperAdmin
+}
+
+// ensureOtherSuperAdmin returns ErrLastSuperAdmin unless an enabled super admin other than
+// adminID exists
+func (s *authService) ensureOtherSuperAdmin(ctx context.Context, adminID primitive.ObjectID) error {
+	admins, err := s.adminUserRepo.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count super admins: %w", err)
+	}
+	for _, admin := range admins {
+		if admin.ID != adminID && isSuperAdmin(admin.Role) && !admin.Disabled {
+			return nil
+		}
+	}
+	return ErrLastSuperAdmin
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	// ErrAccountDisabled is returned when a disabled admin logs in or refreshes
+	ErrAccountDisabled = errors.New("admin account is disabled")
+	// ErrInvalidRefreshToken is returned for an unknown, expired, used or revoked refresh token
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrSelfDisable is returned when an admin tries to disable their own account
+	ErrSelfDisable = errors.New("admins cannot disable their own account")
+)
+
+// newTokenID returns a random jti for an access token
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// issueTokens signs an access token and stores a new refresh token for a session
+func (s *authService) issueTokens(ctx context.Context, adminUser *models.AdminUser, sessionID primitive.ObjectID) (*models.AuthTokens, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	accessExpiresAt := now.Add(time.Duration(s.jwtExpiresIn) * time.Second)
+	claims := jwt.MapClaims{
+		"sub":   adminUser.ID.Hex(), // Subject (user ID)
+		"email": adminUser.Email,
+		"role":  adminUser.Role,
+		"typ":   "access",
+		"jti":   jti,             // Checked against the denylist on every request
+		"sid":   sessionID.Hex(), // Login session, shared by the refresh tokens
+		"iat":   now.Unix(),
+		"exp":   accessExpiresAt.Unix(),
+	}
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.jwtSecret))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	refreshToken, err := newSecretToken()
+	if err != nil {
+		return nil, err
+	}
+	err = s.refreshRepo.Create(ctx, &models.RefreshToken{
+		SessionID:       sessionID,
+		AdminUserID:     adminUser.ID,
+		TokenHash:       hashSecretToken(refreshToken),
+		AccessJTI:       jti,
+		AccessExpiresAt: accessExpiresAt,
+		ExpiresAt:       now.Add(time.Duration(s.refreshExpiresIn) * time.Second),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.AuthTokens{
+		Token:            accessToken,
+		RefreshToken:     refreshToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        s.jwtExpiresIn,
+		RefreshExpiresIn: s.refreshExpiresIn,
+	}, nil
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh token. A token
+// that was already used is taken as stolen: the whole session is revoked.
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (*models.AuthTokens, error) {
+	stored, err := s.refreshRepo.FindByTokenHash(ctx, hashSecretToken(refreshToken))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if stored.RevokedAt != nil || !now.Before(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	rotated := false
+	if stored.UsedAt == nil {
+		if rotated, err = s.refreshRepo.MarkUsed(ctx, stored.ID, now); err != nil {
+			return nil, err
+		}
+	}
+	if !rotated {
+		log.Printf("[WARN] Refresh: Refresh token of session %s reused; revoking the session", stored.SessionID.Hex())
+		if err := s.revokeSession(ctx, stored.SessionID, "refresh token reused"); err != nil {
+			log.Printf("[ERROR] Refresh: Failed to revoke session %s: %v", stored.SessionID.Hex(), err)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// Re-read the admin so that role changes and disabling take effect at refresh
+	adminUser, err := s.adminUserRepo.FindByID(ctx, stored.AdminUserID)
+	if err != nil {
+		return nil, err
+	}
+	if adminUser.Disabled {
+		return nil, ErrAccountDisabled
+	}
+	// Sessions started before the admin's role required two-factor authentication end here
+	if !adminUser.TOTPEnabled && s.totpRequired(adminUser.Role) {
+		return nil, ErrTOTPRequired
+	}
+	return s.issueTokens(ctx, adminUser, stored.SessionID)
+}
+
+// Logout revokes the session of a refresh token, including its unexpired access tokens
+func (s *authService) Logout(ctx context.Context, refreshToken string) error {
+	stored, err := s.refreshRepo.FindByTokenHash(ctx, hashSecretToken(refreshToken))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return err
+	}
+	return s.revokeSession(ctx, stored.SessionID, "logout")
+}
+
+// IsTokenRevoked reports whether an access token has been denylisted
+func (s *authService) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	return s.revokedRepo.IsRevoked(ctx, jti)
+}
+
+// denylistAccessTokens revokes the unexpired access tokens issued with refresh tokens
+func (s *authService) denylistAccessTokens(ctx context.Context, tokens []*models.RefreshToken, reason string) error {
+	for _, token := range tokens {
+		err := s.revokedRepo.Add(ctx, &models.RevokedToken{
+			JTI:         token.AccessJTI,
+			AdminUserID: token.AdminUserID,
+			Reason:      reason,
+			ExpiresAt:   token.AccessExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	// Entries are only needed until the token expires
+	if _, err := s.revokedRepo.DeleteExpired(ctx, time.Now()); err != nil {
+		log.Printf("[WARN] Failed to delete expired revoked tokens: %v", err)
+	}
+	return nil
+}
+
+// revokeSession revokes the refresh tokens of a session and denylists its access tokens
+func (s *authService) revokeSession(ctx context.Context, sessionID primitive.ObjectID, reason string) error {
+	now := time.Now()
+	live, err := s.refreshRepo.FindLiveBySession(ctx, sessionID, now)
+	if err != nil {
+		return err
+	}
+	if _, err := s.refreshRepo.RevokeSession(ctx, sessionID, reason, now); err != nil {
+		return err
+	}
+	return s.denylistAccessTokens(ctx, live, reason)
+}
+
+// revokeAdminSessions revokes every session of an admin user
+func (s *authService) revokeAdminSessions(ctx context.Context, adminID primitive.ObjectID, reason string) error {
+	now := time.Now()
+	live, err := s.refreshRepo.FindLiveByAdmin(ctx, adminID, now)
+	if err != nil {
+		return err
+	}
+	if _, err := s.refreshRepo.RevokeByAdmin(ctx, adminID, reason, now); err != nil {
+		return err
+	}
+	return s.denylistAccessTokens(ctx, live, reason)
+}
+
+// RevokeSessions logs an admin user out everywhere
+func (s *authService) RevokeSessions(ctx context.Context, adminID primitive.ObjectID, actor string) error {
+	if _, err := s.adminUserRepo.FindByID(ctx, adminID); err != nil {
+		return err
+	}
+	if err := s.revokeAdminSessions(ctx, adminID, "revoked by "+actor); err != nil {
+		log.Printf("[ERROR] RevokeSessions: Failed to revoke sessions of admin %s: %v", adminID.Hex(), err)
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	log.Printf("[INFO] RevokeSessions: Sessions of admin %s revoked by %s", adminID.Hex(), actor)
+	return nil
+}
+
+// SetDisabled disables or re-enables an admin user. Disabling revokes every session at once.
+// Admins cannot disable themselves, and the last enabled super admin cannot be disabled.
+func (s *authService) SetDisabled(ctx context.Context, adminID primitive.ObjectID, disabled bool, actor string) (*models.AdminUser, error) {
+	adminUser, err := s.adminUserRepo.FindByID(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+	if disabled {
+		if adminID.Hex() == actor {
+			return nil, ErrSelfDisable
+		}
+		if isSuperAdmin(adminUser.Role) {
+			if err := s.ensureOtherSuperAdmin(ctx, adminID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	now := time.Now()
+	adminUser.Disabled = disabled
+	adminUser.DisabledAt = nil
+	if disabled {
+		adminUser.DisabledAt = &now
+	}
+	adminUser.UpdatedAt = now
+	if err := s.adminUserRepo.Update(ctx, adminUser); err != nil {
+		log.Printf("[ERROR] SetDisabled: Failed to update admin user %s: %v", adminID.Hex(), err)
+		return nil, fmt.Errorf("failed to update admin user: %w", err)
+	}
+	if disabled {
+		if err := s.revokeAdminSessions(ctx, adminID, "account disabled"); err != nil {
+			log.Printf("[ERROR] SetDisabled: Failed to revoke sessions of admin %s: %v", adminID.Hex(), err)
+			return nil, fmt.Errorf("admin disabled but revoking sessions failed: %w", err)
+		}
+	}
+	log.Printf("[INFO] SetDisabled: Admin user %s disabled=%t by %s", adminID.Hex(), disabled, actor)
+	adminUser.Password = ""
+	return adminUser, nil
+}
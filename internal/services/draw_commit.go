@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"golang.org/x/exp/slog"
+)
+
+// ErrDrawExecutionInProgress is returned when another call is already executing the draw
+var ErrDrawExecutionInProgress = errors.New("draw execution is already in progress")
+
+// executionReplay returns the outcome of an earlier execution started with the same idempotency key
+func executionReplay(draw *models.Draw) (*models.Draw, error) {
+	switch draw.Status {
+	case models.DrawStatusCompleted:
+		return draw, nil
+	case models.DrawStatusExecuting:
+		return draw, ErrDrawExecutionInProgress
+	default:
+		return draw, fmt.Errorf("draw execution with this idempotency key ended in status %s: %s", draw.Status, draw.ErrorMessage)
+	}
+}
+
+// commitDrawOutcome writes the result of an executed draw: the jackpot rollover (if any), the
+// winner records and the draw itself, now COMPLETED with its seed revealed. When the deployment
+// supports transactions these writes are atomic, so a failure leaves neither winners nor a
+// rollover behind for a draw that ends up FAILED. Nothing is committed unless this process
+// still holds the draw's execution lease.
+func (s *DrawServiceImpl) commitDrawOutcome(ctx context.Context, draw *models.Draw, rollover *models.JackpotRollover, winners []*models.Winner) error {
+	logLen := len(draw.ExecutionLog)
+	if !s.txManager.TransactionsSupported(ctx) {
+		draw.ExecutionLog = append(draw.ExecutionLog, "WARN: Database does not support transactions, winners and draw status are written separately")
+		logLen = len(draw.ExecutionLog) // Keep the warning even if the commit fails
+	}
+
+	now := time.Now()
+	draw.NumWinners = len(winners)
+	draw.Status = models.DrawStatusCompleted
+	draw.RevealedSeed = draw.Seed // Reveal the seed so the selection can be independently verified
+	draw.ExecutionEndTime = now
+	draw.ExecutionLeaseOwner = ""
+	draw.ExecutionLeaseExpiresAt = time.Time{}
+	if len(winners) > 0 {
+		draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Saving %d winner records", len(winners)))
+	} else {
+		draw.ExecutionLog = append(draw.ExecutionLog, "No winners selected or eligible to be saved.")
+	}
+	draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("%s: Execution completed successfully", now.Format(time.RFC3339)))
+
+	// The function may be retried by the driver on transient errors, so it only writes
+	err := s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		// Inside a transaction this also locks the draw against recovery until the commit
+		held, err := s.drawRepo.ExtendExecutionLease(txCtx, draw.ID, s.instanceID, now.Add(drawExecutionLeaseTTL))
+		if err != nil {
+			return err
+		}
+		if !held {
+			return errExecutionLeaseLost
+		}
+		if rollover != nil {
+			if err := s.jackpotRolloverRepo.Create(txCtx, rollover); err != nil {
+				return fmt.Errorf("failed to create jackpot rollover record: %w", err)
+			}
+		}
+		if err := s.winnerRepo.CreateMany(txCtx, winners); err != nil {
+			return fmt.Errorf("failed to save winner records: %w", err)
+		}
+		updated, err := s.drawRepo.ReplaceIfLeaseHeld(txCtx, draw, s.instanceID)
+		if err != nil {
+			return fmt.Errorf("failed to update final draw status: %w", err)
+		}
+		if !updated {
+			return errExecutionLeaseLost
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("ExecuteDraw: Failed to commit draw outcome", "error", err, "drawId", draw.ID)
+		// Undo the in-memory completion; ExecuteDraw marks the draw FAILED
+		draw.ExecutionLog = draw.ExecutionLog[:logLen]
+		draw.NumWinners = 0
+		if rollover != nil {
+			draw.RolloverExecuted = false
+		}
+		return err
+	}
+	return nil
+}
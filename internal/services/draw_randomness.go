@@ -0,0 +1,120 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+)
+
+// drawSeedBytes is the size of the secret seed committed to when a draw is scheduled
+const drawSeedBytes = 32
+
+// generateDrawSeed creates a new secret seed and its public commitment (SHA-256 of the seed).
+// Both are returned hex encoded.
+func generateDrawSeed() (seed string, commitment string, err error) {
+	b := make([]byte, drawSeedBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate draw seed: %w", err)
+	}
+	seed = hex.EncodeToString(b)
+	return seed, seedCommitment(b), nil
+}
+
+// seedCommitment returns the hex encoded SHA-256 of the raw seed bytes
+func seedCommitment(seed []byte) string {
+	sum := sha256.Sum256(seed)
+	return hex.EncodeToString(sum[:])
+}
+
+// verifySeedCommitment checks that a revealed hex seed matches the published commitment
+func verifySeedCommitment(seedHex, commitment string) bool {
+	seed, err := hex.DecodeString(seedHex)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(seedCommitment(seed)), []byte(commitment))
+}
+
+// sortPoolByMSISDN orders a participant pool by MSISDN so that selection does not
+// depend on the order in which MongoDB happened to return the documents.
+func sortPoolByMSISDN(pool []*models.User) {
+	sort.SliceStable(pool, func(i, j int) bool { return pool[i].MSISDN < pool[j].MSISDN })
+}
+
+// computePoolDigest hashes the (sorted) jackpot and consolation pools, including each
+// participant's points, into a single hex digest. The digest is mixed into the draw RNG
+// so the winners are bound to exactly this set of participants and weights.
+func computePoolDigest(poolA, poolB []*models.User) string {
+	h := sha256.New()
+	writePool := func(label string, pool []*models.User) {
+		h.Write([]byte(label))
+		var buf [8]byte
+		binary.BigEndian.PutUint64(buf[:], uint64(len(pool)))
+		h.Write(buf[:])
+		for _, u := range pool {
+			h.Write([]byte(u.MSISDN))
+			h.Write([]byte{0})
+			binary.BigEndian.PutUint64(buf[:], uint64(int64(u.Points)))
+			h.Write(buf[:])
+		}
+	}
+	writePool("POOL_A", poolA)
+	writePool("POOL_B", poolB)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// drawRNG is a deterministic CSPRNG (HMAC-SHA256 in counter mode) keyed by the draw seed
+// and the participant pool digest. Anyone holding the revealed seed and the same pools
+// can reproduce every random choice made during the draw.
+type drawRNG struct {
+	key     []byte
+	counter uint64
+	buf     []byte
+}
+
+// newDrawRNG creates a drawRNG from a hex encoded seed and a hex pool digest
+func newDrawRNG(seedHex, poolDigest string) (*drawRNG, error) {
+	seed, err := hex.DecodeString(seedHex)
+	if err != nil || len(seed) == 0 {
+		return nil, errors.New("invalid draw seed")
+	}
+	key := sha256.Sum256(append(append([]byte{}, seed...), []byte(poolDigest)...))
+	return &drawRNG{key: key[:]}, nil
+}
+
+// uint64 returns the next 64 random bits from the stream
+func (r *drawRNG) uint64() uint64 {
+	if len(r.buf) < 8 {
+		mac := hmac.New(sha256.New, r.key)
+		var ctr [8]byte
+		binary.BigEndian.PutUint64(ctr[:], r.counter)
+		r.counter++
+		mac.Write(ctr[:])
+		r.buf = append(r.buf, mac.Sum(nil)...)
+	}
+	v := binary.BigEndian.Uint64(r.buf[:8])
+	r.buf = r.buf[8:]
+	return v
+}
+
+// Intn returns a uniform random number in [0, n) using rejection sampling to avoid modulo bias
+func (r *drawRNG) Intn(n int) int {
+	if n <= 0 {
+		panic("drawRNG.Intn: invalid argument")
+	}
+	bound := uint64(n)
+	limit := ^uint64(0) - (^uint64(0) % bound)
+	for {
+		v := r.uint64()
+		if v < limit {
+			return int(v % bound)
+		}
+	}
+}
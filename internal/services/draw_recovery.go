@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// drawExecutionLeaseTTL is how long an EXECUTING draw stays claimed without a heartbeat
+	drawExecutionLeaseTTL = 2 * time.Minute
+	// drawExecutionHeartbeatInterval is how often the executing process renews its lease
+	drawExecutionHeartbeatInterval = 30 * time.Second
+	// drawRecoveryActor is recorded as the actor of changes made by stale draw recovery
+	drawRecoveryActor = "system:recovery"
+)
+
+// processOwnerID identifies this process in leases and locks shared between replicas
+func processOwnerID() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), primitive.NewObjectID().Hex())
+}
+
+// errExecutionLeaseLost is the cause of an execution cancelled because its lease was taken over
+var errExecutionLeaseLost = errors.New("execution lease lost: the draw was recovered or claimed by another process")
+
+// startExecutionHeartbeat renews the execution lease of a draw until the returned stop
+// function is called. stop waits for the heartbeat goroutine to exit. The returned context
+// is cancelled with errExecutionLeaseLost as soon as a renewal finds the lease held by
+// someone else, and when stop is called.
+func (s *DrawServiceImpl) startExecutionHeartbeat(ctx context.Context, drawID primitive.ObjectID) (context.Context, func()) {
+	execCtx, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(drawExecutionHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-execCtx.Done():
+				return
+			case <-ticker.C:
+				held, err := s.drawRepo.ExtendExecutionLease(execCtx, drawID, s.instanceID, time.Now().Add(drawExecutionLeaseTTL))
+				if err != nil {
+					slog.Error("ExecuteDraw: Failed to renew execution lease", "error", err, "drawId", drawID)
+				} else if !held {
+					slog.Warn("ExecuteDraw: Execution lease no longer held, stopping execution", "drawId", drawID)
+					cancel(errExecutionLeaseLost)
+					return
+				}
+			}
+		}
+	}()
+	return execCtx, func() {
+		close(done)
+		<-exited
+		cancel(nil)
+	}
+}
+
+// discardOrphanSnapshot deletes the participant snapshot of a draw that has no winner records.
+// Such a snapshot belongs to an execution that never committed, and verification would
+// otherwise replay against it.
+func (s *DrawServiceImpl) discardOrphanSnapshot(ctx context.Context, drawID primitive.ObjectID) error {
+	winners, err := s.winnerRepo.FindByDrawID(ctx, drawID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch winners: %w", err)
+	}
+	if len(winners) > 0 {
+		return nil // The snapshot the winners were drawn from
+	}
+	return s.drawParticipantRepo.DeleteByDrawID(ctx, drawID)
+}
+
+// RecoverStaleDraws finds draws left EXECUTING by a process that stopped renewing its lease.
+// A draw without winner records is rolled back to SCHEDULED so it can be executed again;
+// a draw that already has winners is marked FAILED for an admin to void and re-run.
+// It returns the number of draws recovered.
+func (s *DrawServiceImpl) RecoverStaleDraws(ctx context.Context, now time.Time) (int, error) {
+	executing, err := s.drawRepo.FindByStatus(ctx, string(models.DrawStatusExecuting))
+	if err != nil {
+		slog.Error("RecoverStaleDraws: Failed to find executing draws", "error", err)
+		return 0, fmt.Errorf("failed to find executing draws: %w", err)
+	}
+
+	recovered := 0
+	for _, draw := range executing {
+		expiresAt := draw.ExecutionLeaseExpiresAt
+		if expiresAt.IsZero() {
+			// Draws started before leases were introduced
+			expiresAt = draw.ExecutionStartTime.Add(drawExecutionLeaseTTL)
+		}
+		if now.Before(expiresAt) {
+			continue // Still being executed
+		}
+		if err := s.recoverStaleDraw(ctx, draw, now); err != nil {
+			slog.Error("RecoverStaleDraws: Failed to recover draw", "error", err, "drawId", draw.ID)
+			continue
+		}
+		recovered++
+	}
+	return recovered, nil
+}
+
+// recoverStaleDraw rolls a single stale EXECUTING draw back to SCHEDULED or marks it FAILED
+func (s *DrawServiceImpl) recoverStaleDraw(ctx context.Context, draw *models.Draw, now time.Time) error {
+	stamp := now.Format(time.RFC3339)
+	staleOwner := draw.ExecutionLeaseOwner
+
+	// Take the lease over first: from here on the stale executor can no longer commit, and a
+	// stale executor that committed in the meantime keeps its outcome
+	draw.ExecutionLeaseOwner = drawRecoveryActor
+	draw.ExecutionLeaseExpiresAt = now.Add(drawExecutionLeaseTTL)
+	var taken bool
+	var err error
+	if staleOwner == "" {
+		// Draws started before leases were introduced
+		taken, err = s.drawRepo.ReplaceIfStatus(ctx, draw, models.DrawStatusExecuting)
+	} else {
+		taken, err = s.drawRepo.ReplaceIfLeaseHeld(ctx, draw, staleOwner)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to take over execution lease: %w", err)
+	}
+	if !taken {
+		return fmt.Errorf("draw %s changed while being recovered", draw.ID.Hex())
+	}
+
+	winners, err := s.winnerRepo.FindByDrawID(ctx, draw.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch winners: %w", err)
+	}
+	if len(winners) > 0 {
+		draw.Status = models.DrawStatusFailed
+		draw.ErrorMessage = fmt.Sprintf("Execution interrupted after %d winners were recorded", len(winners))
+		draw.ExecutionEndTime = now
+		draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("%s: RECOVERY: Execution by %s was interrupted after winners were recorded, marked FAILED", stamp, staleOwner))
+	} else {
+		// Undo the side effects written before winners: the rollover and the participant snapshot
+		if err := s.reverseRollover(ctx, draw, "Execution interrupted", drawRecoveryActor, now); err != nil {
+			return err
+		}
+		if err := s.discardOrphanSnapshot(ctx, draw.ID); err != nil {
+			return err
+		}
+		draw.Status = models.DrawStatusScheduled
+		draw.ExecutionStartTime = time.Time{}
+		draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("%s: RECOVERY: Execution by %s was interrupted before winners were recorded, rolled back to SCHEDULED", stamp, staleOwner))
+	}
+	draw.ExecutionLeaseOwner = ""
+	draw.ExecutionLeaseExpiresAt = time.Time{}
+
+	updated, err := s.drawRepo.ReplaceIfLeaseHeld(ctx, draw, drawRecoveryActor)
+	if err != nil {
+		return fmt.Errorf("failed to update recovered draw: %w", err)
+	}
+	if !updated {
+		return fmt.Errorf("draw %s changed while being recovered", draw.ID.Hex())
+	}
+	slog.Warn("Recovered stale executing draw", "drawId", draw.ID, "status", draw.Status, "staleOwner", staleOwner, "winners", len(winners))
+	return nil
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/exp/slog"
+)
+
+// VoidDraw annuls the results of a completed or failed draw. Every winner of the draw is marked
+// INELIGIBLE and the jackpot rollover the draw created, if any, is reversed. Draws with a prize
+// already paid out cannot be voided.
+func (s *DrawServiceImpl) VoidDraw(ctx context.Context, drawID primitive.ObjectID, reason, actor string) (*models.Draw, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("a reason is required to void a draw")
+	}
+
+	draw, err := s.GetDrawByID(ctx, drawID)
+	if err != nil {
+		return nil, err
+	}
+	if draw.Status != models.DrawStatusCompleted && draw.Status != models.DrawStatusFailed {
+		return draw, fmt.Errorf("only COMPLETED or FAILED draws can be voided (current: %s)", draw.Status)
+	}
+
+	winners, err := s.winnerRepo.FindByDrawID(ctx, drawID)
+	if err != nil {
+		slog.Error("VoidDraw: Failed to fetch winners", "error", err, "drawId", drawID)
+		return draw, fmt.Errorf("failed to fetch winners: %w", err)
+	}
+	for _, winner := range winners {
+		if winner.ClaimStatus == models.ClaimStatusPaid {
+			return draw, fmt.Errorf("winner %s has already been paid, draw cannot be voided", winner.ID.Hex())
+		}
+	}
+
+	now := time.Now()
+	stamp := now.Format(time.RFC3339)
+
+	// 1. Reverse the rollover first so a failure leaves the winners untouched
+	if err := s.reverseRollover(ctx, draw, reason, actor, now); err != nil {
+		return draw, err
+	}
+
+	// 2. Mark every winner ineligible
+	voided := 0
+	for _, winner := range winners {
+		if winner.ClaimStatus == models.ClaimStatusIneligible {
+			continue
+		}
+		winner.ClaimNotes = fmt.Sprintf("Draw voided (was %s): %s", winner.ClaimStatus, reason)
+		winner.ClaimStatus = models.ClaimStatusIneligible
+		if err := s.winnerRepo.Update(ctx, winner); err != nil {
+			slog.Error("VoidDraw: Failed to mark winner ineligible", "error", err, "winnerId", winner.ID, "drawId", drawID)
+			return draw, fmt.Errorf("failed to mark winner %s ineligible: %w", winner.ID.Hex(), err)
+		}
+		voided++
+	}
+
+	// 3. Record the void on the draw
+	completed := *draw
+	draw.Status = models.DrawStatusVoided
+	draw.VoidReason = reason
+	draw.VoidedBy = actor
+	draw.VoidedAt = now
+	draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("%s: Draw voided by %s, %d winners marked ineligible: %s", stamp, actor, voided, reason))
+	if err := s.drawRepo.Update(ctx, draw); err != nil {
+		slog.Error("VoidDraw: Failed to update draw", "error", err, "drawId", drawID)
+		return draw, fmt.Errorf("failed to update voided draw: %w", err)
+	}
+
+	slog.Info("Draw voided", "drawId", drawID, "by", actor, "winners", voided)
+	s.recordDrawAudit(ctx, models.AuditActionDrawVoided, &completed, draw, map[string]string{"reason": reason, "winnersVoided": strconv.Itoa(voided)})
+	return draw, nil
+}
+
+// reverseRollover reverses the jackpot rollover created by a draw being voided. A destination
+// draw settles its jackpot from the rollovers in force when it executes, so one that has not
+// executed yet simply no longer receives the amount; stored jackpots are left alone.
+func (s *DrawServiceImpl) reverseRollover(ctx context.Context, draw *models.Draw, reason, actor string, now time.Time) error {
+	rollover, err := s.jackpotRolloverRepo.FindBySourceDrawID(ctx, draw.ID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil
+		}
+		slog.Error("VoidDraw: Failed to fetch rollover", "error", err, "drawId", draw.ID)
+		return fmt.Errorf("failed to fetch jackpot rollover: %w", err)
+	}
+
+	rollover.ReversedAt = now
+	rollover.ReversedBy = actor
+	rollover.ReversalReason = reason
+	if err := s.jackpotRolloverRepo.Update(ctx, rollover); err != nil {
+		slog.Error("VoidDraw: Failed to reverse rollover", "error", err, "rolloverId", rollover.ID, "drawId", draw.ID)
+		return fmt.Errorf("failed to reverse jackpot rollover: %w", err)
+	}
+	draw.RolloverExecuted = false
+	draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("%s: Rollover of %.2f to %s reversed", now.Format(time.RFC3339), rollover.RolloverAmount, rollover.DestinationDrawDate.Format("2006-01-02")))
+
+	destination, err := s.rolloverDestination(ctx, rollover)
+	if err == nil && destination.Status != models.DrawStatusScheduled {
+		draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("WARN: Destination draw %s is %s, its jackpot already included the reversed rollover", destination.ID.Hex(), destination.Status))
+	}
+	return nil
+}
+
+// rolloverDestination returns the draw that receives a rollover: the draw it was recorded for,
+// or that draw's re-run, falling back to the draw of its destination date for older records
+func (s *DrawServiceImpl) rolloverDestination(ctx context.Context, rollover *models.JackpotRollover) (*models.Draw, error) {
+	if rollover.DestinationDrawID.IsZero() {
+		return s.drawRepo.FindByDate(ctx, rollover.DestinationDrawDate)
+	}
+	destination, err := s.drawRepo.FindByID(ctx, rollover.DestinationDrawID)
+	for err == nil && !destination.ReRunDrawID.IsZero() {
+		destination, err = s.drawRepo.FindByID(ctx, destination.ReRunDrawID)
+	}
+	return destination, err
+}
+
+// ReRunDraw schedules a fresh draw with the configuration of a voided or failed draw. The new
+// draw gets its own seed commitment and both draws are linked to each other.
+func (s *DrawServiceImpl) ReRunDraw(ctx context.Context, drawID primitive.ObjectID, reason, actor string) (*models.Draw, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errors.New("a reason is required to re-run a draw")
+	}
+
+	original, err := s.GetDrawByID(ctx, drawID)
+	if err != nil {
+		return nil, err
+	}
+	if original.Status != models.DrawStatusVoided && original.Status != models.DrawStatusFailed {
+		return original, fmt.Errorf("only VOIDED or FAILED draws can be re-run (current: %s)", original.Status)
+	}
+	if !original.ReRunDrawID.IsZero() {
+		return original, fmt.Errorf("draw has already been re-run as %s", original.ReRunDrawID.Hex())
+	}
+	if original.Status == models.DrawStatusFailed {
+		// A failed draw may still hold winners if it failed late; they have to be voided first
+		winners, err := s.winnerRepo.FindByDrawID(ctx, drawID)
+		if err != nil {
+			return original, fmt.Errorf("failed to fetch winners: %w", err)
+		}
+		for _, winner := range winners {
+			if winner.ClaimStatus != models.ClaimStatusIneligible {
+				return original, errors.New("failed draw has winners, void it before re-running")
+			}
+		}
+	}
+
+	// The re-run takes the original's place as the draw of its date
+	existing, err := s.drawRepo.FindByDate(ctx, original.DrawDate)
+	if err == nil && existing.ID != original.ID {
+		return original, fmt.Errorf("draw %s is already scheduled for %s", existing.ID.Hex(), original.DrawDate.Format("2006-01-02"))
+	}
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return original, fmt.Errorf("failed to check for existing draw: %w", err)
+	}
+
+	seed, seedCommitment, err := generateDrawSeed()
+	if err != nil {
+		slog.Error("ReRunDraw: Failed to generate draw seed", "error", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	rerun := &models.Draw{
+		DrawDate:                original.DrawDate,
+		DrawType:                original.DrawType,
+		EligibleDigits:          append([]int(nil), original.EligibleDigits...),
+		UseDefaultDigits:        original.UseDefaultDigits,
+		EligibilityWindow:       original.EligibilityWindow, // Same window as the draw being re-run
+		Status:                  models.DrawStatusScheduled,
+		Prizes:                  append([]models.Prize(nil), original.Prizes...),
+		PrizeStructureVersion:   original.PrizeStructureVersion,
+		BaseJackpotAmount:       original.BaseJackpotAmount,
+		RolloverAmount:          original.RolloverAmount,
+		CalculatedJackpotAmount: original.CalculatedJackpotAmount,
+		SeedCommitment:          seedCommitment,
+		Seed:                    seed,
+		ReRunOfDrawID:           original.ID,
+		ReRunReason:             reason,
+		ReRunRequestedBy:        actor,
+		ExecutionLog:            []string{fmt.Sprintf("%s: Scheduled by %s as re-run of draw %s: %s", now.Format(time.RFC3339), actor, original.ID.Hex(), reason)},
+		CreatedAt:               now,
+		UpdatedAt:               now,
+	}
+	if err := s.drawRepo.Create(ctx, rerun); err != nil {
+		slog.Error("ReRunDraw: Failed to create re-run draw", "error", err, "originalDrawId", drawID)
+		return nil, fmt.Errorf("failed to save re-run draw: %w", err)
+	}
+
+	original.ReRunDrawID = rerun.ID
+	original.ExecutionLog = append(original.ExecutionLog, fmt.Sprintf("%s: Re-run scheduled by %s as draw %s: %s", now.Format(time.RFC3339), actor, rerun.ID.Hex(), reason))
+	if err := s.drawRepo.Update(ctx, original); err != nil {
+		slog.Error("ReRunDraw: Failed to link original draw to re-run", "error", err, "drawId", drawID, "reRunDrawId", rerun.ID)
+		return rerun, fmt.Errorf("re-run draw %s created but linking the original failed: %w", rerun.ID.Hex(), err)
+	}
+
+	slog.Info("Draw re-run scheduled", "originalDrawId", drawID, "reRunDrawId", rerun.ID, "by", actor)
+	s.recordDrawAudit(ctx, models.AuditActionDrawReRun, nil, rerun, map[string]string{"reRunOfDrawId": original.ID.Hex(), "reason": reason})
+	return rerun, nil
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/exp/slog"
+)
+
+// drawSchedulerLockName is the leader lock shared by all replicas running the scheduler
+const drawSchedulerLockName = "draw_scheduler"
+
+// DrawSchedulerOptions configures the background draw scheduler
+type DrawSchedulerOptions struct {
+	DaysAhead    int            // Draws are kept scheduled from today up to this many days ahead
+	Interval     time.Duration  // How often the scheduler wakes up
+	LockTTL      time.Duration  // Leader lease duration, must be longer than Interval
+	AutoExecute  bool           // Request approval to execute today's draw once ExecuteAfter has passed
+	ExecuteAfter time.Duration  // Offset from local midnight after which today's draw is executed (e.g. 18h30m)
+	Location     *time.Location // Promotion timezone used to decide "today"
+}
+
+// DrawScheduler creates DAILY draws Monday to Friday and the SATURDAY draw ahead of time,
+// optionally requests the execution of today's draw after the cut-off, forfeits unclaimed
+// jackpots and recovers draws left EXECUTING by a crashed replica.
+// Only the replica holding the leader lock acts. It never executes a draw itself: executions
+// go through the maker-checker approval like those requested by an admin.
+type DrawScheduler struct {
+	drawService     DrawService
+	approvalService ApprovalService
+	drawRepo        repositories.DrawRepository
+	lockRepo        repositories.LeaderLockRepository
+	opts            DrawSchedulerOptions
+	owner           string
+}
+
+// NewDrawScheduler creates a new DrawScheduler
+func NewDrawScheduler(drawService DrawService, approvalService ApprovalService, drawRepo repositories.DrawRepository, lockRepo repositories.LeaderLockRepository, opts DrawSchedulerOptions) *DrawScheduler {
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+	if opts.LockTTL <= opts.Interval {
+		opts.LockTTL = 3 * opts.Interval
+	}
+	return &DrawScheduler{
+		drawService:     drawService,
+		approvalService: approvalService,
+		drawRepo:        drawRepo,
+		lockRepo:        lockRepo,
+		opts:            opts,
+		owner:           processOwnerID(),
+	}
+}
+
+// Run blocks until ctx is cancelled, running a scheduling pass on every interval
+func (s *DrawScheduler) Run(ctx context.Context) {
+	slog.Info("Draw scheduler started", "owner", s.owner, "daysAhead", s.opts.DaysAhead, "autoExecute", s.opts.AutoExecute)
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+	defer func() {
+		// Let another replica take over immediately instead of waiting for the lease to expire
+		if err := s.lockRepo.Release(context.Background(), drawSchedulerLockName, s.owner); err != nil {
+			slog.Error("Draw scheduler: failed to release leader lock", "error", err)
+		}
+	}()
+
+	for {
+		s.tick(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			slog.Info("Draw scheduler stopped", "owner", s.owner)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick runs one scheduling pass if this replica is the leader
+func (s *DrawScheduler) tick(ctx context.Context, now time.Time) {
+	isLeader, err := s.lockRepo.TryAcquire(ctx, drawSchedulerLockName, s.owner, s.opts.LockTTL)
+	if err != nil {
+		slog.Error("Draw scheduler: failed to acquire leader lock", "error", err)
+		return
+	}
+	if !isLeader {
+		return
+	}
+	// A pass can outlast LockTTL (executing a large draw above all), so the lock is renewed
+	// while it runs and the pass is cancelled if another replica takes over
+	ctx, stopRenewing := s.renewLeadership(ctx)
+	defer stopRenewing()
+
+	if recovered, err := s.drawService.RecoverStaleDraws(ctx, now); err != nil {
+		slog.Error("Draw scheduler: failed to recover stale draws", "error", err)
+	} else if recovered > 0 {
+		slog.Warn("Draw scheduler: recovered stale executing draws", "recovered", recovered)
+	}
+	s.scheduleUpcoming(ctx, now)
+	if s.opts.AutoExecute {
+		s.executeDue(ctx, now)
+	}
+	if forfeited, err := s.drawService.ProcessExpiredJackpotClaims(ctx, now); err != nil {
+		slog.Error("Draw scheduler: failed to process expired jackpot claims", "error", err)
+	} else if forfeited > 0 {
+		slog.Info("Draw scheduler: processed expired jackpot claims", "forfeited", forfeited)
+	}
+}
+
+// renewLeadership renews the leader lock every third of LockTTL until the returned stop function
+// is called. The returned context is cancelled when the lock is lost.
+func (s *DrawScheduler) renewLeadership(ctx context.Context) (context.Context, func()) {
+	passCtx, cancel := context.WithCancel(ctx)
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(s.opts.LockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-passCtx.Done():
+				return
+			case <-ticker.C:
+				held, err := s.lockRepo.TryAcquire(passCtx, drawSchedulerLockName, s.owner, s.opts.LockTTL)
+				if err != nil {
+					slog.Error("Draw scheduler: failed to renew leader lock", "error", err)
+					continue // The lease survives a couple of failed renewals
+				}
+				if !held {
+					slog.Warn("Draw scheduler: leader lock lost, stopping the pass", "owner", s.owner)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	return passCtx, func() {
+		cancel()
+		<-exited
+	}
+}
+
+// drawDay returns the draw date for the local calendar day of t, in the same form as
+// draws scheduled through the API (midnight UTC of that day)
+func (s *DrawScheduler) drawDay(t time.Time) time.Time {
+	local := t.In(s.opts.Location)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// scheduleUpcoming creates any missing draws from today up to DaysAhead days ahead, using
+// the active draw type registered for each weekday
+func (s *DrawScheduler) scheduleUpcoming(ctx context.Context, now time.Time) {
+	drawTypes, err := s.drawService.ListDrawTypes(ctx)
+	if err != nil {
+		slog.Error("Draw scheduler: failed to load draw types", "error", err)
+		return
+	}
+
+	today := s.drawDay(now)
+	for i := 0; i <= s.opts.DaysAhead; i++ {
+		drawDate := today.AddDate(0, 0, i)
+		drawType := drawTypeForWeekday(drawTypes, drawDate.Weekday())
+		if drawType == nil {
+			continue // No draw type is held on this weekday
+		}
+
+		existing, err := s.drawRepo.FindByDate(ctx, drawDate)
+		if err == nil && existing != nil {
+			continue
+		}
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			slog.Error("Draw scheduler: failed to check for existing draw", "error", err, "date", drawDate)
+			continue
+		}
+
+		draw, err := s.drawService.ScheduleDraw(ctx, drawDate, drawType.Code, nil, true)
+		if err != nil {
+			slog.Error("Draw scheduler: failed to schedule draw", "error", err, "date", drawDate, "type", drawType.Code)
+			continue
+		}
+		slog.Info("Draw scheduler: scheduled draw", "drawId", draw.ID, "date", drawDate, "type", drawType.Code)
+	}
+}
+
+// executeDue requests the execution of today's draw once the configured execution time has
+// passed. The draw runs when an admin approves the request.
+func (s *DrawScheduler) executeDue(ctx context.Context, now time.Time) {
+	local := now.In(s.opts.Location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.opts.Location)
+	if local.Before(midnight.Add(s.opts.ExecuteAfter)) {
+		return
+	}
+
+	draw, err := s.drawRepo.FindByDate(ctx, s.drawDay(now))
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			slog.Error("Draw scheduler: failed to find today's draw", "error", err)
+		}
+		return
+	}
+	if draw.Status != models.DrawStatusScheduled {
+		return
+	}
+
+	request, filed, err := s.approvalService.RequestScheduledDrawExecution(ctx, draw.ID)
+	if err != nil {
+		slog.Error("Draw scheduler: failed to request draw execution", "error", err, "drawId", draw.ID)
+		return
+	}
+	if filed {
+		slog.Info("Draw scheduler: requested draw execution", "drawId", draw.ID, "date", draw.DrawDate, "approvalId", request.ID)
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"encoding/json" // Added for prize structure parsing
 	"errors"
 	"fmt"
-	"math/rand"
+	"sort"
 	"strings" // Added for prize structure parsing
 	"time"
 
@@ -29,6 +29,12 @@ type DrawServiceImpl struct {
 	 systemConfigRepo     repositories.SystemConfigRepository
 	 pointTransactionRepo repositories.PointTransactionRepository
 	 jackpotRolloverRepo  repositories.JackpotRolloverRepository
+	 drawParticipantRepo  repositories.DrawParticipantRepository
+	 drawTypeRepo         repositories.DrawTypeRepository
+	 prizeStructureRepo   repositories.PrizeStructureRepository
+	 txManager            repositories.TransactionManager
+	 audit                AuditService
+	 instanceID           string // Owner recorded on the execution leases taken by this process
 	 // userService          UserService // Might be needed for AllocatePointsForTopup
 }
 
@@ -41,6 +47,11 @@ func NewDrawService(
 	 systemConfigRepo repositories.SystemConfigRepository,
 	 pointTransactionRepo repositories.PointTransactionRepository,
 	 jackpotRolloverRepo repositories.JackpotRolloverRepository,
+	 drawParticipantRepo repositories.DrawParticipantRepository,
+	 drawTypeRepo repositories.DrawTypeRepository,
+	 prizeStructureRepo repositories.PrizeStructureRepository,
+	 txManager repositories.TransactionManager,
+	 audit AuditService,
 	 // userService UserService,
 ) *DrawServiceImpl {
 	return &DrawServiceImpl{
@@ -51,10 +62,36 @@ func NewDrawService(
 		 systemConfigRepo:     systemConfigRepo,
 		 pointTransactionRepo: pointTransactionRepo,
 		 jackpotRolloverRepo:  jackpotRolloverRepo,
+		 drawParticipantRepo:  drawParticipantRepo,
+		 drawTypeRepo:         drawTypeRepo,
+		 prizeStructureRepo:   prizeStructureRepo,
+		 txManager:            txManager,
+		 audit:                audit,
+		 instanceID:           processOwnerID(),
 		 // userService:          userService,
 	}
 }
 
+// drawAuditIgnored are the draw fields left out of audit diffs: the execution log repeats
+// the change in prose and updatedAt always changes
+var drawAuditIgnored = []string{"executionLog", "updatedAt"}
+
+// recordDrawAudit records an audit event for a draw, diffing it against before (nil for a
+// new draw)
+func (s *DrawServiceImpl) recordDrawAudit(ctx context.Context, action string, before, after *models.Draw, details map[string]string) {
+	 var previous interface{}
+	 if before != nil {
+		 previous = before
+	 }
+	 s.audit.Record(ctx, &models.AuditEvent{
+		 Action:     action,
+		 TargetType: models.AuditTargetDraw,
+		 TargetID:   after.ID.Hex(),
+		 Changes:    auditChanges(previous, after, drawAuditIgnored...),
+		 Details:    details,
+	 })
+}
+
 // --- Core Draw Lifecycle Methods (Refactored & Refined) ---
 
 
@@ -71,20 +108,35 @@ func (s *DrawServiceImpl) ScheduleDraw(ctx context.Context, drawDate time.Time,
 		 return nil, fmt.Errorf("failed to check for existing draw: %w", err)
 	 }
 
-	 // 2. Determine final eligible digits
+	 // 2. Look up the draw type in the registry
+	 drawTypeDef, err := s.drawTypeDefinition(ctx, drawType)
+	 if err != nil {
+		 return nil, err
+	 }
+	 if !drawTypeDef.Active {
+		 return nil, fmt.Errorf("draw type %s is not active", drawTypeDef.Code)
+	 }
+
+	 // 2b. Determine final eligible digits
 	 finalEligibleDigits := eligibleDigits
 	 if useDefaultDigits {
-		 finalEligibleDigits = utils.GetDefaultEligibleDigits(drawDate.Weekday())
+		 finalEligibleDigits = drawTypeDef.DefaultDigits
+		 if len(finalEligibleDigits) == 0 {
+			 finalEligibleDigits = utils.GetDefaultEligibleDigits(drawDate.Weekday())
+		 }
 	 }
 
-	 // 3. Fetch Prize Structure from System Config
-	 prizes, err := s.GetPrizeStructure(ctx, drawType)
+	 // 3. Fetch the prize structure version in force on the draw date
+	 prizeStructure, err := s.effectivePrizeStructure(ctx, drawTypeDef, drawDate)
 	 if err != nil {
-		 return nil, err // Error already logged in GetPrizeStructure
+		 return nil, err // Error already logged in effectivePrizeStructure
 	 }
 
+	 // 3b. Snapshot the eligibility window rule so later registry changes don't affect this draw
+	 eligibilityWindow := drawTypeDef.EligibilityWindow
+
 	 // 4. Fetch Base Jackpot Amount from System Config
-	 baseJackpotKey := "base_jackpot_" + strings.ToUpper(drawType)
+	 baseJackpotKey := drawTypeDef.BaseJackpotKey
 	 baseJackpotConfig, err := s.systemConfigRepo.FindByKey(ctx, baseJackpotKey)
 	 if err != nil {
 		 slog.Error("Failed to fetch base jackpot config", "error", err, "key", baseJackpotKey)
@@ -96,7 +148,8 @@ func (s *DrawServiceImpl) ScheduleDraw(ctx context.Context, drawDate time.Time,
 		 return nil, fmt.Errorf("invalid base jackpot amount format in config %s", baseJackpotKey)
 	 }
 
-	 // 5. Calculate Rollover Amount *into* this draw
+	 // 5. Calculate Rollover Amount *into* this draw. This is a projection: rollovers created
+	 // later are added by ExecuteDraw (applyIncomingRollovers).
 	 accumulatedRollover := 0.0
 	 rollovers, err := s.jackpotRolloverRepo.FindRolloversByDestinationDate(ctx, drawDate)
 	 if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
@@ -104,43 +157,59 @@ func (s *DrawServiceImpl) ScheduleDraw(ctx context.Context, drawDate time.Time,
 		 // Decide if this is fatal. For now, log and continue with 0 rollover.
 	 } else if err == nil {
 		 for _, rollover := range rollovers {
-			 accumulatedRollover += rollover.RolloverAmount
+			 if rollover.DestinationDrawType == "" || rollover.DestinationDrawType == drawTypeDef.Code {
+				 accumulatedRollover += rollover.RolloverAmount
+			 }
 		 }
 	 }
 
 	 // 6. Calculate Final Jackpot Amount
 	 calculatedJackpot := baseJackpotAmount + accumulatedRollover
 
-	 // 7. Create the Draw object
+	 // 7. Commit to a secret seed for the draw's randomness
+	 seed, seedCommitment, err := generateDrawSeed()
+	 if err != nil {
+		 slog.Error("Failed to generate draw seed", "error", err)
+		 return nil, err
+	 }
+
+	 // 8. Create the Draw object
 	 draw := &models.Draw{
 		 DrawDate:                drawDate,
-		 DrawType:                strings.ToUpper(drawType),
+		 DrawType:                drawTypeDef.Code,
 		 EligibleDigits:          finalEligibleDigits,
 		 UseDefaultDigits:        useDefaultDigits,
+		 EligibilityWindow:       &eligibilityWindow,
 		 Status:                  models.DrawStatusScheduled,
-		 Prizes:                  prizes, // Use prizes from config
+		 Prizes:                  prizeStructure.Prizes,
+		 PrizeStructureVersion:   prizeStructure.Version,
 		 BaseJackpotAmount:       baseJackpotAmount,
 		 RolloverAmount:          accumulatedRollover,
 		 CalculatedJackpotAmount: calculatedJackpot,
 		 RolloverExecuted:        false,
+		 SeedCommitment:          seedCommitment,
+		 Seed:                    seed,
 		 CreatedAt:               time.Now(),
 		 UpdatedAt:               time.Now(),
 	 }
 
-	 // 8. Save the Draw
+	 // 9. Save the Draw
 	 err = s.drawRepo.Create(ctx, draw)
 	 if err != nil {
 		 slog.Error("Failed to create draw in repository", "error", err)
 		 return nil, fmt.Errorf("failed to save scheduled draw: %w", err)
 	 }
 
-	 slog.Info("Draw scheduled successfully", "drawId", draw.ID, "date", drawDate, "type", drawType, "jackpot", calculatedJackpot)
+	 slog.Info("Draw scheduled successfully", "drawId", draw.ID, "date", drawDate, "type", draw.DrawType, "jackpot", calculatedJackpot)
+	 s.recordDrawAudit(ctx, models.AuditActionDrawScheduled, nil, draw, nil)
 	 return draw, nil
 }
 
 
-// ExecuteDraw executes a scheduled draw, including eligibility, selection, validation, and rollover
-func (s *DrawServiceImpl) ExecuteDraw(ctx context.Context, drawID primitive.ObjectID) (*models.Draw, error) {
+// ExecuteDraw executes a scheduled draw, including eligibility, selection, validation, and rollover.
+// A non-empty idempotencyKey is stored on the draw; repeating the call with the same key returns
+// the outcome of the first execution instead of running the draw again.
+func (s *DrawServiceImpl) ExecuteDraw(ctx context.Context, drawID primitive.ObjectID, idempotencyKey string) (result *models.Draw, err error) {
 	 // 1. Get the Draw & Basic Validation
 	 draw, err := s.drawRepo.FindByID(ctx, drawID)
 	 if err != nil {
@@ -148,16 +217,35 @@ func (s *DrawServiceImpl) ExecuteDraw(ctx context.Context, drawID primitive.Obje
 		 return nil, fmt.Errorf("draw not found: %w", err)
 	 }
 
+	 if idempotencyKey != "" && draw.ExecutionIdempotencyKey == idempotencyKey && draw.Status != models.DrawStatusScheduled {
+		 slog.Info("ExecuteDraw: Replaying outcome for repeated idempotency key", "drawId", drawID, "status", draw.Status)
+		 return executionReplay(draw)
+	 }
+
 	 if draw.Status != models.DrawStatusScheduled {
 		 slog.Warn("ExecuteDraw: Attempted to execute draw not in SCHEDULED state", "drawId", drawID, "status", draw.Status)
 		 return draw, fmt.Errorf("draw is not in SCHEDULED state (current: %s)", draw.Status)
 	 }
 
+	 scheduled := *draw // Compared with the outcome in the audit log
+
 	 // 2. Update Draw Status to EXECUTING
 	 draw.Status = models.DrawStatusExecuting
 	 draw.ExecutionStartTime = time.Now()
+	 draw.ExecutionLeaseOwner = s.instanceID
+	 draw.ExecutionLeaseExpiresAt = draw.ExecutionStartTime.Add(drawExecutionLeaseTTL)
+	 draw.ExecutionIdempotencyKey = idempotencyKey
 	 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("%s: Starting execution", time.Now().Format(time.RFC3339)))
-	 updateErr := s.drawRepo.Update(ctx, draw) // Use a different variable name here
+	 // Only one caller can move the draw out of SCHEDULED, so concurrent or retried calls cannot both run
+	 claimed, updateErr := s.drawRepo.ReplaceIfStatus(ctx, draw, models.DrawStatusScheduled)
+	 if updateErr == nil && !claimed {
+		 slog.Warn("ExecuteDraw: Draw was claimed by another execution", "drawId", drawID)
+		 current, findErr := s.drawRepo.FindByID(ctx, drawID)
+		 if findErr == nil && current.Status != models.DrawStatusExecuting {
+			 return current, fmt.Errorf("draw is not in SCHEDULED state (current: %s)", current.Status)
+		 }
+		 return current, ErrDrawExecutionInProgress
+	 }
 	 if updateErr != nil {
 		 slog.Error("ExecuteDraw: Failed to update draw status to EXECUTING", "error", updateErr, "drawId", drawID)
 		 // Attempt to return the original draw object on failure
@@ -168,292 +256,359 @@ func (s *DrawServiceImpl) ExecuteDraw(ctx context.Context, drawID primitive.Obje
 		 return originalDraw, fmt.Errorf("failed to mark draw as executing: %w", updateErr)
 	 }
 
-	 // Defer status update on failure/completion
+	 // Keep the lease alive while executing so recovery leaves this draw alone. If the lease is
+	 // lost anyway (the draw was recovered or claimed elsewhere) execCtx is cancelled, so the
+	 // execution stops before it commits anything.
+	 execCtx, stopHeartbeat := s.startExecutionHeartbeat(ctx, drawID)
+
+	 // Defer status update on failure. On success the COMPLETED status is written by
+	 // commitDrawOutcome together with the winners and rollover.
 	 defer func() {
-		 finalStatus := models.DrawStatusCompleted
-		 if r := recover(); r != nil {
-			 finalStatus = models.DrawStatusFailed
+		 stopHeartbeat()
+		 r := recover()
+		 if r == nil && err == nil {
+			 return
+		 }
+		 if r != nil {
 			 draw.ErrorMessage = fmt.Sprintf("Panic during execution: %v", r)
 			 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("%s: PANIC: %v", time.Now().Format(time.RFC3339), r))
 			 slog.Error("ExecuteDraw: Panic recovered", "panic", r, "drawId", drawID)
-			 // Ensure 'err' is set if it wasn't already (to prevent overwriting by defer update error)
+			 // Report the panic to the caller as an error
 			 if err == nil {
 				 err = fmt.Errorf("panic during execution: %v", r)
 			 }
-		 } else if err != nil {
-			 finalStatus = models.DrawStatusFailed
+			 result = draw
+		 } else {
 			 draw.ErrorMessage = err.Error()
 			 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("%s: ERROR: %s", time.Now().Format(time.RFC3339), err.Error()))
 			 slog.Error("ExecuteDraw: Execution failed", "error", err, "drawId", drawID)
-		 } else {
-			 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("%s: Execution completed successfully", time.Now().Format(time.RFC3339)))
-			 slog.Info("ExecuteDraw: Execution completed", "drawId", drawID)
 		 }
 
-		 draw.Status = finalStatus
+		 if errors.Is(context.Cause(execCtx), errExecutionLeaseLost) {
+			 // The draw belongs to whoever took it over; leave it as they wrote it
+			 slog.Error("ExecuteDraw: Execution abandoned after losing the lease", "error", err, "drawId", drawID)
+			 return
+		 }
+		 if discardErr := s.discardOrphanSnapshot(ctx, drawID); discardErr != nil {
+			 slog.Error("ExecuteDraw: Failed to discard participant snapshot of failed draw", "error", discardErr, "drawId", drawID)
+		 }
+		 draw.Status = models.DrawStatusFailed
+		 draw.RevealedSeed = ""
 		 draw.ExecutionEndTime = time.Now()
-		 finalUpdateErr := s.drawRepo.Update(ctx, draw)
+		 draw.ExecutionLeaseOwner = ""
+		 draw.ExecutionLeaseExpiresAt = time.Time{}
+		 updated, finalUpdateErr := s.drawRepo.ReplaceIfLeaseHeld(ctx, draw, s.instanceID)
 		 if finalUpdateErr != nil {
 			 slog.Error("ExecuteDraw: CRITICAL: Failed to update final draw status", "error", finalUpdateErr, "drawId", drawID, "finalStatusAttempt", draw.Status)
-			 // If the final update fails, the original error (err) takes precedence
-			 if err == nil {
-				 err = fmt.Errorf("failed to update final draw status: %w", finalUpdateErr)
-			 }
+		 } else if !updated {
+			 slog.Warn("ExecuteDraw: Draw was taken over before it could be marked FAILED", "drawId", drawID)
 		 }
 	 }()
 
+	 // 2b. Settle the jackpot: add the rollovers received since the draw was scheduled
+	 if err = s.applyIncomingRollovers(execCtx, draw); err != nil {
+		 return draw, err
+	 }
+
 	 // 3. Determine Eligibility Time Windows
-	 eligibilityCutoff := time.Date(draw.DrawDate.Year(), draw.DrawDate.Month(), draw.DrawDate.Day(), 18, 0, 0, 0, draw.DrawDate.Location())
-	 var eligibilityStart time.Time
-	 if draw.DrawType == "SATURDAY" {
-		 prevSaturday := draw.DrawDate.AddDate(0, 0, -7)
-		 eligibilityStart = time.Date(prevSaturday.Year(), prevSaturday.Month(), prevSaturday.Day(), 18, 0, 1, 0, draw.DrawDate.Location())
-	 } else {
-		 // Daily draw eligibility starts at 00:00:00 on the draw day
-		 eligibilityStart = time.Date(draw.DrawDate.Year(), draw.DrawDate.Month(), draw.DrawDate.Day(), 0, 0, 0, 0, draw.DrawDate.Location())
+	 eligibilityStart, eligibilityCutoff, err := drawEligibilityWindow(draw)
+	 if err != nil {
+		 return draw, err
 	 }
 	 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Eligibility window: %s to %s", eligibilityStart.Format(time.RFC3339), eligibilityCutoff.Format(time.RFC3339)))
 
 	 // 4. Fetch Participant Pools
-	 // Pool A (Jackpot): All users with *any* recharge in the window
-	 poolA, err := s.userRepo.FindUsersByRechargeWindow(ctx, eligibilityStart, eligibilityCutoff)
+	 poolA, poolB, excluded, err := fetchDrawPools(execCtx, s.userRepo, s.blacklistRepo, draw, eligibilityStart, eligibilityCutoff)
 	 if err != nil {
-		 draw.ExecutionLog = append(draw.ExecutionLog, "Failed to fetch Pool A")
-		 return draw, fmt.Errorf("failed to fetch jackpot participant pool: %w", err)
+		 draw.ExecutionLog = append(draw.ExecutionLog, "Failed to fetch participant pools")
+		 return draw, err
+	 }
+	 draw.ExcludedParticipants = len(excluded)
+	 for _, exclusion := range excluded {
+		 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Excluded %s (%s) from %s", maskMsisdn(exclusion.User.MSISDN), exclusion.Reason, exclusionPools(exclusion)))
 	 }
 	 draw.TotalParticipants = len(poolA)
 	 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Fetched Pool A (Jackpot Pool): %d users", len(poolA)))
-
-	 // Pool B (Consolation): Opt-in users meeting all criteria
-	 poolB, err := s.userRepo.FindEligibleConsolationUsers(ctx, draw.EligibleDigits, eligibilityCutoff, eligibilityStart, eligibilityCutoff)
-	 if err != nil {
-		 draw.ExecutionLog = append(draw.ExecutionLog, "Failed to fetch Pool B")
-		 return draw, fmt.Errorf("failed to fetch consolation participant pool: %w", err)
-	 }
 	 draw.EligibleOptedInParticipants = len(poolB)
 	 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Fetched Pool B (Consolation Pool): %d users", len(poolB)))
 
-	 // 5. Select Jackpot Winner (if Pool A not empty)
-	 var potentialJackpotWinner *models.User
-	 if len(poolA) > 0 {
-		 // Use points-weighted selection for Jackpot Pool A as well (REQFUNC027)
-		 weightedPoolA := createWeightedPool(poolA)
-		 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Created weighted pool for jackpot winner (total weight: %d)", len(weightedPoolA)))
-		 var selectionErr error
-		 potentialJackpotWinner, _, selectionErr = selectWeightedWinner(weightedPoolA)
-		 if selectionErr != nil {
-			 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("ERROR selecting weighted jackpot winner: %s", selectionErr.Error()))
-			 return draw, fmt.Errorf("failed to select weighted jackpot winner: %w", selectionErr)
-		 }
-		 draw.JackpotWinnerMsisdn = potentialJackpotWinner.MSISDN
-		 draw.JackpotWinnerValidationStatus = models.JackpotValidationPending
-		 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Potential Jackpot Winner Selected (Weighted): %s (Points: %d)", maskMsisdn(potentialJackpotWinner.MSISDN), potentialJackpotWinner.Points))
-	 } else {
-		 draw.ExecutionLog = append(draw.ExecutionLog, "Pool A is empty, cannot select Jackpot Winner.")
-		 draw.JackpotWinnerValidationStatus = models.JackpotValidationNoParticipantsStatus // Use renamed constant
+	 // 5. Bind randomness to the committed seed and the exact participant pools
+	 if draw.Seed == "" {
+		 // Draws scheduled before seed commitment was introduced have no seed yet
+		 draw.Seed, draw.SeedCommitment, err = generateDrawSeed()
+		 if err != nil {
+			 return draw, err
+		 }
+		 draw.ExecutionLog = append(draw.ExecutionLog, "WARN: No seed was committed at scheduling, generated seed at execution time")
 	 }
+	 draw.PoolDigest = computePoolDigest(poolA, poolB)
 
-	 // 6. Validate Jackpot Winner & Handle Rollover
-	 isJackpotWinnerValid := false
-	 if potentialJackpotWinner != nil {
-		 // Check Opt-in status and timing
-		 if potentialJackpotWinner.OptInStatus && !potentialJackpotWinner.OptInDate.IsZero() && potentialJackpotWinner.OptInDate.Before(eligibilityCutoff) {
-			 isJackpotWinnerValid = true
-			 draw.JackpotWinnerValidationStatus = models.JackpotValidationValid
-			 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Jackpot Winner Validated: %s (Opted-in)", maskMsisdn(potentialJackpotWinner.MSISDN)))
-		 } else {
-			 isJackpotWinnerValid = false
-			 draw.JackpotWinnerValidationStatus = models.JackpotValidationInvalidNotOptIn
-			 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Jackpot Winner Invalid: %s (Not Opted-in or Opted-in too late)", maskMsisdn(potentialJackpotWinner.MSISDN)))
-
-			 // --- Trigger Rollover Logic ---
-			 draw.RolloverExecuted = true
-			 rolloverAmount := draw.CalculatedJackpotAmount
-			 var destinationDate time.Time
-			 // Find the next scheduled draw to determine destination date
-			 nextDraw, findNextErr := s.drawRepo.FindNextScheduledDraw(ctx, draw.DrawDate)
-			 if findNextErr != nil {
-				 slog.Error("Failed to find next scheduled draw for rollover destination", "error", findNextErr, "sourceDrawId", draw.ID)
-				 // Fallback: Use simple date logic if next draw isn't found (might be inaccurate if schedule changes)
-				 if draw.DrawType == "SATURDAY" {
-					 destinationDate = draw.DrawDate.AddDate(0, 0, 7)
-				 } else {
-					 daysUntilSaturday := time.Saturday - draw.DrawDate.Weekday()
-					 if daysUntilSaturday <= 0 {
-						 daysUntilSaturday += 7
-					 }
-					 destinationDate = draw.DrawDate.AddDate(0, 0, int(daysUntilSaturday))
-				 }
-				 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("WARN: Could not find next scheduled draw, using calculated date %s for rollover", destinationDate.Format("2006-01-02")))
-			 } else {
-				 destinationDate = nextDraw.DrawDate
-			 }
-
-			 rolloverRecord := &models.JackpotRollover{
-				 SourceDrawID:        draw.ID,
-				 SourceDrawDate:      draw.DrawDate,
-				 RolloverAmount:      rolloverAmount,
-				 DestinationDrawDate: destinationDate,
-				 Reason:              string(models.JackpotValidationInvalidNotOptIn), // Convert status to string
-				 CreatedAt:           time.Now(),
-			 }
-			 // Use 'err' for the rollover creation error, shadowing the outer 'err'
-			 rolloverErr := s.jackpotRolloverRepo.Create(ctx, rolloverRecord)
-			 if rolloverErr != nil {
-				 slog.Error("Failed to create jackpot rollover record", "error", rolloverErr, "sourceDrawId", draw.ID)
-				 // Log error but don't fail the entire draw execution
-				 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("ERROR creating rollover record: %s", rolloverErr.Error()))
-			 } else {
-				 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Rollover Triggered: Amount %.2f to %s", rolloverAmount, destinationDate.Format("2006-01-02")))
-			 }
-		 }
+	 // Persist the participant snapshot before any winner is chosen. It is too large for the
+	 // commit transaction, so a snapshot left by an interrupted attempt is cleared first and
+	 // one left by a failed attempt is discarded below.
+	 err = s.drawParticipantRepo.DeleteByDrawID(execCtx, draw.ID)
+	 if err != nil {
+		 draw.ExecutionLog = append(draw.ExecutionLog, "Failed to clear earlier participant snapshot")
+		 return draw, err
 	 }
+	 snapshot := buildParticipantSnapshot(draw.ID, poolA, poolB, excluded)
+	 err = s.drawParticipantRepo.CreateMany(execCtx, snapshot)
+	 if err != nil {
+		 draw.ExecutionLog = append(draw.ExecutionLog, "Failed to save participant snapshot")
+		 return draw, fmt.Errorf("failed to save participant snapshot: %w", err)
+	 }
+	 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Saved participant snapshot: %d participants, %d excluded", len(snapshot)-len(excluded), len(excluded)))
 
-	 // 7. Select Consolation Winners (Pool B, Points Weighted)
-	 var consolationWinners []*models.Winner
-	 if len(poolB) > 0 {
-		 weightedPoolB := createWeightedPool(poolB)
-		 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Created weighted pool for consolation winners (total weight: %d)", len(weightedPoolB)))
+	 rng, err := newDrawRNG(draw.Seed, draw.PoolDigest)
+	 if err != nil {
+		 return draw, fmt.Errorf("failed to initialise draw randomness: %w", err)
+	 }
+	 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Seed commitment: %s, pool digest: %s", draw.SeedCommitment, draw.PoolDigest))
 
-		 selectedConsolationMSISDNs := make(map[string]bool)
-		 // Ensure Jackpot winner (even if invalid) isn't selected for consolation (REQFUNC039)
-		 if potentialJackpotWinner != nil {
-			 selectedConsolationMSISDNs[potentialJackpotWinner.MSISDN] = true
+	 // 6. Select Jackpot Candidates and Consolation Winners
+	 draw.JackpotAlternateCount = s.getIntConfig(execCtx, jackpotAlternateCountKey, defaultJackpotAlternateCount)
+	 draw.JackpotClaimWindowDays = s.getIntConfig(execCtx, jackpotClaimWindowDaysKey, defaultJackpotClaimWindowDays)
+	 winRules, err := s.GetWinFrequencyRules(execCtx)
+	 if err != nil {
+		 return draw, err
+	 }
+	 draw.WinRules = winRules
+	 draw.WinRuleRejections = nil
+	 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Win rules: max %d wins per %d days, jackpot cooldown %d days, one prize per draw: %t", winRules.MaxWinsPerWindow, winRules.WinWindowDays, winRules.JackpotCooldownDays, winRules.OnePrizePerDraw))
+	 checker := newWinRuleChecker(execCtx, s.winnerRepo, *winRules, draw)
+	 accept := func(user *models.User, category string) (string, error) {
+		 rule, err := checker.accept(user, category)
+		 if rule != "" {
+			 draw.WinRuleRejections = append(draw.WinRuleRejections, models.WinRuleRejection{MSISDN: user.MSISDN, Category: category, Rule: rule})
 		 }
+		 return rule, err
+	 }
+	 selection, err := selectDrawWinners(rng, poolA, poolB, draw.Prizes, draw.JackpotAlternateCount, winRules.OnePrizePerDraw, accept, func(msg string) {
+		 draw.ExecutionLog = append(draw.ExecutionLog, msg)
+	 })
+	 if err != nil {
+		 return draw, err
+	 }
 
-		 for _, prize := range draw.Prizes {
-			 // Corrected: Check against models.JackpotCategory constant
-			 if prize.Category == models.JackpotCategory { // Skip jackpot prize here
-				 continue
+	 // 7. Validate the Jackpot Chain & Handle Rollover
+	 // The first opted-in pick wins; the remaining valid picks stand by in case the winner does not claim.
+	 var jackpotWinner *models.User
+	 var rollover *models.JackpotRollover // Written with the winners in step 10
+	 jackpotWinnerRank := 0
+	 chain := selection.jackpotChain()
+	 for rank, user := range chain {
+		 candidate := models.JackpotCandidate{Rank: rank, UserID: user.ID, MSISDN: user.MSISDN, Points: user.Points, UpdatedAt: time.Now()}
+		 if isJackpotEligible(user, eligibilityCutoff) {
+			 candidate.Status = models.JackpotCandidateValid
+			 if jackpotWinner == nil {
+				 jackpotWinner = user
+				 jackpotWinnerRank = rank
+				 candidate.Status = models.JackpotCandidateAwarded
 			 }
+		 } else {
+			 candidate.Status = models.JackpotCandidateInvalidNotOptIn
+			 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Jackpot Candidate #%d Invalid: %s (Not Opted-in or Opted-in too late)", rank, maskMsisdn(user.MSISDN)))
+		 }
+		 draw.JackpotCandidates = append(draw.JackpotCandidates, candidate)
+	 }
 
-			 for i := 0; i < prize.NumWinners; i++ {
-				 if len(weightedPoolB) == 0 {
-					 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Weighted pool B exhausted while selecting for %s prize", prize.Category))
-					 break // Stop selecting for this prize category
-				 }
-
-				 var consolationWinner *models.User
-				 var selectionErr error
-				 attempts := 0
-				 maxAttempts := len(weightedPoolB) * 2 // Safety break
-
-				 for attempts < maxAttempts {
-					 attempts++
-					 consolationWinner, weightedPoolB, selectionErr = selectWeightedWinner(weightedPoolB)
-					 if selectionErr != nil {
-						 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("ERROR selecting weighted consolation winner for %s: %s", prize.Category, selectionErr.Error()))
-						 // Decide if this is fatal for the prize category or the draw
-						 // For now, log and break inner loop
-						 consolationWinner = nil // Ensure we don't process a nil winner
-						 break
-					 }
-
-					 // Check if already selected or is the jackpot winner
-					 if !selectedConsolationMSISDNs[consolationWinner.MSISDN] {
-						 selectedConsolationMSISDNs[consolationWinner.MSISDN] = true
-						 break // Found a unique winner for this slot
-					 } else {
-						 // Winner already selected, try again (pool was already modified by selectWeightedWinner)
-						 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Re-selected winner %s for %s, trying again...", maskMsisdn(consolationWinner.MSISDN), prize.Category))
-						 consolationWinner = nil // Reset winner for next loop iteration check
-						 if len(weightedPoolB) == 0 {
-							 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Pool B exhausted during re-selection for %s prize", prize.Category))
-							 break
-						 }
-					 }
-				 }
-
-				 if consolationWinner == nil { // If loop finished without finding a unique winner
-					 if selectionErr != nil {
-						 // Error already logged, break outer loop for this prize
-						 break
-					 } else if attempts >= maxAttempts {
-						 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Max attempts reached trying to find unique winner for %s prize", prize.Category))
-						 break // Stop selecting for this prize category
-					 } else {
-						 // Pool likely exhausted
-						 break
-					 }
-				 }
+	 switch {
+	 case len(chain) == 0:
+		 draw.JackpotWinnerValidationStatus = models.JackpotValidationNoParticipantsStatus // Use renamed constant
+	 case jackpotWinner != nil:
+		 draw.JackpotWinnerMsisdn = jackpotWinner.MSISDN
+		 draw.JackpotWinnerValidationStatus = models.JackpotValidationValid
+		 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Jackpot Winner Validated: %s (Opted-in, candidate #%d)", maskMsisdn(jackpotWinner.MSISDN), jackpotWinnerRank))
+	 default:
+		 draw.JackpotWinnerMsisdn = chain[0].MSISDN
+		 draw.JackpotWinnerValidationStatus = models.JackpotValidationInvalidNotOptIn
+		 draw.ExecutionLog = append(draw.ExecutionLog, "No jackpot candidate was opted in, rolling over the jackpot")
+		 rollover = s.newRolloverRecord(execCtx, draw, draw.DrawDate, string(models.JackpotValidationInvalidNotOptIn))
+	 }
 
-				 // Create Winner record
-				 winnerRecord := &models.Winner{
-					 DrawID:       draw.ID,
-					 UserID:       consolationWinner.ID,
-					 MSISDN:       consolationWinner.MSISDN,
-					 PrizeCategory: prize.Category,
-					 PrizeAmount:  prize.Amount,
-					 ClaimStatus:  models.ClaimStatusPending, // Use ClaimStatus
-					 WinDate:      draw.DrawDate,
-					 CreatedAt:    time.Now(),
-					 UpdatedAt:    time.Now(),
-				 }
-				 consolationWinners = append(consolationWinners, winnerRecord)
-				 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Consolation Winner Selected (%s): %s (Points: %d)", prize.Category, maskMsisdn(consolationWinner.MSISDN), consolationWinner.Points))
-			 }
-		 }
-	 } else {
-		 draw.ExecutionLog = append(draw.ExecutionLog, "Pool B is empty, cannot select Consolation Winners.")
+	 // 8. Build Consolation Winner records
+	 var consolationWinners []*models.Winner
+	 for _, pick := range selection.Consolation {
+		 consolationWinners = append(consolationWinners, &models.Winner{
+			 DrawID:        draw.ID,
+			 UserID:        pick.User.ID,
+			 MSISDN:        pick.User.MSISDN,
+			 PrizeCategory: pick.Prize.Category,
+			 PrizeAmount:   pick.Prize.Amount,
+			 ClaimStatus:   models.ClaimStatusPending, // Use ClaimStatus
+			 WinDate:       draw.DrawDate,
+			 CreatedAt:     time.Now(),
+			 UpdatedAt:     time.Now(),
+		 })
 	 }
 
-	 // 8. Save Winners (including valid Jackpot winner if applicable)
+	 // 9. Prepare Winner records (including valid Jackpot winner if applicable)
 	 allWinnersToSave := consolationWinners
-	 if isJackpotWinnerValid && potentialJackpotWinner != nil {
-		 // Find the jackpot prize amount from the draw's prize list
-		 jackpotPrizeAmount := 0.0
-		 for _, p := range draw.Prizes {
-			 if p.Category == models.JackpotCategory {
-				 jackpotPrizeAmount = draw.CalculatedJackpotAmount // Use the calculated amount for the winner
-				 break
-			 }
-		 }
-		 if jackpotPrizeAmount == 0.0 {
+	 if jackpotWinner != nil {
+		 jackpotWinnerRecord := s.newJackpotWinnerRecord(draw, jackpotWinner, jackpotWinnerRank, time.Now())
+		 if jackpotWinnerRecord == nil {
 			 // This shouldn't happen if scheduling is correct, but handle defensively
 			 slog.Error("Jackpot prize category not found in draw prizes", "drawId", draw.ID)
-			 // Decide how to handle: fail draw or just log? For now, log and continue without saving jackpot winner.
 			 draw.ExecutionLog = append(draw.ExecutionLog, "ERROR: Jackpot prize category missing, cannot save jackpot winner record.")
 		 } else {
-			 jackpotWinnerRecord := &models.Winner{
-				 DrawID:       draw.ID,
-				 UserID:       potentialJackpotWinner.ID,
-				 MSISDN:       potentialJackpotWinner.MSISDN,
-				 PrizeCategory: models.JackpotCategory,
-				 PrizeAmount:  jackpotPrizeAmount,
-				 ClaimStatus:  models.ClaimStatusPending,
-				 WinDate:      draw.DrawDate,
-				 CreatedAt:    time.Now(),
-				 UpdatedAt:    time.Now(),
-			 }
+			 draw.JackpotCandidates[jackpotWinnerRank].WinnerID = jackpotWinnerRecord.ID
 			 allWinnersToSave = append(allWinnersToSave, jackpotWinnerRecord)
-			 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Jackpot Winner Record Prepared: %s, Amount: %.2f", maskMsisdn(potentialJackpotWinner.MSISDN), jackpotPrizeAmount))
+			 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Jackpot Winner Record Prepared: %s, Amount: %.2f, Claim by: %s", maskMsisdn(jackpotWinner.MSISDN), jackpotWinnerRecord.PrizeAmount, jackpotWinnerRecord.ClaimDeadline.Format(time.RFC3339)))
+		 }
+	 }
+
+	 // 10. Commit the outcome: rollover, winners and the COMPLETED status are written atomically
+	 if cause := context.Cause(execCtx); cause != nil {
+		 return draw, cause
+	 }
+	 err = s.commitDrawOutcome(execCtx, draw, rollover, allWinnersToSave)
+	 if err != nil {
+		 return draw, err
+	 }
+	 slog.Info("ExecuteDraw: Execution completed", "drawId", drawID)
+	 s.recordDrawAudit(execCtx, models.AuditActionDrawExecuted, &scheduled, draw, nil)
+	 return draw, nil
+}
+
+
+// VerifyDraw re-runs the winner selection of an executed draw from its revealed seed and
+// the participant pools, and checks the outcome against the recorded winners.
+func (s *DrawServiceImpl) VerifyDraw(ctx context.Context, drawID primitive.ObjectID) (*models.DrawVerification, error) {
+	 draw, err := s.drawRepo.FindByID(ctx, drawID)
+	 if err != nil {
+		 if errors.Is(err, mongo.ErrNoDocuments) {
+			 return nil, fmt.Errorf("draw with ID %s not found", drawID.Hex())
 		 }
+		 return nil, fmt.Errorf("failed to fetch draw by ID: %w", err)
+	 }
+	 if draw.Status != models.DrawStatusCompleted || draw.RevealedSeed == "" {
+		 return nil, fmt.Errorf("draw %s has not completed or its seed has not been revealed", drawID.Hex())
+	 }
+
+	 result := &models.DrawVerification{
+		 DrawID:           draw.ID,
+		 SeedCommitment:   draw.SeedCommitment,
+		 RevealedSeed:     draw.RevealedSeed,
+		 CommitmentValid:  verifySeedCommitment(draw.RevealedSeed, draw.SeedCommitment),
+		 StoredPoolDigest: draw.PoolDigest,
+	 }
+	 if !result.CommitmentValid {
+		 result.Notes = append(result.Notes, "Revealed seed does not match the published commitment")
 	 }
 
-	 if len(allWinnersToSave) > 0 {
-		 // Use CreateMany for efficiency
-		 createWinnersErr := s.winnerRepo.CreateMany(ctx, allWinnersToSave)
-		 if createWinnersErr != nil {
-			 // This is a significant error, fail the draw
-			 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("ERROR saving winner records: %s", createWinnersErr.Error()))
-			 return draw, fmt.Errorf("failed to save winner records: %w", createWinnersErr)
+	 // Rebuild the pools from the participant snapshot, falling back to the live
+	 // pools for draws executed before snapshots were recorded
+	 poolA, poolB, err := s.loadParticipantPools(ctx, draw.ID)
+	 if err != nil {
+		 return nil, err
+	 }
+	 if len(poolA) == 0 && len(poolB) == 0 {
+		 result.Notes = append(result.Notes, "No participant snapshot recorded, replay uses the current pools")
+		 eligibilityStart, eligibilityCutoff, windowErr := drawEligibilityWindow(draw)
+		 if windowErr != nil {
+			 return nil, windowErr
 		 }
-		 draw.NumWinners = len(allWinnersToSave)
-		 draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Successfully saved %d winner records", len(allWinnersToSave)))
-	 } else {
-		 draw.ExecutionLog = append(draw.ExecutionLog, "No winners selected or eligible to be saved.")
+		 poolA, poolB, _, err = fetchDrawPools(ctx, s.userRepo, s.blacklistRepo, draw, eligibilityStart, eligibilityCutoff)
+		 if err != nil {
+			 return nil, err
+		 }
+	 }
+	 sortPoolByMSISDN(poolA)
+	 sortPoolByMSISDN(poolB)
+	 result.ReplayPoolDigest = computePoolDigest(poolA, poolB)
+	 result.PoolDigestMatch = result.ReplayPoolDigest == draw.PoolDigest
+	 if !result.PoolDigestMatch {
+		 result.Notes = append(result.Notes, "Replayed participant pools do not match the pool digest recorded at execution")
+	 }
+
+	 rng, err := newDrawRNG(draw.RevealedSeed, draw.PoolDigest)
+	 if err != nil {
+		 return nil, fmt.Errorf("failed to initialise draw randomness: %w", err)
 	 }
+	 // Picks refused by the win rules are replayed as recorded, the win history has moved on since
+	 if len(draw.WinRuleRejections) > 0 {
+		 result.Notes = append(result.Notes, fmt.Sprintf("Replay applies the %d win rule refusals recorded at execution", len(draw.WinRuleRejections)))
+	 }
+	 selection, err := selectDrawWinners(rng, poolA, poolB, draw.Prizes, draw.JackpotAlternateCount, drawOnePrizePerDraw(draw), replayWinRuleRejections(draw.WinRuleRejections), func(string) {})
+	 if err != nil {
+		 return nil, err
+	 }
+	 result.ExpectedWinners = selectionWinnerKeys(selection)
+
+	 winners, err := s.winnerRepo.FindByDrawID(ctx, draw.ID)
+	 if err != nil {
+		 return nil, fmt.Errorf("failed to retrieve winners for draw %s: %w", draw.ID.Hex(), err)
+	 }
+	 result.RecordedWinners = recordedWinnerKeys(draw, winners)
+	 result.WinnersMatch = equalStringSets(result.ExpectedWinners, result.RecordedWinners)
+	 if !result.WinnersMatch {
+		 result.Notes = append(result.Notes, "Replayed winners differ from the recorded winners")
+	 }
+
+	 result.Verified = result.CommitmentValid && result.PoolDigestMatch && result.WinnersMatch
+	 slog.Info("Draw verification completed", "drawId", draw.ID, "verified", result.Verified)
+	 return result, nil
+}
+
+// GetDrawParticipants returns one page of a draw's participant snapshot and the total snapshot size
+func (s *DrawServiceImpl) GetDrawParticipants(ctx context.Context, drawID primitive.ObjectID, page, limit int) ([]*models.DrawParticipant, int64, error) {
+	 participants, err := s.drawParticipantRepo.FindByDrawID(ctx, drawID, page, limit)
+	 if err != nil {
+		 slog.Error("Failed to get draw participants", "error", err, "drawId", drawID)
+		 return nil, 0, fmt.Errorf("failed to retrieve participants for draw %s: %w", drawID.Hex(), err)
+	 }
+	 total, err := s.drawParticipantRepo.CountByDrawID(ctx, drawID)
+	 if err != nil {
+		 slog.Error("Failed to count draw participants", "error", err, "drawId", drawID)
+		 return nil, 0, fmt.Errorf("failed to count participants for draw %s: %w", drawID.Hex(), err)
+	 }
+	 return participants, total, nil
+}
 
-	 // 9. Final status update is handled by the deferred function
-	 return draw, nil // err will be nil here if execution reached the end without errors
+// ExportDrawParticipants streams a draw's full participant snapshot to fn
+func (s *DrawServiceImpl) ExportDrawParticipants(ctx context.Context, drawID primitive.ObjectID, fn func(*models.DrawParticipant) error) error {
+	 if err := s.drawParticipantRepo.IterateByDrawID(ctx, drawID, fn); err != nil {
+		 slog.Error("Failed to export draw participants", "error", err, "drawId", drawID)
+		 return fmt.Errorf("failed to export participants for draw %s: %w", drawID.Hex(), err)
+	 }
+	 return nil
 }
 
+// loadParticipantPools rebuilds Pool A and Pool B from a draw's participant snapshot
+func (s *DrawServiceImpl) loadParticipantPools(ctx context.Context, drawID primitive.ObjectID) ([]*models.User, []*models.User, error) {
+	 var poolA, poolB []*models.User
+	 err := s.drawParticipantRepo.IterateByDrawID(ctx, drawID, func(p *models.DrawParticipant) error {
+		 if p.ExclusionReason != "" {
+			 return nil // Recorded for the audit trail, never took part in selection
+		 }
+		 user := &models.User{ID: p.UserID, MSISDN: p.MSISDN, Points: p.Points, OptInStatus: p.OptInStatus, OptInDate: p.OptInDate}
+		 if p.InJackpotPool {
+			 poolA = append(poolA, user)
+		 }
+		 if p.InConsolationPool {
+			 poolB = append(poolB, user)
+		 }
+		 return nil
+	 })
+	 if err != nil {
+		 return nil, nil, fmt.Errorf("failed to load participant snapshot: %w", err)
+	 }
+	 return poolA, poolB, nil
+}
 
 // --- Helper & Getter Methods ---
 
-// GetPrizeStructure retrieves the prize structure for a given draw type from system config
+// GetPrizeStructure retrieves the prize structure of a registered draw type in force today
 func (s *DrawServiceImpl) GetPrizeStructure(ctx context.Context, drawType string) ([]models.Prize, error) {
-	 configKey := "prize_structure_" + strings.ToUpper(drawType)
+	 version, err := s.GetEffectivePrizeStructure(ctx, drawType, time.Now().UTC())
+	 if err != nil {
+		 return nil, err
+	 }
+	 return version.Prizes, nil
+}
+
+// prizeStructureByKey retrieves a prize structure stored under a system config key
+func (s *DrawServiceImpl) prizeStructureByKey(ctx context.Context, configKey string) ([]models.Prize, error) {
 	 config, err := s.systemConfigRepo.FindByKey(ctx, configKey)
 	 if err != nil {
 		 slog.Error("Failed to fetch prize structure config", "error", err, "key", configKey)
@@ -484,26 +639,54 @@ func (s *DrawServiceImpl) GetPrizeStructure(ctx context.Context, drawType string
 	 return prizes, nil
 }
 
-// UpdatePrizeStructure updates the prize structure in system config
-func (s *DrawServiceImpl) UpdatePrizeStructure(ctx context.Context, drawType string, structure []models.Prize) error {
-	 configKey := "prize_structure_" + strings.ToUpper(drawType)
-
-	 // Marshal the structure back to JSON string
-	 jsonBytes, err := json.Marshal(structure)
+// UpdateBaseJackpot sets the base jackpot of a registered draw type. Draws already scheduled
+// keep the jackpot they were scheduled with.
+func (s *DrawServiceImpl) UpdateBaseJackpot(ctx context.Context, drawType string, amount float64) error {
+	 if amount <= 0 {
+		 return fmt.Errorf("base jackpot must be positive (got %.2f)", amount)
+	 }
+	 drawTypeDef, err := s.drawTypeDefinition(ctx, drawType)
 	 if err != nil {
-		 slog.Error("Failed to marshal prize structure to JSON", "error", err, "key", configKey)
-		 return fmt.Errorf("failed to marshal prize structure for %s: %w", configKey, err)
+		 return err
 	 }
+	 configKey := drawTypeDef.BaseJackpotKey
+	 before := map[string]interface{}{}
+	 if previous, err := s.systemConfigRepo.FindByKey(ctx, configKey); err == nil {
+		 before["amount"] = previous.Value
+	 }
+	 if err := s.systemConfigRepo.UpsertByKey(ctx, configKey, amount); err != nil {
+		 slog.Error("Failed to upsert base jackpot config", "error", err, "key", configKey)
+		 return fmt.Errorf("failed to save base jackpot config %s: %w", configKey, err)
+	 }
+	 slog.Info("Base jackpot updated successfully", "key", configKey, "amount", amount)
+	 s.audit.Record(ctx, &models.AuditEvent{
+		 Action:     models.AuditActionBaseJackpotUpdated,
+		 TargetType: models.AuditTargetDrawType,
+		 TargetID:   drawTypeDef.Code,
+		 Changes:    auditChanges(before, map[string]interface{}{"amount": amount}),
+	 })
+	 return nil
+}
 
-	 // Upsert the JSON string into the config
-	 err = s.systemConfigRepo.UpsertByKey(ctx, configKey, string(jsonBytes))
+// getIntConfig reads a numeric SystemConfig value, falling back to def when it is missing or malformed
+func (s *DrawServiceImpl) getIntConfig(ctx context.Context, key string, def int) int {
+	 config, err := s.systemConfigRepo.FindByKey(ctx, key)
 	 if err != nil {
-		 slog.Error("Failed to upsert prize structure config", "error", err, "key", configKey)
-		 return fmt.Errorf("failed to save prize structure config %s: %w", configKey, err)
+		 return def
+	 }
+	 switch v := config.Value.(type) {
+	 case int32:
+		 return int(v)
+	 case int64:
+		 return int(v)
+	 case int:
+		 return v
+	 case float64:
+		 return int(v)
+	 default:
+		 slog.Warn("Invalid numeric config value, using default", "key", key, "valueType", fmt.Sprintf("%T", config.Value), "default", def)
+		 return def
 	 }
-
-	 slog.Info("Prize structure updated successfully", "key", configKey)
-	 return nil
 }
 
 // GetWinnersByDrawID retrieves all winners for a specific draw
@@ -570,37 +753,56 @@ func (s *DrawServiceImpl) GetDrawByID(ctx context.Context, drawID primitive.Obje
 }
 
 
-// GetJackpotStatus retrieves the current jackpot status
-func (s *DrawServiceImpl) GetJackpotStatus(ctx context.Context) (*models.JackpotStatus, error) {
-	 slog.Info("Fetching current jackpot status")
+// GetJackpotStatus retrieves the current jackpot status of a draw type, or of the headline
+// draw type when drawType is empty
+func (s *DrawServiceImpl) GetJackpotStatus(ctx context.Context, drawType string) (*models.JackpotStatus, error) {
+	 slog.Info("Fetching current jackpot status", "drawType", drawType)
 	 now := time.Now()
+
+	 var drawTypeDef *models.DrawTypeDefinition
+	 var err error
+	 if drawType == "" {
+		 drawTypeDef, err = s.headlineDrawType(ctx)
+	 } else {
+		 drawTypeDef, err = s.drawTypeDefinition(ctx, drawType)
+	 }
+	 if err != nil {
+		 return nil, err
+	 }
 	 status := &models.JackpotStatus{
+		 DrawType:      drawTypeDef.Code,
 		 LastUpdatedAt: now,
 	 }
 
-	 // 1. Find the latest completed Saturday draw
-	 latestSaturdayDraw, err := s.drawRepo.FindLatestDrawByTypeAndStatus(ctx, "SATURDAY", []string{string(models.DrawStatusCompleted)})
+	 // 1. Find the latest completed draw of the type
+	 latestDraw, err := s.drawRepo.FindLatestDrawByTypeAndStatus(ctx, drawTypeDef.Code, []string{string(models.DrawStatusCompleted)})
 	 if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
-		 slog.Error("GetJackpotStatus: Failed to find latest completed Saturday draw", "error", err)
-		 return nil, fmt.Errorf("failed to find latest completed Saturday draw: %w", err)
+		 slog.Error("GetJackpotStatus: Failed to find latest completed draw", "error", err, "drawType", drawTypeDef.Code)
+		 return nil, fmt.Errorf("failed to find latest completed %s draw: %w", drawTypeDef.Code, err)
 	 }
 
 	 // 2. If a draw is found, get its details and winner
-	 if latestSaturdayDraw != nil {
-		 status.LastDrawDate = latestSaturdayDraw.DrawDate
+	 if latestDraw != nil {
+		 status.LastDrawDate = latestDraw.DrawDate
 		 // Find the jackpot winner for that draw
-		 winners, findWinnerErr := s.winnerRepo.FindByDrawIDAndCategory(ctx, latestSaturdayDraw.ID, models.JackpotCategory)
+		 winners, findWinnerErr := s.winnerRepo.FindByDrawIDAndCategory(ctx, latestDraw.ID, models.JackpotCategory)
 		 if findWinnerErr != nil && !errors.Is(findWinnerErr, mongo.ErrNoDocuments) {
-			 slog.Error("GetJackpotStatus: Failed to find jackpot winner for last draw", "error", findWinnerErr, "drawId", latestSaturdayDraw.ID)
+			 slog.Error("GetJackpotStatus: Failed to find jackpot winner for last draw", "error", findWinnerErr, "drawId", latestDraw.ID)
 			 // Continue, but status will lack winner info
-		 } else if len(winners) > 0 {
-			 // Assuming only one jackpot winner per draw
-			 status.LastWinnerMSISDN = winners[0].MSISDN
-			 status.LastWinAmount = winners[0].PrizeAmount
+		 } else {
+			 // Forfeited winners were replaced by an alternate (or the jackpot rolled over)
+			 for _, winner := range winners {
+				 if winner.ClaimStatus == models.ClaimStatusForfeited {
+					 continue
+				 }
+				 status.LastWinnerMSISDN = winner.MSISDN
+				 status.LastWinAmount = winner.PrizeAmount
+				 break
+			 }
 		 }
 	 }
 
-	 // 3. Find pending rollovers effective today
+	 // 3. Find pending rollovers effective today that go to a draw of this type
 	 pendingRollovers, err := s.jackpotRolloverRepo.FindPendingRollovers(ctx, now)
 	 accumulatedRollover := 0.0
 	 if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
@@ -608,12 +810,14 @@ func (s *DrawServiceImpl) GetJackpotStatus(ctx context.Context) (*models.Jackpot
 		 // Continue, but current amount might be inaccurate
 	 } else if err == nil {
 		 for _, rollover := range pendingRollovers {
-			 accumulatedRollover += rollover.RolloverAmount
+			 if s.rolloverTargetsType(ctx, rollover, drawTypeDef) {
+				 accumulatedRollover += rollover.RolloverAmount
+			 }
 		 }
 	 }
 
-	 // 4. Get base jackpot amount from config (assuming Saturday for current status)
-	 baseJackpotKey := "base_jackpot_SATURDAY"
+	 // 4. Get base jackpot amount of the draw type from config
+	 baseJackpotKey := drawTypeDef.BaseJackpotKey
 	 baseJackpotConfig, err := s.systemConfigRepo.FindByKey(ctx, baseJackpotKey)
 	 baseJackpotAmount := 0.0
 	 if err != nil {
@@ -632,8 +836,8 @@ func (s *DrawServiceImpl) GetJackpotStatus(ctx context.Context) (*models.Jackpot
 	 // 5. Calculate current jackpot amount
 	 status.CurrentAmount = baseJackpotAmount + accumulatedRollover
 
-	 // 6. Find the next scheduled draw date (any type)
-	 nextDraw, err := s.drawRepo.FindNextScheduledDraw(ctx, now)
+	 // 6. Find the next scheduled draw date of the type
+	 nextDraw, err := s.drawRepo.FindNextScheduledDrawByType(ctx, drawTypeDef.Code, now)
 	 if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
 		 slog.Error("GetJackpotStatus: Failed to find next scheduled draw", "error", err)
 		 // Continue, but status will lack next draw date
@@ -648,58 +852,339 @@ func (s *DrawServiceImpl) GetJackpotStatus(ctx context.Context) (*models.Jackpot
 // --- Utility functions specific to DrawService ---
 
 
-// createWeightedPool creates a slice where each user is repeated based on their points
-func createWeightedPool(users []*models.User) []*models.User {
-	 totalWeight := 0
-	 for _, user := range users {
-		 // Ensure minimum 1 entry even if points are 0 or negative
-		 weight := user.Points
-		 if weight <= 0 {
-			 weight = 1
+// drawPoolReader is the read-only part of UserRepository needed to build a draw's pools
+type drawPoolReader interface {
+	FindUsersByRechargeWindow(ctx context.Context, startTime, endTime time.Time) ([]*models.User, error)
+	FindEligibleConsolationUsers(ctx context.Context, digits []int, optInCutoff, rechargeStart, rechargeEnd time.Time) ([]*models.User, error)
+}
+
+// blacklistReader is the read-only part of BlacklistRepository needed to build a draw's pools
+type blacklistReader interface {
+	FindAll(ctx context.Context) ([]*models.BlacklistEntry, error)
+}
+
+// poolExclusion is a user removed from a draw's pools before selection
+type poolExclusion struct {
+	User              *models.User
+	Reason            string
+	InJackpotPool     bool
+	InConsolationPool bool
+}
+
+// fetchDrawPools loads a draw's participant pools, sorted by MSISDN as selection requires.
+// Pool A (Jackpot) holds every user with any recharge in the window; Pool B (Consolation)
+// holds opted-in users meeting all criteria. Blacklisted users are removed from both pools
+// and returned as exclusions, ordered by MSISDN.
+func fetchDrawPools(ctx context.Context, users drawPoolReader, blacklist blacklistReader, draw *models.Draw, eligibilityStart, eligibilityCutoff time.Time) ([]*models.User, []*models.User, []*poolExclusion, error) {
+	 poolA, err := users.FindUsersByRechargeWindow(ctx, eligibilityStart, eligibilityCutoff)
+	 if err != nil {
+		 return nil, nil, nil, fmt.Errorf("failed to fetch jackpot participant pool: %w", err)
+	 }
+	 poolB, err := users.FindEligibleConsolationUsers(ctx, draw.EligibleDigits, eligibilityCutoff, eligibilityStart, eligibilityCutoff)
+	 if err != nil {
+		 return nil, nil, nil, fmt.Errorf("failed to fetch consolation participant pool: %w", err)
+	 }
+	 entries, err := blacklist.FindAll(ctx)
+	 if err != nil {
+		 return nil, nil, nil, fmt.Errorf("failed to fetch blacklist: %w", err)
+	 }
+	 blacklisted := make(map[string]bool, len(entries))
+	 for _, entry := range entries {
+		 blacklisted[entry.MSISDN] = true
+	 }
+	 sortPoolByMSISDN(poolA)
+	 sortPoolByMSISDN(poolB)
+	 poolA, poolB, excluded := excludeBlacklisted(poolA, poolB, blacklisted)
+	 return poolA, poolB, excluded, nil
+}
+
+// excludeBlacklisted removes users on the blacklist, or flagged as blacklisted on their
+// profile, from both pools. The pools keep their order.
+func excludeBlacklisted(poolA, poolB []*models.User, blacklisted map[string]bool) ([]*models.User, []*models.User, []*poolExclusion) {
+	 byMSISDN := make(map[string]*poolExclusion)
+	 var excluded []*poolExclusion
+	 filter := func(pool []*models.User, mark func(*poolExclusion)) []*models.User {
+		 kept := make([]*models.User, 0, len(pool))
+		 for _, user := range pool {
+			 if !user.IsBlacklisted && !blacklisted[user.MSISDN] {
+				 kept = append(kept, user)
+				 continue
+			 }
+			 exclusion, ok := byMSISDN[user.MSISDN]
+			 if !ok {
+				 exclusion = &poolExclusion{User: user, Reason: models.ExclusionReasonBlacklisted}
+				 byMSISDN[user.MSISDN] = exclusion
+				 excluded = append(excluded, exclusion)
+			 }
+			 mark(exclusion)
 		 }
-		 totalWeight += weight
+		 return kept
 	 }
+	 poolA = filter(poolA, func(e *poolExclusion) { e.InJackpotPool = true })
+	 poolB = filter(poolB, func(e *poolExclusion) { e.InConsolationPool = true })
+	 sort.SliceStable(excluded, func(i, j int) bool { return excluded[i].User.MSISDN < excluded[j].User.MSISDN })
+	 return poolA, poolB, excluded
+}
 
-	 if totalWeight == 0 {
-		 return []*models.User{} // Return empty pool if no users or all have <= 0 points
+// exclusionPools names the pools a user was excluded from, for the execution log
+func exclusionPools(exclusion *poolExclusion) string {
+	 switch {
+	 case exclusion.InJackpotPool && exclusion.InConsolationPool:
+		 return "Pool A and Pool B"
+	 case exclusion.InJackpotPool:
+		 return "Pool A"
+	 default:
+		 return "Pool B"
 	 }
+}
 
-	 weightedPool := make([]*models.User, 0, totalWeight)
-	 for _, user := range users {
-		 weight := user.Points
-		 if weight <= 0 {
-			 weight = 1
+// buildParticipantSnapshot merges both pools into one snapshot entry per user, followed by
+// the users excluded from the pools
+func buildParticipantSnapshot(drawID primitive.ObjectID, poolA, poolB []*models.User, excluded []*poolExclusion) []*models.DrawParticipant {
+	 byUser := make(map[primitive.ObjectID]*models.DrawParticipant, len(poolA))
+	 snapshot := make([]*models.DrawParticipant, 0, len(poolA))
+	 add := func(user *models.User) *models.DrawParticipant {
+		 if p, ok := byUser[user.ID]; ok {
+			 return p
 		 }
-		 for i := 0; i < weight; i++ {
-			 weightedPool = append(weightedPool, user)
+		 p := &models.DrawParticipant{
+			 DrawID:      drawID,
+			 UserID:      user.ID,
+			 MSISDN:      user.MSISDN,
+			 Points:      user.Points,
+			 OptInStatus: user.OptInStatus,
+			 OptInDate:   user.OptInDate,
 		 }
+		 byUser[user.ID] = p
+		 snapshot = append(snapshot, p)
+		 return p
+	 }
+	 for _, user := range poolA {
+		 add(user).InJackpotPool = true
+	 }
+	 for _, user := range poolB {
+		 add(user).InConsolationPool = true
+	 }
+	 for _, exclusion := range excluded {
+		 p := add(exclusion.User)
+		 p.InJackpotPool = exclusion.InJackpotPool
+		 p.InConsolationPool = exclusion.InConsolationPool
+		 p.ExclusionReason = exclusion.Reason
 	 }
-	 return weightedPool
+	 return snapshot
 }
 
-// selectWeightedWinner selects a winner randomly from a weighted pool and returns the winner
-// and the pool with the winner removed.
-func selectWeightedWinner(weightedPool []*models.User) (*models.User, []*models.User, error) {
-	 if len(weightedPool) == 0 {
-		 return nil, weightedPool, errors.New("cannot select winner from empty pool")
+// jackpotChainKey labels a jackpot chain entry: "JACKPOT" for the first pick, "JACKPOT_ALT_n" for alternates
+func jackpotChainKey(rank int, msisdn string) string {
+	 if rank == 0 {
+		 return models.JackpotCategory + ":" + msisdn
 	 }
+	 return fmt.Sprintf("%s_ALT_%d:%s", models.JackpotCategory, rank, msisdn)
+}
 
-	 // Seed random number generator (should ideally be done once at application start)
-	 // rand.Seed(time.Now().UnixNano()) // Deprecated since Go 1.20
-	 // Use crypto/rand for better randomness if needed, or default rand is okay for non-security critical selection.
+// selectionWinnerKeys lists the picks of a selection as "CATEGORY:MSISDN", the jackpot chain first
+func selectionWinnerKeys(selection *drawSelection) []string {
+	 keys := []string{}
+	 for rank, user := range selection.jackpotChain() {
+		 keys = append(keys, jackpotChainKey(rank, user.MSISDN))
+	 }
+	 for _, pick := range selection.Consolation {
+		 keys = append(keys, pick.Prize.Category+":"+pick.User.MSISDN)
+	 }
+	 return keys
+}
 
-	 winnerIndex := rand.Intn(len(weightedPool))
-	 winner := weightedPool[winnerIndex]
+// recordedWinnerKeys lists the winners recorded for a draw as "CATEGORY:MSISDN". The jackpot
+// chain comes from the draw itself since invalid or standby picks have no Winner record.
+func recordedWinnerKeys(draw *models.Draw, winners []*models.Winner) []string {
+	 keys := []string{}
+	 if len(draw.JackpotCandidates) > 0 {
+		 for _, candidate := range draw.JackpotCandidates {
+			 keys = append(keys, jackpotChainKey(candidate.Rank, candidate.MSISDN))
+		 }
+	 } else if draw.JackpotWinnerMsisdn != "" {
+		 keys = append(keys, models.JackpotCategory+":"+draw.JackpotWinnerMsisdn)
+	 }
+	 for _, w := range winners {
+		 if w.PrizeCategory == models.JackpotCategory {
+			 continue
+		 }
+		 keys = append(keys, w.PrizeCategory+":"+w.MSISDN)
+	 }
+	 return keys
+}
 
-	 // Create a new pool excluding all entries for the selected winner
-	 remainingPool := make([]*models.User, 0, len(weightedPool))
-	 for _, user := range weightedPool {
-		 if user.MSISDN != winner.MSISDN {
-			 remainingPool = append(remainingPool, user)
+// equalStringSets reports whether a and b contain the same strings, ignoring order
+func equalStringSets(a, b []string) bool {
+	 if len(a) != len(b) {
+		 return false
+	 }
+	 counts := make(map[string]int, len(a))
+	 for _, v := range a {
+		 counts[v]++
+	 }
+	 for _, v := range b {
+		 counts[v]--
+		 if counts[v] < 0 {
+			 return false
+		 }
+	 }
+	 return true
+}
+
+// consolationPick is a single non-jackpot prize awarded during selection
+type consolationPick struct {
+	 Prize models.Prize
+	 User  *models.User
+}
+
+// drawSelection holds the outcome of the random selection step of a draw
+type drawSelection struct {
+	 JackpotCandidate  *models.User   // Weighted pick from Pool A, validated separately
+	 JackpotAlternates []*models.User // Backup picks from Pool A, in order
+	 Consolation       []consolationPick
+}
+
+// jackpotChain returns the jackpot pick followed by its alternates
+func (d *drawSelection) jackpotChain() []*models.User {
+	 if d.JackpotCandidate == nil {
+		 return nil
+	 }
+	 return append([]*models.User{d.JackpotCandidate}, d.JackpotAlternates...)
+}
+
+// selectDrawWinners performs all random choices of a draw. Every pick is offered to accept,
+// which applies the win-frequency rules; refused picks are skipped and the next one drawn.
+// It has no side effects other than calling logf and accept, so the same seed, pools and
+// accept decisions always yield the same selection; ExecuteDraw and VerifyDraw both rely on this.
+// With onePrizePerDraw, jackpot picks and alternates are kept out of the consolation prizes.
+func selectDrawWinners(rng *drawRNG, poolA, poolB []*models.User, prizes []models.Prize, numAlternates int, onePrizePerDraw bool, accept pickFilter, logf func(string)) (*drawSelection, error) {
+	 selection := &drawSelection{}
+
+	 // Jackpot: points-weighted selection from Pool A (REQFUNC027)
+	 if len(poolA) > 0 {
+		 samplerA := newWeightedSampler(poolA)
+		 logf(fmt.Sprintf("Created weighted pool for jackpot winner (total weight: %d)", samplerA.TotalWeight()))
+		 // pickJackpot draws from Pool A until a pick is accepted; nil once the pool is exhausted
+		 pickJackpot := func() (*models.User, error) {
+			 for samplerA.Len() > 0 {
+				 user, err := samplerA.Pick(rng)
+				 if err != nil {
+					 return nil, err
+				 }
+				 rule, err := accept(user, models.JackpotCategory)
+				 if err != nil {
+					 return nil, err
+				 }
+				 if rule == "" {
+					 return user, nil
+				 }
+				 logf(fmt.Sprintf("Jackpot pick %s refused by win rule %s", maskMsisdn(user.MSISDN), rule))
+			 }
+			 return nil, nil
+		 }
+		 winner, err := pickJackpot()
+		 if err != nil {
+			 logf(fmt.Sprintf("ERROR selecting weighted jackpot winner: %s", err.Error()))
+			 return nil, fmt.Errorf("failed to select weighted jackpot winner: %w", err)
+		 }
+		 if winner == nil {
+			 logf("Every Pool A pick was refused by the win rules, cannot select Jackpot Winner.")
+		 } else {
+			 selection.JackpotCandidate = winner
+			 logf(fmt.Sprintf("Potential Jackpot Winner Selected (Weighted): %s (Points: %d)", maskMsisdn(winner.MSISDN), winner.Points))
+			 for i := 1; i <= numAlternates; i++ {
+				 alternate, err := pickJackpot()
+				 if err != nil {
+					 logf(fmt.Sprintf("ERROR selecting jackpot alternate: %s", err.Error()))
+					 return nil, fmt.Errorf("failed to select jackpot alternate: %w", err)
+				 }
+				 if alternate == nil {
+					 break
+				 }
+				 selection.JackpotAlternates = append(selection.JackpotAlternates, alternate)
+				 logf(fmt.Sprintf("Jackpot Alternate #%d Selected (Weighted): %s (Points: %d)", i, maskMsisdn(alternate.MSISDN), alternate.Points))
+			 }
+		 }
+	 } else {
+		 logf("Pool A is empty, cannot select Jackpot Winner.")
+	 }
+
+	 // Consolation: points-weighted selection from Pool B
+	 if len(poolB) == 0 {
+		 logf("Pool B is empty, cannot select Consolation Winners.")
+		 return selection, nil
+	 }
+	 samplerB := newWeightedSampler(poolB)
+	 logf(fmt.Sprintf("Created weighted pool for consolation winners (total weight: %d)", samplerB.TotalWeight()))
+
+	 selectedMSISDNs := make(map[string]bool)
+	 if onePrizePerDraw {
+		 // Ensure Jackpot winner and alternates (even if invalid) aren't selected for consolation (REQFUNC039)
+		 for _, user := range selection.jackpotChain() {
+			 selectedMSISDNs[user.MSISDN] = true
+		 }
+	 }
+
+	 for _, prize := range prizes {
+		 if prize.Category == models.JackpotCategory { // Skip jackpot prize here
+			 continue
+		 }
+
+		 for i := 0; i < prize.NumWinners; i++ {
+			 if samplerB.Len() == 0 {
+				 logf(fmt.Sprintf("Weighted pool B exhausted while selecting for %s prize", prize.Category))
+				 break // Stop selecting for this prize category
+			 }
+
+			 var winner *models.User
+			 var err error
+			 attempts := 0
+			 maxAttempts := samplerB.Len() * 2 // Safety break
+
+			 for attempts < maxAttempts {
+				 attempts++
+				 winner, err = samplerB.Pick(rng)
+				 if err != nil {
+					 logf(fmt.Sprintf("ERROR selecting weighted consolation winner for %s: %s", prize.Category, err.Error()))
+					 winner = nil
+					 break
+				 }
+				 if !selectedMSISDNs[winner.MSISDN] {
+					 rule, acceptErr := accept(winner, prize.Category)
+					 if acceptErr != nil {
+						 logf(fmt.Sprintf("ERROR applying win rules for %s: %s", prize.Category, acceptErr.Error()))
+						 return nil, fmt.Errorf("failed to apply win rules: %w", acceptErr)
+					 }
+					 if rule == "" {
+						 selectedMSISDNs[winner.MSISDN] = true
+						 break // Found a unique winner for this slot
+					 }
+					 logf(fmt.Sprintf("Pick %s for %s refused by win rule %s, trying again...", maskMsisdn(winner.MSISDN), prize.Category, rule))
+				 } else {
+					 // Winner already selected, try again (the pick was already removed from the sampler)
+					 logf(fmt.Sprintf("Re-selected winner %s for %s, trying again...", maskMsisdn(winner.MSISDN), prize.Category))
+				 }
+				 winner = nil
+				 if samplerB.Len() == 0 {
+					 logf(fmt.Sprintf("Pool B exhausted during re-selection for %s prize", prize.Category))
+					 break
+				 }
+			 }
+
+			 if winner == nil {
+				 if err == nil && attempts >= maxAttempts {
+					 logf(fmt.Sprintf("Max attempts reached trying to find unique winner for %s prize", prize.Category))
+				 }
+				 break // Stop selecting for this prize category
+			 }
+
+			 selection.Consolation = append(selection.Consolation, consolationPick{Prize: prize, User: winner})
+			 logf(fmt.Sprintf("Consolation Winner Selected (%s): %s (Points: %d)", prize.Category, maskMsisdn(winner.MSISDN), winner.Points))
 		 }
 	 }
 
-	 return winner, remainingPool, nil
+	 return selection, nil
 }
 
 // maskMsisdn masks the middle digits of an MSISDN for logging
@@ -714,13 +1199,19 @@ func maskMsisdn(msisdn string) string {
 	 return prefix + maskedPart + suffix
 }
 
-// AllocatePointsForTopup calculates points for a top-up and updates user points and transaction log.
-// This function now resides in DrawService as it's closely tied to draw eligibility/weighting.
-func (s *DrawServiceImpl) AllocatePointsForTopup(ctx context.Context, userID primitive.ObjectID, amount float64, transactionTime time.Time) (int, error) {
+// AllocatePointsForTopup calculates points for a top-up, records them as a PointTransaction keyed
+// on the top-up's transactionRef and adds them to the user's points. A top-up is credited once:
+// a replay returns the points of the original allocation without changing anything, unless the
+// allocation was left pending, in which case the replay completes it. The user records the
+// reference with the points, so the points themselves are never added twice.
+func (s *DrawServiceImpl) AllocatePointsForTopup(ctx context.Context, userID primitive.ObjectID, amount float64, transactionTime time.Time, transactionRef string) (int, error) {
+	 if transactionRef == "" {
+		 return 0, errors.New("a transaction reference is required to allocate points")
+	 }
 	 // 1. Calculate points based on amount (centralized logic)
 	 pointsToAdd := calculatePoints(amount)
 	 if pointsToAdd <= 0 {
-		 slog.Info("No points awarded for top-up amount", "userId", userID, "amount", amount)
+		 slog.Info("No points awarded for top-up amount", "userId", userID, "amount", amount, "transactionRef", transactionRef)
 		 return 0, nil // Not an error, just no points awarded
 	 }
 
@@ -731,32 +1222,51 @@ func (s *DrawServiceImpl) AllocatePointsForTopup(ctx context.Context, userID pri
 		 return 0, fmt.Errorf("failed to find user %s for point allocation: %w", userID.Hex(), err)
 	 }
 
-	 // 3. Create Point Transaction Record
-	 transaction := &models.PointTransaction{
-		 UserID:             user.ID,
-		 MSISDN:             user.MSISDN, // Get MSISDN from user object
-		 TopupAmount:        amount,
-		 PointsAwarded:      pointsToAdd,
+	 // 3. Record the point transaction unless this top-up was recorded before. This runs outside
+	 // a transaction: a concurrent insert of the same reference is then re-read, not aborted.
+	 applied := false
+	 allocation, created, err := s.pointTransactionRepo.CreateIfAbsent(ctx, &models.PointTransaction{
+		 TransactionRef:       transactionRef,
+		 UserID:               user.ID,
+		 MSISDN:               user.MSISDN, // Get MSISDN from user object
+		 TopupAmount:          amount,
+		 PointsAwarded:        pointsToAdd,
 		 TransactionTimestamp: transactionTime,
-		 CreatedAt:          time.Now(),
-		 // Removed undefined fields: TransactionType, Description
-	 }
-	 err = s.pointTransactionRepo.Create(ctx, transaction)
+		 CreatedAt:            time.Now(),
+		 Applied:              &applied,
+	 })
 	 if err != nil {
-		 slog.Error("AllocatePointsForTopup: Failed to create point transaction record", "error", err, "userId", userID)
-		 // Decide if this should prevent point update. For now, log and continue.
+		 slog.Error("AllocatePointsForTopup: Failed to record point transaction", "error", err, "userId", userID, "transactionRef", transactionRef, "pointsToAdd", pointsToAdd)
+		 return 0, fmt.Errorf("failed to allocate points for top-up %s: %w", transactionRef, err)
+	 }
+	 if !allocation.Pending() {
+		 slog.Info("Points already allocated for top-up", "userId", allocation.UserID, "transactionRef", transactionRef, "pointsAwarded", allocation.PointsAwarded)
+		 return allocation.PointsAwarded, nil
+	 }
+	 if !created {
+		 slog.Warn("Completing a pending point allocation", "userId", allocation.UserID, "transactionRef", transactionRef, "pointsAwarded", allocation.PointsAwarded)
 	 }
 
-	 // 4. Update User's Points
-	 err = s.userRepo.IncrementPoints(ctx, user.ID, pointsToAdd)
+	 // 4. Credit the user and flag the record applied. The credit is one conditional write on
+	 // the user that records the reference with the points, so it happens at most once however
+	 // often it is retried; a failure before the flag is set leaves the record pending and the
+	 // next replay only sets the flag.
+	 credited, err := s.userRepo.CreditTopupPoints(ctx, allocation.UserID, transactionRef, allocation.PointsAwarded)
 	 if err != nil {
-		 slog.Error("AllocatePointsForTopup: Failed to increment user points", "error", err, "userId", userID, "pointsToAdd", pointsToAdd)
-		 // Attempt to rollback transaction? Or just log? For now, log the inconsistency.
-		 return 0, fmt.Errorf("failed to update user points for %s: %w", userID.Hex(), err)
+		 slog.Error("AllocatePointsForTopup: Failed to allocate points", "error", err, "userId", userID, "transactionRef", transactionRef, "pointsToAdd", pointsToAdd)
+		 return 0, fmt.Errorf("failed to allocate points for top-up %s: %w", transactionRef, err)
+	 }
+	 if _, err := s.pointTransactionRepo.MarkApplied(ctx, allocation.ID); err != nil {
+		 slog.Error("AllocatePointsForTopup: Failed to mark point transaction applied", "error", err, "userId", userID, "transactionRef", transactionRef)
+		 return 0, fmt.Errorf("failed to allocate points for top-up %s: %w", transactionRef, err)
+	 }
+	 if !credited {
+		 slog.Info("Points already allocated for top-up", "userId", allocation.UserID, "transactionRef", transactionRef, "pointsAwarded", allocation.PointsAwarded)
+		 return allocation.PointsAwarded, nil
 	 }
 
-	 slog.Info("Points allocated successfully", "userId", userID, "pointsAdded", pointsToAdd, "newTotalPoints", user.Points+pointsToAdd)
-	 return pointsToAdd, nil
+	 slog.Info("Points allocated successfully", "userId", userID, "transactionRef", transactionRef, "pointsAdded", allocation.PointsAwarded, "newTotalPoints", user.Points+allocation.PointsAwarded)
+	 return allocation.PointsAwarded, nil
 }
 
 // calculatePoints determines points based on top-up amount.
@@ -779,34 +1289,29 @@ func (s *DrawServiceImpl) GetDrawConfig(ctx context.Context) (map[string]interfa
 	 // Example: Fetch base jackpot amounts and prize structures
 	 config := make(map[string]interface{})
 
-	 dailyJackpotConfig, err := s.systemConfigRepo.FindByKey(ctx, "base_jackpot_DAILY")
-	 if err == nil {
-		 config["base_jackpot_DAILY"] = dailyJackpotConfig.Value
-	 } else if !errors.Is(err, mongo.ErrNoDocuments) {
-		 slog.Error("GetDrawConfig: Failed to fetch daily base jackpot", "error", err)
-		 // Decide if this error is fatal or just log
-	 }
-
-	 saturdayJackpotConfig, err := s.systemConfigRepo.FindByKey(ctx, "base_jackpot_SATURDAY")
-	 if err == nil {
-		 config["base_jackpot_SATURDAY"] = saturdayJackpotConfig.Value
-	 } else if !errors.Is(err, mongo.ErrNoDocuments) {
-		 slog.Error("GetDrawConfig: Failed to fetch saturday base jackpot", "error", err)
-	 }
-
-	 // Fetch prize structures (assuming stored under keys like "prize_structure_DAILY")
-	 dailyPrizes, err := s.GetPrizeStructure(ctx, "DAILY")
-	 if err == nil {
-		 config["prize_structure_DAILY"] = dailyPrizes
-	 } else {
-		 slog.Error("GetDrawConfig: Failed to fetch daily prize structure", "error", err)
+	 drawTypes, err := s.drawTypeRepo.FindAll(ctx)
+	 if err != nil {
+		 slog.Error("GetDrawConfig: Failed to fetch draw types", "error", err)
+		 return nil, fmt.Errorf("failed to fetch draw types: %w", err)
 	 }
+	 config["draw_types"] = drawTypes
+
+	 // Base jackpots and prize structures of every registered draw type, under their config keys
+	 for _, drawType := range drawTypes {
+		 jackpotConfig, err := s.systemConfigRepo.FindByKey(ctx, drawType.BaseJackpotKey)
+		 if err == nil {
+			 config[drawType.BaseJackpotKey] = jackpotConfig.Value
+		 } else if !errors.Is(err, mongo.ErrNoDocuments) {
+			 slog.Error("GetDrawConfig: Failed to fetch base jackpot", "error", err, "drawType", drawType.Code)
+			 // Decide if this error is fatal or just log
+		 }
 
-	 saturdayPrizes, err := s.GetPrizeStructure(ctx, "SATURDAY")
-	 if err == nil {
-		 config["prize_structure_SATURDAY"] = saturdayPrizes
-	 } else {
-		 slog.Error("GetDrawConfig: Failed to fetch saturday prize structure", "error", err)
+		 prizeStructure, err := s.effectivePrizeStructure(ctx, drawType, time.Now().UTC())
+		 if err == nil {
+			 config[drawType.PrizeStructureKey] = prizeStructure.Prizes
+		 } else {
+			 slog.Error("GetDrawConfig: Failed to fetch prize structure", "error", err, "drawType", drawType.Code)
+		 }
 	 }
 
 	 // Add other relevant configs
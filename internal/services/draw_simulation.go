@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// maxSimulationIterations caps the Monte Carlo iterations of a single simulation
+	maxSimulationIterations = 10000
+	// maxSimulationWork caps iterations × pool size, since every iteration rebuilds the samplers
+	maxSimulationWork = 50000000
+)
+
+var (
+	// ErrInvalidSimulation is returned when the requested iterations exceed the simulation caps
+	ErrInvalidSimulation = errors.New("invalid simulation")
+	// ErrDrawNotScheduled is returned when simulating a draw that has already run or is running
+	ErrDrawNotScheduled = errors.New("draw is not in SCHEDULED state")
+)
+
+// SimulateDraw previews a draw without writing anything: it builds the pools exactly as
+// ExecuteDraw would, blacklist exclusions included, reports each participant's weight and
+// jackpot probability, and makes one sample selection under the current win-frequency rules. With iterations > 0 it repeats the selection that many times and
+// compares observed jackpot picks with the expected probabilities. The expected probabilities
+// ignore the win rules, so users the rules refuse show up as deviations.
+//
+// The simulation never uses the draw's committed seed, so it cannot reveal the real outcome.
+func (s *DrawServiceImpl) SimulateDraw(ctx context.Context, drawID primitive.ObjectID, iterations, participantLimit int) (*models.DrawSimulation, error) {
+	if iterations < 0 || iterations > maxSimulationIterations {
+		return nil, fmt.Errorf("%w: iterations must be between 0 and %d", ErrInvalidSimulation, maxSimulationIterations)
+	}
+
+	draw, err := s.GetDrawByID(ctx, drawID)
+	if err != nil {
+		return nil, err
+	}
+	if draw.Status != models.DrawStatusScheduled {
+		return nil, fmt.Errorf("%w (current: %s)", ErrDrawNotScheduled, draw.Status)
+	}
+
+	eligibilityStart, eligibilityCutoff, err := drawEligibilityWindow(draw)
+	if err != nil {
+		return nil, err
+	}
+	poolA, poolB, excluded, err := fetchDrawPools(ctx, s.userRepo, s.blacklistRepo, draw, eligibilityStart, eligibilityCutoff)
+	if err != nil {
+		return nil, err
+	}
+	if iterations*(len(poolA)+len(poolB)) > maxSimulationWork {
+		return nil, fmt.Errorf("%w: too many iterations for pools of %d and %d participants, use at most %d", ErrInvalidSimulation, len(poolA), len(poolB), maxSimulationWork/(len(poolA)+len(poolB)))
+	}
+
+	sim := &models.DrawSimulation{
+		DrawID:               draw.ID,
+		DrawType:             draw.DrawType,
+		DrawDate:             draw.DrawDate,
+		EligibilityStart:     eligibilityStart,
+		EligibilityCutoff:    eligibilityCutoff,
+		JackpotPoolSize:      len(poolA),
+		ConsolationPoolSize:  len(poolB),
+		ExcludedParticipants: len(excluded),
+		PoolDigest:           computePoolDigest(poolA, poolB),
+	}
+
+	// Merge both pools into one entry per MSISDN
+	index := make(map[string]int)
+	var participants []models.SimulatedParticipant
+	entry := func(user *models.User) *models.SimulatedParticipant {
+		i, ok := index[user.MSISDN]
+		if !ok {
+			i = len(participants)
+			index[user.MSISDN] = i
+			participants = append(participants, models.SimulatedParticipant{MSISDN: user.MSISDN, Points: user.Points})
+		}
+		return &participants[i]
+	}
+	for _, user := range poolA {
+		p := entry(user)
+		p.InJackpotPool = true
+		p.Weight += userWeight(user)
+		sim.JackpotPoolWeight += userWeight(user)
+	}
+	for _, user := range poolB {
+		p := entry(user)
+		p.InConsolationPool = true
+		if !p.InJackpotPool {
+			p.Weight += userWeight(user)
+		}
+		sim.ConsolationPoolWeight += userWeight(user)
+	}
+	for i := range participants {
+		if participants[i].InJackpotPool && sim.JackpotPoolWeight > 0 {
+			participants[i].JackpotProbability = float64(participants[i].Weight) / float64(sim.JackpotPoolWeight)
+		}
+	}
+	sort.SliceStable(participants, func(i, j int) bool {
+		if participants[i].Weight != participants[j].Weight {
+			return participants[i].Weight > participants[j].Weight
+		}
+		return participants[i].MSISDN < participants[j].MSISDN
+	})
+	if participantLimit > 0 && len(participants) > participantLimit {
+		participants = participants[:participantLimit]
+	}
+
+	// Fresh randomness, bound to the pools like a real execution
+	seed, _, err := generateDrawSeed()
+	if err != nil {
+		return nil, err
+	}
+	rng, err := newDrawRNG(seed, sim.PoolDigest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialise simulation randomness: %w", err)
+	}
+	noLog := func(string) {}
+
+	alternates := s.getIntConfig(ctx, jackpotAlternateCountKey, defaultJackpotAlternateCount)
+	winRules, err := s.GetWinFrequencyRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sim.WinRules = winRules
+	checker := newWinRuleChecker(ctx, s.winnerRepo, *winRules, draw)
+	sample, err := selectDrawWinners(rng, poolA, poolB, draw.Prizes, alternates, winRules.OnePrizePerDraw, checker.accept, noLog)
+	if err != nil {
+		return nil, err
+	}
+	sim.SampleWinners = selectionWinnerKeys(sample)
+
+	if iterations > 0 {
+		jackpotCounts := make(map[string]int)
+		consolationCounts := make(map[string]int)
+		for n := 0; n < iterations; n++ {
+			selection, err := selectDrawWinners(rng, poolA, poolB, draw.Prizes, 0, winRules.OnePrizePerDraw, checker.accept, noLog)
+			if err != nil {
+				return nil, err
+			}
+			if selection.JackpotCandidate != nil {
+				jackpotCounts[selection.JackpotCandidate.MSISDN]++
+			}
+			for _, pick := range selection.Consolation {
+				consolationCounts[pick.User.MSISDN]++
+			}
+		}
+		sim.MonteCarlo = monteCarloSummary(participants, jackpotCounts, consolationCounts, iterations)
+	}
+
+	sim.Participants = participants
+	return sim, nil
+}
+
+// monteCarloSummary fills in the observed rates of the listed participants and measures how
+// far the observed first jackpot picks are from the expected probabilities
+func monteCarloSummary(participants []models.SimulatedParticipant, jackpotCounts, consolationCounts map[string]int, iterations int) *models.DrawMonteCarloResult {
+	result := &models.DrawMonteCarloResult{Iterations: iterations}
+	n := float64(iterations)
+
+	listedProbability := 0.0
+	listedPicks := 0
+	buckets := 0
+	for i := range participants {
+		p := &participants[i]
+		p.ObservedJackpotRate = float64(jackpotCounts[p.MSISDN]) / n
+		p.ObservedConsolationWin = float64(consolationCounts[p.MSISDN]) / n
+		if p.JackpotProbability <= 0 {
+			continue
+		}
+		if deviation := math.Abs(p.ObservedJackpotRate - p.JackpotProbability); deviation > result.MaxDeviation {
+			result.MaxDeviation = deviation
+		}
+		expected := n * p.JackpotProbability
+		observed := float64(jackpotCounts[p.MSISDN])
+		result.ChiSquare += (observed - expected) * (observed - expected) / expected
+		listedProbability += p.JackpotProbability
+		listedPicks += jackpotCounts[p.MSISDN]
+		buckets++
+	}
+
+	// Everyone not listed forms one bucket
+	if otherProbability := 1 - listedProbability; otherProbability > 1e-9 {
+		totalPicks := 0
+		for _, count := range jackpotCounts {
+			totalPicks += count
+		}
+		expected := n * otherProbability
+		observed := float64(totalPicks - listedPicks)
+		result.ChiSquare += (observed - expected) * (observed - expected) / expected
+		buckets++
+	}
+	if buckets > 1 {
+		result.DegreesOfFreedom = buckets - 1
+	}
+	return result
+}
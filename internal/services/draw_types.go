@@ -0,0 +1,417 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/exp/slog"
+)
+
+var (
+	// ErrUnknownDrawType is returned when a draw type code is not in the registry
+	ErrUnknownDrawType = errors.New("unknown draw type")
+	// ErrDrawTypeKeysNeedApproval is returned when a plain draw type update would repoint its
+	// prize structure or base jackpot key, which needs a second admin's approval
+	ErrDrawTypeKeysNeedApproval = errors.New("changing the prize structure or base jackpot key of a draw type needs approval")
+)
+
+// drawTypeCodePattern restricts codes to what is safe in SystemConfig keys and URLs
+var drawTypeCodePattern = regexp.MustCompile(`^[A-Z0-9_]{1,32}$`)
+
+// normalizeDrawTypeCode returns the registry form of a draw type code
+func normalizeDrawTypeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// defaultDrawTypes are the draw types the promotion started with: a DAILY draw from Monday
+// to Friday and the SATURDAY draw, whose jackpot is the headline one
+func defaultDrawTypes() []*models.DrawTypeDefinition {
+	return []*models.DrawTypeDefinition{
+		{
+			Code:                "DAILY",
+			Name:                "Daily Draw",
+			Active:              true,
+			Weekdays:            []int{int(time.Monday), int(time.Tuesday), int(time.Wednesday), int(time.Thursday), int(time.Friday)},
+			EligibilityWindow:   defaultEligibilityWindow("DAILY"),
+			PrizeStructureKey:   "prize_structure_DAILY",
+			BaseJackpotKey:      "base_jackpot_DAILY",
+			RolloverDestination: models.RolloverToNextDraw,
+		},
+		{
+			Code:                "SATURDAY",
+			Name:                "Saturday Draw",
+			Active:              true,
+			Weekdays:            []int{int(time.Saturday)},
+			EligibilityWindow:   defaultEligibilityWindow("SATURDAY"),
+			PrizeStructureKey:   "prize_structure_SATURDAY",
+			BaseJackpotKey:      "base_jackpot_SATURDAY",
+			RolloverDestination: models.RolloverToNextDraw,
+			Headline:            true,
+		},
+	}
+}
+
+// EnsureDefaultDrawTypes registers the default draw types that are missing from the registry.
+// An eligibility window already configured for a type in SystemConfig is carried over.
+func (s *DrawServiceImpl) EnsureDefaultDrawTypes(ctx context.Context) error {
+	for _, drawType := range defaultDrawTypes() {
+		_, err := s.drawTypeRepo.FindByCode(ctx, drawType.Code)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return fmt.Errorf("failed to check draw type %s: %w", drawType.Code, err)
+		}
+		if rule, found, err := s.configuredEligibilityWindow(ctx, drawType.Code); err != nil {
+			slog.Warn("Ignoring invalid eligibility window config while registering draw type", "error", err, "drawType", drawType.Code)
+		} else if found {
+			drawType.EligibilityWindow = rule
+		}
+		if err := s.drawTypeRepo.Create(ctx, drawType); err != nil {
+			return err
+		}
+		slog.Info("Registered default draw type", "drawType", drawType.Code)
+	}
+	return nil
+}
+
+// configuredEligibilityWindow reads the eligibility window rule of a draw type from the
+// SystemConfig key used before the draw type registry existed
+func (s *DrawServiceImpl) configuredEligibilityWindow(ctx context.Context, code string) (models.EligibilityWindowRule, bool, error) {
+	var rule models.EligibilityWindowRule
+	config, err := s.systemConfigRepo.FindByKey(ctx, eligibilityWindowKeyPrefix+code)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return rule, false, nil
+		}
+		return rule, false, err
+	}
+	jsonString, ok := config.Value.(string)
+	if !ok {
+		return rule, false, fmt.Errorf("invalid eligibility window format (expected JSON string, got %T)", config.Value)
+	}
+	if err := json.Unmarshal([]byte(jsonString), &rule); err != nil {
+		return rule, false, fmt.Errorf("failed to parse eligibility window JSON: %w", err)
+	}
+	if err := validateEligibilityWindow(rule); err != nil {
+		return rule, false, err
+	}
+	return rule, true, nil
+}
+
+// drawTypeDefinition returns the registry entry of a draw type, wrapping ErrUnknownDrawType
+// when it is not registered
+func (s *DrawServiceImpl) drawTypeDefinition(ctx context.Context, code string) (*models.DrawTypeDefinition, error) {
+	code = normalizeDrawTypeCode(code)
+	drawType, err := s.drawTypeRepo.FindByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownDrawType, code)
+		}
+		slog.Error("Failed to fetch draw type", "error", err, "drawType", code)
+		return nil, fmt.Errorf("failed to fetch draw type %s: %w", code, err)
+	}
+	return drawType, nil
+}
+
+// headlineDrawType returns the draw type whose jackpot is reported by default
+func (s *DrawServiceImpl) headlineDrawType(ctx context.Context) (*models.DrawTypeDefinition, error) {
+	drawTypes, err := s.drawTypeRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, drawType := range drawTypes {
+		if drawType.Headline {
+			return drawType, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no headline draw type is registered", ErrUnknownDrawType)
+}
+
+// ListDrawTypes returns every registered draw type ordered by code
+func (s *DrawServiceImpl) ListDrawTypes(ctx context.Context) ([]*models.DrawTypeDefinition, error) {
+	drawTypes, err := s.drawTypeRepo.FindAll(ctx)
+	if err != nil {
+		slog.Error("Failed to list draw types", "error", err)
+		return nil, err
+	}
+	return drawTypes, nil
+}
+
+// GetDrawType returns a registered draw type
+func (s *DrawServiceImpl) GetDrawType(ctx context.Context, code string) (*models.DrawTypeDefinition, error) {
+	return s.drawTypeDefinition(ctx, code)
+}
+
+// CreateDrawType validates and registers a new draw type. Missing SystemConfig keys default
+// to prize_structure_<CODE> and base_jackpot_<CODE>.
+func (s *DrawServiceImpl) CreateDrawType(ctx context.Context, drawType *models.DrawTypeDefinition) (*models.DrawTypeDefinition, error) {
+	drawType.Code = normalizeDrawTypeCode(drawType.Code)
+	if drawType.PrizeStructureKey == "" {
+		drawType.PrizeStructureKey = "prize_structure_" + drawType.Code
+	}
+	if drawType.BaseJackpotKey == "" {
+		drawType.BaseJackpotKey = "base_jackpot_" + drawType.Code
+	}
+	if drawType.RolloverDestination == "" {
+		drawType.RolloverDestination = models.RolloverToNextDraw
+	}
+
+	if _, err := s.drawTypeRepo.FindByCode(ctx, drawType.Code); err == nil {
+		return nil, fmt.Errorf("draw type %s already exists", drawType.Code)
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("failed to check for existing draw type: %w", err)
+	}
+	if err := s.validateDrawType(ctx, drawType); err != nil {
+		return nil, err
+	}
+
+	drawType.ID = primitive.NilObjectID
+	if err := s.drawTypeRepo.Create(ctx, drawType); err != nil {
+		slog.Error("Failed to create draw type", "error", err, "drawType", drawType.Code)
+		return nil, err
+	}
+	if err := s.clearOtherHeadlines(ctx, drawType); err != nil {
+		return drawType, err
+	}
+	slog.Info("Draw type created", "drawType", drawType.Code)
+	s.recordDrawTypeAudit(ctx, models.AuditActionDrawTypeCreated, drawType.Code, nil, drawType)
+	return drawType, nil
+}
+
+// UpdateDrawType replaces the settings of a registered draw type. The code cannot change,
+// and draws already scheduled keep the eligibility window and prizes they were scheduled with.
+// The prize structure and base jackpot keys decide what the type pays out, so they only change
+// through an approved request (see UpdateDrawTypeKeys).
+func (s *DrawServiceImpl) UpdateDrawType(ctx context.Context, code string, update *models.DrawTypeDefinition) (*models.DrawTypeDefinition, error) {
+	existing, err := s.drawTypeDefinition(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if update.Code != "" && normalizeDrawTypeCode(update.Code) != existing.Code {
+		return nil, errors.New("the code of a draw type cannot be changed")
+	}
+	update.ID = existing.ID
+	update.Code = existing.Code
+	update.CreatedAt = existing.CreatedAt
+	if update.PrizeStructureKey == "" {
+		update.PrizeStructureKey = existing.PrizeStructureKey
+	}
+	if update.BaseJackpotKey == "" {
+		update.BaseJackpotKey = existing.BaseJackpotKey
+	}
+	if update.PrizeStructureKey != existing.PrizeStructureKey || update.BaseJackpotKey != existing.BaseJackpotKey {
+		return nil, ErrDrawTypeKeysNeedApproval
+	}
+	if update.RolloverDestination == "" {
+		update.RolloverDestination = models.RolloverToNextDraw
+	}
+	if err := s.validateDrawType(ctx, update); err != nil {
+		return nil, err
+	}
+
+	if err := s.drawTypeRepo.Update(ctx, update); err != nil {
+		slog.Error("Failed to update draw type", "error", err, "drawType", update.Code)
+		return nil, err
+	}
+	if err := s.clearOtherHeadlines(ctx, update); err != nil {
+		return update, err
+	}
+	slog.Info("Draw type updated", "drawType", update.Code)
+	s.recordDrawTypeAudit(ctx, models.AuditActionDrawTypeUpdated, update.Code, existing, update)
+	return update, nil
+}
+
+// UpdateDrawTypeKeys repoints the SystemConfig keys a draw type reads its prize structure and
+// base jackpot from ("" keeps a key). It runs once a DRAW_TYPE_KEYS request is approved.
+func (s *DrawServiceImpl) UpdateDrawTypeKeys(ctx context.Context, code, prizeStructureKey, baseJackpotKey string) (*models.DrawTypeDefinition, error) {
+	existing, err := s.drawTypeDefinition(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	updated := *existing
+	if prizeStructureKey != "" {
+		updated.PrizeStructureKey = prizeStructureKey
+	}
+	if baseJackpotKey != "" {
+		updated.BaseJackpotKey = baseJackpotKey
+	}
+
+	if err := s.drawTypeRepo.Update(ctx, &updated); err != nil {
+		slog.Error("Failed to update draw type keys", "error", err, "drawType", updated.Code)
+		return nil, err
+	}
+	slog.Info("Draw type keys updated", "drawType", updated.Code, "prizeStructureKey", updated.PrizeStructureKey, "baseJackpotKey", updated.BaseJackpotKey)
+	s.recordDrawTypeAudit(ctx, models.AuditActionDrawTypeUpdated, updated.Code, existing, &updated)
+	return &updated, nil
+}
+
+// DeleteDrawType removes a draw type from the registry. Types with scheduled draws, or that
+// other types roll their jackpot into, cannot be deleted; deactivate them instead.
+func (s *DrawServiceImpl) DeleteDrawType(ctx context.Context, code string) error {
+	drawType, err := s.drawTypeDefinition(ctx, code)
+	if err != nil {
+		return err
+	}
+	scheduled, err := s.drawRepo.FindByStatus(ctx, string(models.DrawStatusScheduled))
+	if err != nil {
+		return fmt.Errorf("failed to check scheduled draws: %w", err)
+	}
+	for _, draw := range scheduled {
+		if draw.DrawType == drawType.Code {
+			return fmt.Errorf("draw type %s has scheduled draws (e.g. %s), deactivate it instead", drawType.Code, draw.DrawDate.Format("2006-01-02"))
+		}
+	}
+	others, err := s.drawTypeRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if other.RolloverDestination == models.RolloverToDrawType && other.RolloverDrawType == drawType.Code {
+			return fmt.Errorf("draw type %s rolls its jackpot over into %s", other.Code, drawType.Code)
+		}
+	}
+
+	if err := s.drawTypeRepo.Delete(ctx, drawType.Code); err != nil {
+		slog.Error("Failed to delete draw type", "error", err, "drawType", drawType.Code)
+		return err
+	}
+	slog.Info("Draw type deleted", "drawType", drawType.Code)
+	s.recordDrawTypeAudit(ctx, models.AuditActionDrawTypeDeleted, drawType.Code, drawType, nil)
+	return nil
+}
+
+// recordDrawTypeAudit records an audit event for a draw type, diffing before and after (nil
+// for a created or deleted type)
+func (s *DrawServiceImpl) recordDrawTypeAudit(ctx context.Context, action, code string, before, after *models.DrawTypeDefinition) {
+	var previous, current interface{}
+	if before != nil {
+		previous = before
+	}
+	if after != nil {
+		current = after
+	}
+	s.audit.Record(ctx, &models.AuditEvent{
+		Action:     action,
+		TargetType: models.AuditTargetDrawType,
+		TargetID:   code,
+		Changes:    auditChanges(previous, current, "updatedAt"),
+	})
+}
+
+// validateDrawType checks a draw type against its own rules and the rest of the registry
+func (s *DrawServiceImpl) validateDrawType(ctx context.Context, drawType *models.DrawTypeDefinition) error {
+	if !drawTypeCodePattern.MatchString(drawType.Code) {
+		return fmt.Errorf("invalid draw type code %q (use up to 32 of A-Z, 0-9 and _)", drawType.Code)
+	}
+	if strings.TrimSpace(drawType.Name) == "" {
+		return errors.New("draw type name is required")
+	}
+	if err := validateEligibilityWindow(drawType.EligibilityWindow); err != nil {
+		return err
+	}
+	seenDays := make(map[int]bool)
+	for _, day := range drawType.Weekdays {
+		if day < 0 || day > 6 || seenDays[day] {
+			return fmt.Errorf("invalid or repeated weekday %d (0 = Sunday ... 6 = Saturday)", day)
+		}
+		seenDays[day] = true
+	}
+	for _, digit := range drawType.DefaultDigits {
+		if digit < 0 || digit > 9 {
+			return fmt.Errorf("invalid default digit %d", digit)
+		}
+	}
+
+	others, err := s.drawTypeRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	switch drawType.RolloverDestination {
+	case models.RolloverToNextDraw, models.RolloverToNextSameType:
+		drawType.RolloverDrawType = ""
+	case models.RolloverToDrawType:
+		drawType.RolloverDrawType = normalizeDrawTypeCode(drawType.RolloverDrawType)
+		found := drawType.RolloverDrawType == drawType.Code
+		for _, other := range others {
+			found = found || other.Code == drawType.RolloverDrawType
+		}
+		if !found {
+			return fmt.Errorf("rollover target %s is not a registered draw type", drawType.RolloverDrawType)
+		}
+	default:
+		return fmt.Errorf("invalid rollover destination %q", drawType.RolloverDestination)
+	}
+
+	// The scheduler holds one draw per day, so active types cannot share a weekday
+	if drawType.Active {
+		for _, other := range others {
+			if other.Code == drawType.Code || !other.Active {
+				continue
+			}
+			for _, day := range other.Weekdays {
+				if seenDays[day] {
+					return fmt.Errorf("draw type %s is already scheduled on %s", other.Code, time.Weekday(day))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// clearOtherHeadlines keeps a single headline draw type
+func (s *DrawServiceImpl) clearOtherHeadlines(ctx context.Context, drawType *models.DrawTypeDefinition) error {
+	if !drawType.Headline {
+		return nil
+	}
+	others, err := s.drawTypeRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, other := range others {
+		if other.Code == drawType.Code || !other.Headline {
+			continue
+		}
+		other.Headline = false
+		if err := s.drawTypeRepo.Update(ctx, other); err != nil {
+			return fmt.Errorf("failed to clear headline flag of %s: %w", other.Code, err)
+		}
+	}
+	return nil
+}
+
+// drawTypeForWeekday returns the active draw type the scheduler creates on a weekday, or nil
+func drawTypeForWeekday(drawTypes []*models.DrawTypeDefinition, day time.Weekday) *models.DrawTypeDefinition {
+	for _, drawType := range drawTypes {
+		if !drawType.Active {
+			continue
+		}
+		for _, d := range drawType.Weekdays {
+			if time.Weekday(d) == day {
+				return drawType
+			}
+		}
+	}
+	return nil
+}
+
+// nextWeekdayAfter returns the first date after t falling on one of the weekdays
+func nextWeekdayAfter(t time.Time, weekdays []int) (time.Time, bool) {
+	for i := 1; i <= 7; i++ {
+		candidate := t.AddDate(0, 0, i)
+		for _, d := range weekdays {
+			if time.Weekday(d) == candidate.Weekday() {
+				return candidate, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
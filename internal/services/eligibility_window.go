@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// eligibilityWindowKeyPrefix is followed by the upper-case draw type in SystemConfig. Rules
+	// now live in the draw type registry; the keys are only read when registering the defaults.
+	eligibilityWindowKeyPrefix = "eligibility_window_"
+	// promotionTimezone is the promotion's local time (WAT)
+	promotionTimezone = "Africa/Lagos"
+	// eligibilityTimeLayout is the layout of StartTime and CutoffTime
+	eligibilityTimeLayout = "15:04:05"
+)
+
+// defaultEligibilityWindow is the rule of the default draw types:
+// SATURDAY draws cover the week since the previous Saturday's cut-off, other draws the draw day.
+func defaultEligibilityWindow(drawType string) models.EligibilityWindowRule {
+	if strings.ToUpper(drawType) == "SATURDAY" {
+		return models.EligibilityWindowRule{Timezone: promotionTimezone, StartDaysBefore: 7, StartTime: "18:00:01", CutoffTime: "18:00:00"}
+	}
+	return models.EligibilityWindowRule{Timezone: promotionTimezone, StartDaysBefore: 0, StartTime: "00:00:00", CutoffTime: "18:00:00"}
+}
+
+// validateEligibilityWindow checks that a rule can be applied and yields a non-empty window
+func validateEligibilityWindow(rule models.EligibilityWindowRule) error {
+	if rule.Timezone == "" {
+		return errors.New("eligibility window timezone is required")
+	}
+	if rule.StartDaysBefore < 0 || rule.StartDaysBefore > 31 {
+		return fmt.Errorf("eligibility window startDaysBefore must be between 0 and 31 (got %d)", rule.StartDaysBefore)
+	}
+	start, cutoff, err := eligibilityWindowFor(time.Now(), rule)
+	if err != nil {
+		return err
+	}
+	if !start.Before(cutoff) {
+		return fmt.Errorf("eligibility window opens at %s, which is not before its cut-off %s", rule.StartTime, rule.CutoffTime)
+	}
+	return nil
+}
+
+// eligibilityWindowFor applies a rule to the calendar date of drawDate. An empty Timezone
+// means drawDate's own location, which is how windows were computed before rules existed.
+func eligibilityWindowFor(drawDate time.Time, rule models.EligibilityWindowRule) (time.Time, time.Time, error) {
+	loc := drawDate.Location()
+	if rule.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(rule.Timezone); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid eligibility window timezone %q: %w", rule.Timezone, err)
+		}
+	}
+	startClock, err := time.Parse(eligibilityTimeLayout, rule.StartTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid eligibility window start time %q (expected HH:MM:SS): %w", rule.StartTime, err)
+	}
+	cutoffClock, err := time.Parse(eligibilityTimeLayout, rule.CutoffTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid eligibility window cut-off time %q (expected HH:MM:SS): %w", rule.CutoffTime, err)
+	}
+
+	year, month, day := drawDate.Date()
+	cutoff := time.Date(year, month, day, cutoffClock.Hour(), cutoffClock.Minute(), cutoffClock.Second(), 0, loc)
+	start := time.Date(year, month, day-rule.StartDaysBefore, startClock.Hour(), startClock.Minute(), startClock.Second(), 0, loc)
+	return start, cutoff, nil
+}
+
+// drawEligibilityWindow returns the recharge window [start, cutoff] for a draw, using the rule
+// snapshotted when it was scheduled. Draws scheduled before rules were snapshotted keep the
+// original hard-coded windows in the draw date's location.
+func drawEligibilityWindow(draw *models.Draw) (time.Time, time.Time, error) {
+	if draw.EligibilityWindow != nil {
+		return eligibilityWindowFor(draw.DrawDate, *draw.EligibilityWindow)
+	}
+	legacy := defaultEligibilityWindow(draw.DrawType)
+	legacy.Timezone = ""
+	return eligibilityWindowFor(draw.DrawDate, legacy)
+}
+
+// GetEligibilityWindow returns the eligibility window rule of a registered draw type
+func (s *DrawServiceImpl) GetEligibilityWindow(ctx context.Context, drawType string) (*models.EligibilityWindowRule, error) {
+	drawTypeDef, err := s.drawTypeDefinition(ctx, drawType)
+	if err != nil {
+		return nil, err
+	}
+	rule := drawTypeDef.EligibilityWindow
+	return &rule, nil
+}
+
+// UpdateEligibilityWindow validates and stores the eligibility window rule of a registered
+// draw type. Draws already scheduled keep the rule they were scheduled with.
+func (s *DrawServiceImpl) UpdateEligibilityWindow(ctx context.Context, drawType string, rule models.EligibilityWindowRule) error {
+	if err := validateEligibilityWindow(rule); err != nil {
+		return err
+	}
+	drawTypeDef, err := s.drawTypeDefinition(ctx, drawType)
+	if err != nil {
+		return err
+	}
+	previous := drawTypeDef.EligibilityWindow
+	drawTypeDef.EligibilityWindow = rule
+	if err := s.drawTypeRepo.Update(ctx, drawTypeDef); err != nil {
+		slog.Error("Failed to update eligibility window", "error", err, "drawType", drawTypeDef.Code)
+		return fmt.Errorf("failed to save eligibility window of %s: %w", drawTypeDef.Code, err)
+	}
+	slog.Info("Eligibility window updated successfully", "drawType", drawTypeDef.Code)
+	s.audit.Record(ctx, &models.AuditEvent{
+		Action:     models.AuditActionEligibilityWindow,
+		TargetType: models.AuditTargetDrawType,
+		TargetID:   drawTypeDef.Code,
+		Changes:    auditChanges(previous, rule),
+	})
+	return nil
+}
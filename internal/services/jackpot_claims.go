@@ -0,0 +1,377 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/exp/slog"
+)
+
+// SystemConfig keys and defaults for the jackpot claim rules
+const (
+	jackpotAlternateCountKey      = "jackpot_alternate_count"
+	jackpotClaimWindowDaysKey     = "jackpot_claim_window_days"
+	defaultJackpotAlternateCount  = 3
+	defaultJackpotClaimWindowDays = 7
+)
+
+// ErrClaimStatusChanged is returned when a winner's claim status changed while it was being
+// updated, e.g. a claim racing with the forfeit of an expired jackpot
+var ErrClaimStatusChanged = errors.New("the winner's claim status changed concurrently")
+
+// isJackpotEligible reports whether a jackpot pick had opted in before the eligibility cut-off
+func isJackpotEligible(user *models.User, eligibilityCutoff time.Time) bool {
+	return user.OptInStatus && !user.OptInDate.IsZero() && user.OptInDate.Before(eligibilityCutoff)
+}
+
+// newJackpotWinnerRecord prepares the jackpot Winner record for a candidate of the draw's chain.
+// It returns nil when the draw has no jackpot prize category.
+func (s *DrawServiceImpl) newJackpotWinnerRecord(draw *models.Draw, user *models.User, rank int, awardedAt time.Time) *models.Winner {
+	hasJackpot := false
+	for _, p := range draw.Prizes {
+		if p.Category == models.JackpotCategory {
+			hasJackpot = true
+			break
+		}
+	}
+	if !hasJackpot {
+		return nil
+	}
+
+	winner := &models.Winner{
+		ID:            primitive.NewObjectID(), // Pre-assigned so the draw's candidate chain can reference it
+		DrawID:        draw.ID,
+		UserID:        user.ID,
+		MSISDN:        user.MSISDN,
+		PrizeCategory: models.JackpotCategory,
+		PrizeAmount:   draw.CalculatedJackpotAmount, // Use the calculated amount for the winner
+		ClaimStatus:   models.ClaimStatusPending,
+		WinDate:       draw.DrawDate,
+		AlternateRank: rank,
+		CreatedAt:     awardedAt,
+		UpdatedAt:     awardedAt,
+	}
+	if draw.JackpotClaimWindowDays > 0 {
+		winner.ClaimDeadline = awardedAt.AddDate(0, 0, draw.JackpotClaimWindowDays)
+	}
+	return winner
+}
+
+// newRolloverRecord prepares a JackpotRollover of the draw's jackpot into the draw chosen by the
+// rollover destination of its draw type, the next scheduled one after the given time, and marks
+// the draw as rolled over. The caller persists the record.
+func (s *DrawServiceImpl) newRolloverRecord(ctx context.Context, draw *models.Draw, after time.Time, reason string) *models.JackpotRollover {
+	draw.RolloverExecuted = true
+	rolloverAmount := draw.CalculatedJackpotAmount
+
+	// Resolve the destination draw type: "" means a draw of any type
+	targetType := ""
+	fallbackWeekdays := []int{int(time.Saturday)}
+	drawTypeDef, err := s.drawTypeDefinition(ctx, draw.DrawType)
+	if err != nil {
+		draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("WARN: Draw type %s is not registered, rolling over into the next draw of any type", draw.DrawType))
+	} else {
+		switch drawTypeDef.RolloverDestination {
+		case models.RolloverToNextSameType:
+			targetType = drawTypeDef.Code
+		case models.RolloverToDrawType:
+			targetType = drawTypeDef.RolloverDrawType
+		}
+	}
+	if targetType != "" {
+		if targetDef, err := s.drawTypeDefinition(ctx, targetType); err == nil && len(targetDef.Weekdays) > 0 {
+			fallbackWeekdays = targetDef.Weekdays
+		}
+	}
+
+	var destinationDate time.Time
+	var destinationID primitive.ObjectID
+	var nextDraw *models.Draw
+	if targetType == "" {
+		nextDraw, err = s.drawRepo.FindNextScheduledDraw(ctx, after)
+	} else {
+		nextDraw, err = s.drawRepo.FindNextScheduledDrawByType(ctx, targetType, after)
+	}
+	if err != nil {
+		slog.Error("Failed to find next scheduled draw for rollover destination", "error", err, "sourceDrawId", draw.ID, "targetType", targetType)
+		// Fallback: the next day the destination type is held (might be inaccurate if schedule changes)
+		destinationDate, _ = nextWeekdayAfter(after, fallbackWeekdays)
+		draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("WARN: Could not find next scheduled draw, using calculated date %s for rollover", destinationDate.Format("2006-01-02")))
+	} else {
+		destinationDate = nextDraw.DrawDate
+		destinationID = nextDraw.ID
+		targetType = nextDraw.DrawType
+	}
+
+	draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Rollover Triggered: Amount %.2f to %s (%s)", rolloverAmount, destinationDate.Format("2006-01-02"), reason))
+	return &models.JackpotRollover{
+		SourceDrawID:        draw.ID,
+		SourceDrawDate:      draw.DrawDate,
+		RolloverAmount:      rolloverAmount,
+		DestinationDrawID:   destinationID,
+		DestinationDrawDate: destinationDate,
+		DestinationDrawType: targetType,
+		Reason:              reason,
+		CreatedAt:           time.Now(),
+	}
+}
+
+// rolloverTargetsDraw reports whether a rollover is paid into the given draw: it is destined for
+// the draw's date and for its type, or for a draw of any type
+func rolloverTargetsDraw(rollover *models.JackpotRollover, draw *models.Draw) bool {
+	if rollover.SourceDrawID == draw.ID {
+		return false
+	}
+	return rollover.DestinationDrawType == "" || rollover.DestinationDrawType == draw.DrawType
+}
+
+// rolloverTargetsType reports whether a pending rollover goes to a draw of the given type. A
+// rollover open to any type goes to the draw scheduled on its destination date, or, while none
+// is, to the type held on that weekday.
+func (s *DrawServiceImpl) rolloverTargetsType(ctx context.Context, rollover *models.JackpotRollover, drawType *models.DrawTypeDefinition) bool {
+	if rollover.DestinationDrawType != "" {
+		return rollover.DestinationDrawType == drawType.Code
+	}
+	if destination, err := s.rolloverDestination(ctx, rollover); err == nil {
+		return destination.DrawType == drawType.Code
+	}
+	for _, weekday := range drawType.Weekdays {
+		if weekday == int(rollover.DestinationDrawDate.Weekday()) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyIncomingRollovers sets the draw's jackpot to its base amount plus every rollover still in
+// force for it. Rollovers usually arrive after the destination draw was scheduled, so the amount
+// computed at scheduling is only a projection and this runs once the draw is claimed for execution.
+func (s *DrawServiceImpl) applyIncomingRollovers(ctx context.Context, draw *models.Draw) error {
+	rollovers, err := s.jackpotRolloverRepo.FindRolloversByDestinationDate(ctx, draw.DrawDate)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return fmt.Errorf("failed to fetch incoming jackpot rollovers: %w", err)
+	}
+	incoming := 0.0
+	count := 0
+	for _, rollover := range rollovers {
+		if !rolloverTargetsDraw(rollover, draw) {
+			continue
+		}
+		incoming += rollover.RolloverAmount
+		count++
+	}
+	if incoming != draw.RolloverAmount {
+		draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Incoming rollovers changed since scheduling: %.2f, was %.2f", incoming, draw.RolloverAmount))
+	}
+	draw.RolloverAmount = incoming
+	draw.CalculatedJackpotAmount = draw.BaseJackpotAmount + incoming
+	draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("Jackpot: base %.2f + %d incoming rollovers %.2f = %.2f", draw.BaseJackpotAmount, count, incoming, draw.CalculatedJackpotAmount))
+	return nil
+}
+
+// ClaimPrize records that a winner has claimed their prize. Jackpot claims are refused once
+// the claim deadline has passed. The winner is re-validated against the blacklist first: a
+// winner blacklisted since the draw is marked INELIGIBLE, and a jackpot goes to the next
+// valid alternate.
+func (s *DrawServiceImpl) ClaimPrize(ctx context.Context, winnerID primitive.ObjectID) (*models.Winner, error) {
+	winner, err := s.winnerRepo.FindByID(ctx, winnerID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("winner with ID %s not found", winnerID.Hex())
+		}
+		return nil, fmt.Errorf("failed to fetch winner: %w", err)
+	}
+	if winner.ClaimStatus != models.ClaimStatusPending {
+		return winner, fmt.Errorf("prize cannot be claimed in status %s", winner.ClaimStatus)
+	}
+	now := time.Now()
+	if !winner.ClaimDeadline.IsZero() && now.After(winner.ClaimDeadline) {
+		return winner, fmt.Errorf("claim window expired on %s", winner.ClaimDeadline.Format(time.RFC3339))
+	}
+
+	blacklisted, err := s.isBlacklisted(ctx, winner.UserID, winner.MSISDN)
+	if err != nil {
+		slog.Error("Failed to re-validate winner against blacklist", "error", err, "winnerId", winnerID)
+		return nil, fmt.Errorf("failed to re-validate winner: %w", err)
+	}
+	if blacklisted {
+		note := "Blacklisted after selection, prize withheld"
+		if winner.PrizeCategory == models.JackpotCategory {
+			err = s.replaceJackpotWinner(ctx, winner, models.ClaimStatusIneligible, models.JackpotCandidateBlacklisted, note, now)
+		} else {
+			winner.ClaimStatus = models.ClaimStatusIneligible
+			winner.ClaimNotes = note
+			err = s.updateClaimStatus(ctx, winner, models.ClaimStatusPending)
+		}
+		if err != nil {
+			slog.Error("Failed to disqualify blacklisted winner", "error", err, "winnerId", winnerID)
+			return nil, fmt.Errorf("failed to disqualify blacklisted winner: %w", err)
+		}
+		slog.Warn("Claim refused, winner is blacklisted", "winnerId", winnerID, "category", winner.PrizeCategory, "msisdn", maskMsisdn(winner.MSISDN))
+		return winner, errors.New("winner is blacklisted and no longer eligible")
+	}
+
+	winner.ClaimStatus = models.ClaimStatusProcessing
+	winner.ClaimDate = now
+	if err := s.updateClaimStatus(ctx, winner, models.ClaimStatusPending); err != nil {
+		slog.Error("Failed to record prize claim", "error", err, "winnerId", winnerID)
+		return nil, fmt.Errorf("failed to record prize claim: %w", err)
+	}
+	slog.Info("Prize claimed", "winnerId", winnerID, "category", winner.PrizeCategory, "msisdn", maskMsisdn(winner.MSISDN))
+	return winner, nil
+}
+
+// ProcessExpiredJackpotClaims forfeits jackpot winners whose claim deadline has passed and
+// promotes the next valid alternate of each draw. Only when a draw's chain is exhausted is the
+// jackpot rolled over. It returns the number of forfeited claims.
+func (s *DrawServiceImpl) ProcessExpiredJackpotClaims(ctx context.Context, now time.Time) (int, error) {
+	expired, err := s.winnerRepo.FindExpiredClaims(ctx, models.JackpotCategory, now)
+	if err != nil {
+		slog.Error("Failed to find expired jackpot claims", "error", err)
+		return 0, fmt.Errorf("failed to find expired jackpot claims: %w", err)
+	}
+
+	processed := 0
+	for _, winner := range expired {
+		if err := s.forfeitJackpotWinner(ctx, winner, now); err != nil {
+			slog.Error("Failed to forfeit expired jackpot claim", "error", err, "winnerId", winner.ID, "drawId", winner.DrawID)
+			continue
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// forfeitJackpotWinner marks an unclaimed jackpot winner as forfeited and hands the jackpot
+// to the next valid candidate of the draw, or rolls it over when none is left.
+func (s *DrawServiceImpl) forfeitJackpotWinner(ctx context.Context, winner *models.Winner, now time.Time) error {
+	note := fmt.Sprintf("Not claimed before %s", winner.ClaimDeadline.Format(time.RFC3339))
+	return s.replaceJackpotWinner(ctx, winner, models.ClaimStatusForfeited, models.JackpotCandidateForfeited, note, now)
+}
+
+// updateClaimStatus saves a winner whose stored claim status is still expected
+func (s *DrawServiceImpl) updateClaimStatus(ctx context.Context, winner *models.Winner, expected models.ClaimStatus) error {
+	updated, err := s.winnerRepo.ReplaceIfClaimStatus(ctx, winner, expected)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrClaimStatusChanged
+	}
+	return nil
+}
+
+// replaceJackpotWinner takes the jackpot away from its current winner and hands it to the next
+// valid candidate of the draw, or rolls it over when none is left. Candidates blacklisted since
+// the draw are skipped. The winner, the promoted alternate (or the rollover) and the draw are
+// written in one transaction where supported, and only if the winner's claim status is still
+// the one read, so concurrent claims and expiry sweeps replace a winner at most once.
+func (s *DrawServiceImpl) replaceJackpotWinner(ctx context.Context, winner *models.Winner, claimStatus models.ClaimStatus, candidateStatus models.JackpotCandidateStatus, note string, now time.Time) error {
+	draw, err := s.drawRepo.FindByID(ctx, winner.DrawID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch draw %s: %w", winner.DrawID.Hex(), err)
+	}
+	if draw.Status != models.DrawStatusCompleted {
+		return fmt.Errorf("draw %s is %s, its jackpot winner cannot be replaced", draw.ID.Hex(), draw.Status)
+	}
+
+	previousStatus := winner.ClaimStatus
+	winner.ClaimStatus = claimStatus
+	winner.ClaimNotes = note
+	draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("%s: Jackpot winner %s %s: %s", now.Format(time.RFC3339), maskMsisdn(winner.MSISDN), strings.ToLower(string(claimStatus)), note))
+
+	// Mark the replaced candidate, then find the next valid one after it
+	next := -1
+	for i := range draw.JackpotCandidates {
+		candidate := &draw.JackpotCandidates[i]
+		if candidate.WinnerID == winner.ID {
+			candidate.Status = candidateStatus
+			candidate.UpdatedAt = now
+			continue
+		}
+		if next != -1 || candidate.Rank <= winner.AlternateRank || candidate.Status != models.JackpotCandidateValid {
+			continue
+		}
+		blacklisted, err := s.isBlacklisted(ctx, candidate.UserID, candidate.MSISDN)
+		if err != nil {
+			return fmt.Errorf("failed to re-validate jackpot alternate #%d: %w", candidate.Rank, err)
+		}
+		if blacklisted {
+			candidate.Status = models.JackpotCandidateBlacklisted
+			candidate.UpdatedAt = now
+			draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("%s: Jackpot alternate #%d %s skipped, blacklisted since the draw", now.Format(time.RFC3339), candidate.Rank, maskMsisdn(candidate.MSISDN)))
+			continue
+		}
+		next = i
+	}
+
+	var promoted *models.Winner
+	var rollover *models.JackpotRollover
+	if next == -1 {
+		draw.JackpotWinnerValidationStatus = models.JackpotValidationNoValidCandidate
+		draw.ExecutionLog = append(draw.ExecutionLog, "No valid jackpot alternate left, rolling over the jackpot")
+		rollover = s.newRolloverRecord(ctx, draw, now, string(models.JackpotValidationNoValidCandidate))
+	} else {
+		candidate := &draw.JackpotCandidates[next]
+		promoted = s.newJackpotWinnerRecord(draw, &models.User{ID: candidate.UserID, MSISDN: candidate.MSISDN}, candidate.Rank, now)
+		if promoted == nil {
+			return fmt.Errorf("draw %s has no jackpot prize category", draw.ID.Hex())
+		}
+		candidate.Status = models.JackpotCandidateAwarded
+		candidate.WinnerID = promoted.ID
+		candidate.UpdatedAt = now
+		draw.JackpotWinnerMsisdn = candidate.MSISDN
+		draw.ExecutionLog = append(draw.ExecutionLog, fmt.Sprintf("%s: Jackpot alternate #%d %s promoted, claim by %s", now.Format(time.RFC3339), candidate.Rank, maskMsisdn(candidate.MSISDN), promoted.ClaimDeadline.Format(time.RFC3339)))
+	}
+
+	// The function may be retried by the driver on transient errors, so it only writes
+	return s.txManager.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := s.updateClaimStatus(txCtx, winner, previousStatus); err != nil {
+			return fmt.Errorf("failed to mark winner %s: %w", strings.ToLower(string(claimStatus)), err)
+		}
+		if promoted != nil {
+			if err := s.winnerRepo.Create(txCtx, promoted); err != nil {
+				return fmt.Errorf("failed to create promoted jackpot winner: %w", err)
+			}
+		}
+		if rollover != nil {
+			if err := s.jackpotRolloverRepo.Create(txCtx, rollover); err != nil {
+				return fmt.Errorf("failed to create jackpot rollover record: %w", err)
+			}
+		}
+		updated, err := s.drawRepo.ReplaceIfStatus(txCtx, draw, models.DrawStatusCompleted)
+		if err != nil {
+			return fmt.Errorf("failed to update draw %s: %w", draw.ID.Hex(), err)
+		}
+		if !updated {
+			return fmt.Errorf("draw %s changed status while its jackpot winner was being replaced", draw.ID.Hex())
+		}
+		return nil
+	})
+}
+
+// isBlacklisted reports whether a user is on the blacklist or flagged as blacklisted on their
+// profile. A user record that no longer exists only leaves the blacklist to check.
+func (s *DrawServiceImpl) isBlacklisted(ctx context.Context, userID primitive.ObjectID, msisdn string) (bool, error) {
+	listed, err := s.blacklistRepo.IsBlacklisted(ctx, msisdn)
+	if err != nil {
+		return false, fmt.Errorf("failed to check blacklist: %w", err)
+	}
+	if listed || userID.IsZero() {
+		return listed, nil
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to fetch user %s: %w", userID.Hex(), err)
+	}
+	return user.IsBlacklisted, nil
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// LoginLimitOptions configures failed-login throttling
+type LoginLimitOptions struct {
+	MaxFailures      int           // Failures per email before it is locked
+	MaxFailuresPerIP int           // Failures per client IP before it is locked
+	Lockout          time.Duration // How long a lock lasts; failures older than this are forgotten
+	BackoffBase      time.Duration // Wait after the first failure for an email, doubling with each further one
+}
+
+// ErrLoginThrottled is matched by every LoginThrottledError
+var ErrLoginThrottled = errors.New("too many failed login attempts")
+
+// ErrNothingToUnlock is returned when unlocking an email or IP that has no failed logins
+var ErrNothingToUnlock = errors.New("no failed logins recorded for this email or IP")
+
+// LoginThrottledError is returned while an email or IP must wait before trying again
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool // Locked after too many failures, rather than backing off
+}
+
+func (e *LoginThrottledError) Error() string {
+	wait := e.RetryAfter.Round(time.Second)
+	if e.Locked {
+		return fmt.Sprintf("%v; locked for %s", ErrLoginThrottled, wait)
+	}
+	return fmt.Sprintf("%v; try again in %s", ErrLoginThrottled, wait)
+}
+
+// Is makes errors.Is(err, ErrLoginThrottled) match
+func (e *LoginThrottledError) Is(target error) bool {
+	return target == ErrLoginThrottled
+}
+
+// normalizeLoginEmail makes counters independent of how the email was typed
+func normalizeLoginEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// backoffDelay is the wait after a number of consecutive failures: BackoffBase doubled for
+// each failure after the first, capped at Lockout
+func (s *authService) backoffDelay(failures int) time.Duration {
+	delay := s.loginLimits.BackoffBase
+	for i := 1; i < failures && delay < s.loginLimits.Lockout; i++ {
+		delay *= 2
+	}
+	if delay > s.loginLimits.Lockout {
+		delay = s.loginLimits.Lockout
+	}
+	return delay
+}
+
+// checkLoginAllowed refuses a login attempt while the email or IP is locked, or while the
+// email is backing off after a recent failure
+func (s *authService) checkLoginAllowed(ctx context.Context, email, clientIP string) error {
+	now := time.Now()
+	check := func(kind, value string) error {
+		if value == "" {
+			return nil
+		}
+		attempt, err := s.attemptRepo.Find(ctx, kind, value)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil) {
+			return &LoginThrottledError{RetryAfter: attempt.LockedUntil.Sub(now), Locked: true}
+		}
+		if kind == models.LoginAttemptEmail && now.Sub(attempt.LastFailureAt) < s.loginLimits.Lockout {
+			if wait := attempt.LastFailureAt.Add(s.backoffDelay(attempt.Failures)).Sub(now); wait > 0 {
+				return &LoginThrottledError{RetryAfter: wait}
+			}
+		}
+		return nil
+	}
+	if err := check(models.LoginAttemptEmail, normalizeLoginEmail(email)); err != nil {
+		return err
+	}
+	return check(models.LoginAttemptIP, clientIP)
+}
+
+// recordLoginFailure counts a failed password or two-factor code for the email and IP, and
+// locks whichever reaches its limit
+func (s *authService) recordLoginFailure(ctx context.Context, email, clientIP string) {
+	now := time.Now()
+	record := func(kind, value string, limit int) {
+		if value == "" || limit <= 0 {
+			return
+		}
+		attempt, err := s.attemptRepo.RecordFailure(ctx, kind, value, now, now.Add(-s.loginLimits.Lockout))
+		if err != nil {
+			log.Printf("[ERROR] Failed to record login failure for %s %s: %v", kind, value, err)
+			return
+		}
+		// Every failure at or past the limit locks, so a lock that failed is retried by the next
+		// failure; Lock reports a lockout only once while it lasts
+		if attempt.Failures < limit {
+			return
+		}
+		until := now.Add(s.loginLimits.Lockout)
+		locked, err := s.attemptRepo.Lock(ctx, kind, value, now, until)
+		if err != nil {
+			log.Printf("[ERROR] Failed to lock %s %s: %v", kind, value, err)
+			return
+		}
+		if !locked {
+			return
+		}
+		log.Printf("[WARN] Login locked for %s %s until %s after %d failures", kind, value, until.Format(time.RFC3339), attempt.Failures)
+		s.audit.Record(ctx, &models.AuditEvent{
+			Action:     models.AuditActionLoginLockout,
+			TargetType: "login_" + kind,
+			TargetID:   value,
+			IPAddress:  clientIP,
+			Details:    map[string]string{"failures": strconv.Itoa(attempt.Failures), "lockedUntil": until.UTC().Format(time.RFC3339)},
+		})
+	}
+	record(models.LoginAttemptEmail, normalizeLoginEmail(email), s.loginLimits.MaxFailures)
+	record(models.LoginAttemptIP, clientIP, s.loginLimits.MaxFailuresPerIP)
+}
+
+// clearLoginFailures forgets the failures of an email once its admin has logged in. IP
+// counters are left to expire, so one valid account cannot reset them.
+func (s *authService) clearLoginFailures(ctx context.Context, email string) {
+	if _, err := s.attemptRepo.Clear(ctx, models.LoginAttemptEmail, normalizeLoginEmail(email)); err != nil {
+		log.Printf("[ERROR] Failed to clear login failures for %s: %v", email, err)
+	}
+}
+
+// ListLoginLockouts returns the emails and IPs that are locked now
+func (s *authService) ListLoginLockouts(ctx context.Context) ([]*models.LoginAttempt, error) {
+	return s.attemptRepo.FindLocked(ctx, time.Now())
+}
+
+// UnlockLogin lifts the lock and clears the failures of an email, an IP or both
+func (s *authService) UnlockLogin(ctx context.Context, email, clientIP, actor string) error {
+	targets := map[string]string{
+		models.LoginAttemptEmail: normalizeLoginEmail(email),
+		models.LoginAttemptIP:    strings.TrimSpace(clientIP),
+	}
+	unlocked := false
+	for kind, value := range targets {
+		if value == "" {
+			continue
+		}
+		cleared, err := s.attemptRepo.Clear(ctx, kind, value)
+		if err != nil {
+			return err
+		}
+		if !cleared {
+			continue
+		}
+		unlocked = true
+		log.Printf("[INFO] UnlockLogin: %s %s unlocked by %s", kind, value, actor)
+		s.audit.Record(ctx, &models.AuditEvent{
+			Action:     models.AuditActionLoginUnlock,
+			ActorID:    actor,
+			TargetType: "login_" + kind,
+			TargetID:   value,
+		})
+	}
+	if !unlocked {
+		return ErrNothingToUnlock
+	}
+	return nil
+}
@@ -0,0 +1,387 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/exp/slog"
+)
+
+var (
+	// ErrPrizeStructureVersionNotFound is returned when a prize structure version does not exist
+	ErrPrizeStructureVersionNotFound = errors.New("prize structure version not found")
+	// ErrPrizeStructureVersionNotDraft is returned when approving or rejecting a version that is
+	// no longer a DRAFT, including one reviewed concurrently
+	ErrPrizeStructureVersionNotDraft = errors.New("prize structure version is not a DRAFT")
+)
+
+// prizeStructureVersionAttempts bounds the retries of a draft that lost its version number to
+// a concurrent draft
+const prizeStructureVersionAttempts = 5
+
+// calendarDay returns midnight UTC of t's date, the form draw dates are stored in
+func calendarDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// validatePrizeStructure checks that a prize structure can be used by a draw
+func validatePrizeStructure(prizes []models.Prize) error {
+	if len(prizes) == 0 {
+		return errors.New("a prize structure needs at least one prize")
+	}
+	seen := make(map[string]bool, len(prizes))
+	for _, prize := range prizes {
+		category := strings.TrimSpace(prize.Category)
+		if category == "" {
+			return errors.New("every prize needs a category")
+		}
+		if seen[category] {
+			return fmt.Errorf("prize category %s is listed more than once", category)
+		}
+		seen[category] = true
+		if prize.Amount <= 0 {
+			return fmt.Errorf("prize amount of %s must be positive", category)
+		}
+		if prize.NumWinners < 1 {
+			return fmt.Errorf("numWinners of %s must be at least 1", category)
+		}
+		if category == models.JackpotCategory && prize.NumWinners != 1 {
+			return fmt.Errorf("the %s category has exactly one winner", models.JackpotCategory)
+		}
+	}
+	return nil
+}
+
+// effectivePrizeStructure returns the approved version of a draw type's prizes in force on a
+// date. Until a draw type has an approved version, its prizes come from the legacy SystemConfig
+// key and are reported as version 0.
+func (s *DrawServiceImpl) effectivePrizeStructure(ctx context.Context, drawType *models.DrawTypeDefinition, date time.Time) (*models.PrizeStructureVersion, error) {
+	version, err := s.prizeStructureRepo.FindEffective(ctx, drawType.Code, calendarDay(date))
+	if err == nil {
+		return version, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		slog.Error("Failed to fetch effective prize structure", "error", err, "drawType", drawType.Code, "date", date)
+		return nil, fmt.Errorf("failed to fetch prize structure of %s: %w", drawType.Code, err)
+	}
+
+	prizes, err := s.prizeStructureByKey(ctx, drawType.PrizeStructureKey)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PrizeStructureVersion{
+		DrawType: drawType.Code,
+		Prizes:   prizes,
+		Status:   models.PrizeStructureApproved,
+		Notes:    "legacy configuration " + drawType.PrizeStructureKey,
+	}, nil
+}
+
+// GetEffectivePrizeStructure returns the prize structure version a draw of the given type and
+// date is scheduled with
+func (s *DrawServiceImpl) GetEffectivePrizeStructure(ctx context.Context, drawType string, date time.Time) (*models.PrizeStructureVersion, error) {
+	drawTypeDef, err := s.drawTypeDefinition(ctx, drawType)
+	if err != nil {
+		return nil, err
+	}
+	return s.effectivePrizeStructure(ctx, drawTypeDef, date)
+}
+
+// ListPrizeStructureVersions returns every version of a draw type's prizes, newest first
+func (s *DrawServiceImpl) ListPrizeStructureVersions(ctx context.Context, drawType string) ([]*models.PrizeStructureVersion, error) {
+	drawTypeDef, err := s.drawTypeDefinition(ctx, drawType)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.prizeStructureRepo.FindByDrawType(ctx, drawTypeDef.Code)
+	if err != nil {
+		slog.Error("Failed to list prize structure versions", "error", err, "drawType", drawTypeDef.Code)
+		return nil, err
+	}
+	return versions, nil
+}
+
+// prizeStructureVersion loads one version of a draw type's prizes
+func (s *DrawServiceImpl) prizeStructureVersion(ctx context.Context, drawType string, version int) (*models.PrizeStructureVersion, error) {
+	result, err := s.prizeStructureRepo.FindByVersion(ctx, drawType, version)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("%w: %s v%d", ErrPrizeStructureVersionNotFound, drawType, version)
+		}
+		slog.Error("Failed to fetch prize structure version", "error", err, "drawType", drawType, "version", version)
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPrizeStructureVersion returns one version of a draw type's prizes
+func (s *DrawServiceImpl) GetPrizeStructureVersion(ctx context.Context, drawType string, version int) (*models.PrizeStructureVersion, error) {
+	drawTypeDef, err := s.drawTypeDefinition(ctx, drawType)
+	if err != nil {
+		return nil, err
+	}
+	return s.prizeStructureVersion(ctx, drawTypeDef.Code, version)
+}
+
+// CreatePrizeStructureVersion stages a new DRAFT version of a draw type's prizes. It is used
+// by draws dated on or after effectiveFrom once it is approved.
+func (s *DrawServiceImpl) CreatePrizeStructureVersion(ctx context.Context, drawType string, prizes []models.Prize, effectiveFrom time.Time, notes, createdBy string) (*models.PrizeStructureVersion, error) {
+	drawTypeDef, err := s.drawTypeDefinition(ctx, drawType)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePrizeStructure(prizes); err != nil {
+		return nil, err
+	}
+	effectiveFrom = calendarDay(effectiveFrom)
+	if effectiveFrom.Before(calendarDay(time.Now().UTC())) {
+		return nil, fmt.Errorf("effective date %s is in the past", effectiveFrom.Format("2006-01-02"))
+	}
+
+	// The next version number is taken under a unique index; a concurrent draft that took it
+	// first makes Create fail with a duplicate key, and the number is read again
+	var version *models.PrizeStructureVersion
+	for attempt := 1; ; attempt++ {
+		existing, err := s.prizeStructureRepo.FindByDrawType(ctx, drawTypeDef.Code)
+		if err != nil {
+			slog.Error("Failed to list prize structure versions", "error", err, "drawType", drawTypeDef.Code)
+			return nil, err
+		}
+		nextVersion := 1
+		if len(existing) > 0 {
+			nextVersion = existing[0].Version + 1
+		}
+
+		version = &models.PrizeStructureVersion{
+			DrawType:      drawTypeDef.Code,
+			Version:       nextVersion,
+			Prizes:        prizes,
+			EffectiveFrom: effectiveFrom,
+			Status:        models.PrizeStructureDraft,
+			Notes:         notes,
+			CreatedBy:     createdBy,
+		}
+		err = s.prizeStructureRepo.Create(ctx, version)
+		if err == nil {
+			break
+		}
+		if !mongo.IsDuplicateKeyError(err) || attempt == prizeStructureVersionAttempts {
+			slog.Error("Failed to create prize structure version", "error", err, "drawType", drawTypeDef.Code, "version", nextVersion)
+			return nil, err
+		}
+		slog.Warn("Prize structure version taken by a concurrent draft, retrying", "drawType", drawTypeDef.Code, "version", nextVersion)
+	}
+	slog.Info("Prize structure version created", "drawType", version.DrawType, "version", version.Version, "effectiveFrom", effectiveFrom, "createdBy", createdBy)
+	s.recordPrizeStructureAudit(ctx, models.AuditActionPrizeStructureCreated, nil, version)
+	return version, nil
+}
+
+// ApprovePrizeStructureVersion approves a DRAFT version. Draws of the type already scheduled
+// on or after its effective date are re-priced with it, so every draw carries the version in
+// force on its date.
+func (s *DrawServiceImpl) ApprovePrizeStructureVersion(ctx context.Context, drawType string, versionNumber int, approvedBy string) (*models.PrizeStructureVersion, error) {
+	drawTypeDef, err := s.drawTypeDefinition(ctx, drawType)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.prizeStructureVersion(ctx, drawTypeDef.Code, versionNumber)
+	if err != nil {
+		return nil, err
+	}
+	if version.Status != models.PrizeStructureDraft {
+		return version, fmt.Errorf("%w: only DRAFT versions can be approved (current: %s)", ErrPrizeStructureVersionNotDraft, version.Status)
+	}
+	if version.EffectiveFrom.Before(calendarDay(time.Now().UTC())) {
+		return version, fmt.Errorf("effective date %s has passed; create a new version", version.EffectiveFrom.Format("2006-01-02"))
+	}
+
+	draft := *version
+	now := time.Now()
+	version.Status = models.PrizeStructureApproved
+	version.ReviewedBy = approvedBy
+	version.ReviewedAt = &now
+	replaced, err := s.prizeStructureRepo.ReplaceIfStatus(ctx, version, models.PrizeStructureDraft)
+	if err != nil {
+		slog.Error("Failed to approve prize structure version", "error", err, "drawType", version.DrawType, "version", version.Version)
+		return nil, err
+	}
+	if !replaced {
+		slog.Warn("Prize structure version was reviewed concurrently", "drawType", version.DrawType, "version", version.Version)
+		return nil, fmt.Errorf("%w: %s v%d was reviewed concurrently", ErrPrizeStructureVersionNotDraft, version.DrawType, version.Version)
+	}
+	slog.Info("Prize structure version approved", "drawType", version.DrawType, "version", version.Version, "approvedBy", approvedBy)
+	s.recordPrizeStructureAudit(ctx, models.AuditActionPrizeStructureApproved, &draft, version)
+
+	if err := s.repriceScheduledDraws(ctx, drawTypeDef, version.EffectiveFrom); err != nil {
+		return version, err
+	}
+	return version, nil
+}
+
+// repriceScheduledDraws gives scheduled draws of a type dated on or after from the prize
+// structure now in force on their date
+func (s *DrawServiceImpl) repriceScheduledDraws(ctx context.Context, drawType *models.DrawTypeDefinition, from time.Time) error {
+	draws, err := s.drawRepo.FindByStatus(ctx, string(models.DrawStatusScheduled))
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		slog.Error("Failed to fetch scheduled draws for re-pricing", "error", err, "drawType", drawType.Code)
+		return fmt.Errorf("failed to fetch scheduled draws for re-pricing: %w", err)
+	}
+	for _, draw := range draws {
+		if draw.DrawType != drawType.Code || draw.DrawDate.Before(from) {
+			continue
+		}
+		effective, err := s.effectivePrizeStructure(ctx, drawType, draw.DrawDate)
+		if err != nil {
+			return err
+		}
+		if effective.Version == draw.PrizeStructureVersion {
+			continue
+		}
+		previous := draw.PrizeStructureVersion
+		draw.Prizes = append([]models.Prize(nil), effective.Prizes...)
+		draw.PrizeStructureVersion = effective.Version
+		draw.UpdatedAt = time.Now()
+		replaced, err := s.drawRepo.ReplaceIfStatus(ctx, draw, models.DrawStatusScheduled)
+		if err != nil {
+			slog.Error("Failed to re-price scheduled draw", "error", err, "drawId", draw.ID)
+			return fmt.Errorf("failed to re-price draw %s: %w", draw.ID.Hex(), err)
+		}
+		if !replaced {
+			slog.Warn("Draw left SCHEDULED state before it could be re-priced", "drawId", draw.ID)
+			continue
+		}
+		slog.Info("Scheduled draw re-priced", "drawId", draw.ID, "date", draw.DrawDate, "fromVersion", previous, "toVersion", effective.Version)
+	}
+	return nil
+}
+
+// RejectPrizeStructureVersion rejects a DRAFT version; it is kept for the record but never used
+func (s *DrawServiceImpl) RejectPrizeStructureVersion(ctx context.Context, drawType string, versionNumber int, rejectedBy, reason string) (*models.PrizeStructureVersion, error) {
+	drawTypeDef, err := s.drawTypeDefinition(ctx, drawType)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.prizeStructureVersion(ctx, drawTypeDef.Code, versionNumber)
+	if err != nil {
+		return nil, err
+	}
+	if version.Status != models.PrizeStructureDraft {
+		return version, fmt.Errorf("%w: only DRAFT versions can be rejected (current: %s)", ErrPrizeStructureVersionNotDraft, version.Status)
+	}
+
+	draft := *version
+	now := time.Now()
+	version.Status = models.PrizeStructureRejected
+	version.ReviewedBy = rejectedBy
+	version.ReviewedAt = &now
+	version.RejectionReason = reason
+	replaced, err := s.prizeStructureRepo.ReplaceIfStatus(ctx, version, models.PrizeStructureDraft)
+	if err != nil {
+		slog.Error("Failed to reject prize structure version", "error", err, "drawType", version.DrawType, "version", version.Version)
+		return nil, err
+	}
+	if !replaced {
+		slog.Warn("Prize structure version was reviewed concurrently", "drawType", version.DrawType, "version", version.Version)
+		return nil, fmt.Errorf("%w: %s v%d was reviewed concurrently", ErrPrizeStructureVersionNotDraft, version.DrawType, version.Version)
+	}
+	slog.Info("Prize structure version rejected", "drawType", version.DrawType, "version", version.Version, "rejectedBy", rejectedBy, "reason", reason)
+	s.recordPrizeStructureAudit(ctx, models.AuditActionPrizeStructureRejected, &draft, version)
+	return version, nil
+}
+
+// recordPrizeStructureAudit records an audit event for a prize structure version, diffing it
+// against before (nil for a new version). The target is "<DRAW TYPE>/v<version>".
+func (s *DrawServiceImpl) recordPrizeStructureAudit(ctx context.Context, action string, before, after *models.PrizeStructureVersion) {
+	var previous interface{}
+	if before != nil {
+		previous = before
+	}
+	s.audit.Record(ctx, &models.AuditEvent{
+		Action:     action,
+		TargetType: models.AuditTargetPrizeStructure,
+		TargetID:   fmt.Sprintf("%s/v%d", after.DrawType, after.Version),
+		Changes:    auditChanges(previous, after, "updatedAt"),
+	})
+}
+
+// DiffPrizeStructureVersions compares two versions of a draw type's prizes. Version 0 stands
+// for the legacy SystemConfig structure.
+func (s *DrawServiceImpl) DiffPrizeStructureVersions(ctx context.Context, drawType string, fromVersion, toVersion int) (*models.PrizeStructureDiff, error) {
+	drawTypeDef, err := s.drawTypeDefinition(ctx, drawType)
+	if err != nil {
+		return nil, err
+	}
+	load := func(number int) ([]models.Prize, error) {
+		if number == 0 {
+			return s.prizeStructureByKey(ctx, drawTypeDef.PrizeStructureKey)
+		}
+		version, err := s.prizeStructureVersion(ctx, drawTypeDef.Code, number)
+		if err != nil {
+			return nil, err
+		}
+		return version.Prizes, nil
+	}
+	oldPrizes, err := load(fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	newPrizes, err := load(toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := diffPrizes(oldPrizes, newPrizes)
+	diff.DrawType = drawTypeDef.Code
+	diff.FromVersion = fromVersion
+	diff.ToVersion = toVersion
+	return diff, nil
+}
+
+// diffPrizes lists the categories added, removed or changed between two prize structures,
+// ordered by category
+func diffPrizes(oldPrizes, newPrizes []models.Prize) *models.PrizeStructureDiff {
+	diff := &models.PrizeStructureDiff{Changes: []models.PrizeCategoryChange{}}
+	oldByCategory := make(map[string]models.Prize, len(oldPrizes))
+	for _, prize := range oldPrizes {
+		oldByCategory[prize.Category] = prize
+		diff.OldTotalPayout += prize.Amount * float64(prize.NumWinners)
+	}
+	newByCategory := make(map[string]models.Prize, len(newPrizes))
+	for _, prize := range newPrizes {
+		newByCategory[prize.Category] = prize
+		diff.NewTotalPayout += prize.Amount * float64(prize.NumWinners)
+	}
+
+	for category, oldPrize := range oldByCategory {
+		newPrize, ok := newByCategory[category]
+		switch {
+		case !ok:
+			diff.Changes = append(diff.Changes, models.PrizeCategoryChange{
+				Category: category, Change: models.PrizeChangeRemoved,
+				OldAmount: oldPrize.Amount, OldNumWinners: oldPrize.NumWinners,
+			})
+		case oldPrize.Amount != newPrize.Amount || oldPrize.NumWinners != newPrize.NumWinners:
+			diff.Changes = append(diff.Changes, models.PrizeCategoryChange{
+				Category: category, Change: models.PrizeChangeUpdated,
+				OldAmount: oldPrize.Amount, NewAmount: newPrize.Amount,
+				OldNumWinners: oldPrize.NumWinners, NewNumWinners: newPrize.NumWinners,
+			})
+		}
+	}
+	for category, newPrize := range newByCategory {
+		if _, ok := oldByCategory[category]; !ok {
+			diff.Changes = append(diff.Changes, models.PrizeCategoryChange{
+				Category: category, Change: models.PrizeChangeAdded,
+				NewAmount: newPrize.Amount, NewNumWinners: newPrize.NumWinners,
+			})
+		}
+	}
+	sort.Slice(diff.Changes, func(i, j int) bool { return diff.Changes[i].Category < diff.Changes[j].Category })
+	return diff
+}
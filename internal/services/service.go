@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/pkg/mtnapi"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -12,17 +13,59 @@ import (
 type DrawService interface {
 	GetDrawConfig(ctx context.Context) (map[string]interface{}, error) // Added based on handler usage
 	GetPrizeStructure(ctx context.Context, drawType string) ([]models.Prize, error) // Updated return type
-	UpdatePrizeStructure(ctx context.Context, drawType string, structure []models.Prize) error // Updated param type
+	GetEffectivePrizeStructure(ctx context.Context, drawType string, date time.Time) (*models.PrizeStructureVersion, error)
+	ListPrizeStructureVersions(ctx context.Context, drawType string) ([]*models.PrizeStructureVersion, error)
+	GetPrizeStructureVersion(ctx context.Context, drawType string, version int) (*models.PrizeStructureVersion, error)
+	CreatePrizeStructureVersion(ctx context.Context, drawType string, prizes []models.Prize, effectiveFrom time.Time, notes, createdBy string) (*models.PrizeStructureVersion, error)
+	ApprovePrizeStructureVersion(ctx context.Context, drawType string, version int, approvedBy string) (*models.PrizeStructureVersion, error)
+	RejectPrizeStructureVersion(ctx context.Context, drawType string, version int, rejectedBy, reason string) (*models.PrizeStructureVersion, error)
+	DiffPrizeStructureVersions(ctx context.Context, drawType string, fromVersion, toVersion int) (*models.PrizeStructureDiff, error)
+	UpdateBaseJackpot(ctx context.Context, drawType string, amount float64) error // Scheduled draws keep their jackpot
+	ListDrawTypes(ctx context.Context) ([]*models.DrawTypeDefinition, error)
+	GetDrawType(ctx context.Context, code string) (*models.DrawTypeDefinition, error)
+	CreateDrawType(ctx context.Context, drawType *models.DrawTypeDefinition) (*models.DrawTypeDefinition, error)
+	UpdateDrawType(ctx context.Context, code string, drawType *models.DrawTypeDefinition) (*models.DrawTypeDefinition, error) // Key changes need RequestDrawTypeKeysChange
+	UpdateDrawTypeKeys(ctx context.Context, code, prizeStructureKey, baseJackpotKey string) (*models.DrawTypeDefinition, error) // Run by an approved DRAW_TYPE_KEYS request
+	DeleteDrawType(ctx context.Context, code string) error // Refused while draws of the type are scheduled
+	EnsureDefaultDrawTypes(ctx context.Context) error     // Registers DAILY and SATURDAY when missing
+	GetEligibilityWindow(ctx context.Context, drawType string) (*models.EligibilityWindowRule, error)
+	UpdateEligibilityWindow(ctx context.Context, drawType string, rule models.EligibilityWindowRule) error // Validated; scheduled draws keep their snapshot
+	GetWinFrequencyRules(ctx context.Context) (*models.WinFrequencyRules, error)
+	UpdateWinFrequencyRules(ctx context.Context, rules models.WinFrequencyRules) error // Validated; executed draws keep their snapshot
 	ScheduleDraw(ctx context.Context, drawDate time.Time, drawType string, eligibleDigits []int, useDefaultDigits bool) (*models.Draw, error)
-	ExecuteDraw(ctx context.Context, drawID primitive.ObjectID) (*models.Draw, error)
+	ExecuteDraw(ctx context.Context, drawID primitive.ObjectID, idempotencyKey string) (*models.Draw, error) // Same key returns the first execution's outcome
+	SimulateDraw(ctx context.Context, drawID primitive.ObjectID, iterations, participantLimit int) (*models.DrawSimulation, error) // Read-only preview, optional Monte Carlo
+	VerifyDraw(ctx context.Context, drawID primitive.ObjectID) (*models.DrawVerification, error) // Replays selection from the revealed seed
+	GetDrawParticipants(ctx context.Context, drawID primitive.ObjectID, page, limit int) ([]*models.DrawParticipant, int64, error)
+	ExportDrawParticipants(ctx context.Context, drawID primitive.ObjectID, fn func(*models.DrawParticipant) error) error
+	VoidDraw(ctx context.Context, drawID primitive.ObjectID, reason, actor string) (*models.Draw, error)  // actor is the admin's JWT subject
+	ReRunDraw(ctx context.Context, drawID primitive.ObjectID, reason, actor string) (*models.Draw, error) // Returns the new SCHEDULED draw
 	GetDrawByID(ctx context.Context, drawID primitive.ObjectID) (*models.Draw, error) // Ensure this is implemented
 	GetWinnersByDrawID(ctx context.Context, drawID primitive.ObjectID) ([]*models.Winner, error)
+	ClaimPrize(ctx context.Context, winnerID primitive.ObjectID) (*models.Winner, error)
+	ProcessExpiredJackpotClaims(ctx context.Context, now time.Time) (int, error) // Forfeits unclaimed jackpots and promotes alternates
+	RecoverStaleDraws(ctx context.Context, now time.Time) (int, error)           // Rolls back or fails draws left EXECUTING by a dead process
 	GetDraws(ctx context.Context, startDate, endDate time.Time) ([]*models.Draw, error)
 	GetJackpotHistory(ctx context.Context, startDate, endDate time.Time) ([]map[string]interface{}, error) // Added based on handler usage
 	GetDefaultDigitsForDay(ctx context.Context, dayOfWeek time.Weekday) ([]int, error) // Added based on handler, updated return type
 	GetDrawByDate(ctx context.Context, date time.Time) (*models.Draw, error)    // Added based on handler
-	GetJackpotStatus(ctx context.Context) (*models.JackpotStatus, error)      // Added for build error
-	AllocatePointsForTopup(ctx context.Context, userID primitive.ObjectID, amount float64, transactionTime time.Time) (int, error) // Added for point allocation logic
+	GetJackpotStatus(ctx context.Context, drawType string) (*models.JackpotStatus, error) // "" reports the headline draw type
+	AllocatePointsForTopup(ctx context.Context, userID primitive.ObjectID, amount float64, transactionTime time.Time, transactionRef string) (int, error) // Idempotent per transactionRef
+}
+
+// ApprovalService defines the interface for maker-checker approvals of sensitive draw operations
+type ApprovalService interface {
+	RequestDrawExecution(ctx context.Context, drawID primitive.ObjectID, reason, requestedBy string) (*models.ApprovalRequest, error)
+	RequestDrawVoid(ctx context.Context, drawID primitive.ObjectID, reason, requestedBy string) (*models.ApprovalRequest, error)
+	RequestPrizeStructureApproval(ctx context.Context, drawType string, version int, reason, requestedBy string) (*models.ApprovalRequest, error)
+	RequestBaseJackpotChange(ctx context.Context, drawType string, amount float64, reason, requestedBy string) (*models.ApprovalRequest, error)
+	RequestDrawTypeKeysChange(ctx context.Context, drawType, prizeStructureKey, baseJackpotKey, reason, requestedBy string) (*models.ApprovalRequest, error) // "" keeps a key
+	RequestScheduledDrawExecution(ctx context.Context, drawID primitive.ObjectID) (*models.ApprovalRequest, bool, error) // Filed by the scheduler at most once per draw
+	ListApprovals(ctx context.Context, status models.ApprovalStatus) ([]*models.ApprovalRequest, error) // "" for all, newest first
+	GetApproval(ctx context.Context, id primitive.ObjectID) (*models.ApprovalRequest, error)
+	Approve(ctx context.Context, id primitive.ObjectID, approvedBy, note string) (*models.ApprovalRequest, error) // Runs the action; approver must differ from the requester. A scheduler request is endorsed by its first approval and stays PENDING
+	Reject(ctx context.Context, id primitive.ObjectID, rejectedBy, note string) (*models.ApprovalRequest, error)
+	ExpirePending(ctx context.Context, now time.Time) (int64, error)
 }
 
 // TopupService defines the interface for topup-related operations
@@ -35,6 +78,7 @@ type TopupService interface {
 	CreateTopup(ctx context.Context, topup *models.Topup) error
 	ProcessTopups(ctx context.Context, startDate, endDate time.Time) (int, error)
 	GetTopupCount(ctx context.Context) (int64, error)
+	EnqueueWebhookTopup(ctx context.Context, recharge mtnapi.TopupResponse) (bool, error) // False when the recharge was already queued
 }
 
 // NotificationService defines the interface for notification-related operations
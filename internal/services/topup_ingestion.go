@@ -0,0 +1,338 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/pkg/mtnapi"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// topupIngestionCheckpointKey is the SystemConfig key of the ingestion high-water mark
+	topupIngestionCheckpointKey = "topup_ingestion_checkpoint"
+	// topupIngestionLockName is the leader lock held while ingesting, so runs never overlap
+	topupIngestionLockName = "topup_ingestion"
+	topupIngestionLockTTL  = 10 * time.Minute
+	// topupIngestionCheckpointAttempts bounds the retries of a checkpoint write that raced another
+	topupIngestionCheckpointAttempts = 5
+	// topupIngestionChunk is the part of the range fetched, processed and checkpointed at a time
+	topupIngestionChunk = 24 * time.Hour
+	// topupFeedSettleDelay keeps ingestion behind the clock, as recharges reach the feed late
+	topupFeedSettleDelay = 5 * time.Minute
+)
+
+var (
+	// ErrTopupIngestionRunning is returned when another ProcessTopups run holds the ingestion lock
+	ErrTopupIngestionRunning = errors.New("topup ingestion is already running")
+	// ErrDuplicateTopup is returned with the stored topup when its TransactionRef was already recorded
+	ErrDuplicateTopup = errors.New("a topup with this transaction reference was already recorded")
+	// ErrInvalidTopup wraps the reason a topup cannot be recorded
+	ErrInvalidTopup = errors.New("invalid topup")
+)
+
+// TopupFeed is the source of recharges ProcessTopups ingests; *mtnapi.Client implements it
+type TopupFeed interface {
+	GetTopups(ctx context.Context, startDate, endDate time.Time) ([]mtnapi.TopupResponse, error)
+}
+
+// ProcessTopups ingests the MTN recharges dated in [startDate, endDate), one day at a time:
+// each recharge is recorded once per TransactionRef, its subscriber matched or created, and its
+// points allocated. The part of the range covered by the checkpoint is skipped, so reruns and
+// overlapping ranges are safe. It returns the number of topups processed by this run.
+func (s *TopupServiceImpl) ProcessTopups(ctx context.Context, startDate, endDate time.Time) (int, error) {
+	if s.feed == nil {
+		return 0, errors.New("MTN topup feed is not configured")
+	}
+	if settled := time.Now().Add(-topupFeedSettleDelay); endDate.After(settled) {
+		endDate = settled // Later recharges may still be on their way; the next run picks them up
+	}
+	if !startDate.Before(endDate) {
+		return 0, fmt.Errorf("start date %s is not before end date %s", startDate.Format(time.RFC3339), endDate.Format(time.RFC3339))
+	}
+
+	owner := primitive.NewObjectID().Hex()
+	if acquired, err := s.lockRepo.TryAcquire(ctx, topupIngestionLockName, owner, topupIngestionLockTTL); err != nil {
+		return 0, fmt.Errorf("failed to acquire topup ingestion lock: %w", err)
+	} else if !acquired {
+		return 0, ErrTopupIngestionRunning
+	}
+	defer func() {
+		if err := s.lockRepo.Release(context.Background(), topupIngestionLockName, owner); err != nil {
+			slog.Error("Failed to release topup ingestion lock", "error", err)
+		}
+	}()
+	// A day of recharges can take longer than the lock TTL, so the lock is renewed while the run
+	// lasts and the run is cancelled if another one takes it over
+	ctx, stopRenewing := s.renewIngestionLock(ctx, owner)
+	defer stopRenewing()
+
+	checkpoint, _, err := s.loadIngestionCheckpoint(ctx)
+	if err != nil {
+		return 0, err
+	}
+	from := startDate
+	if checkpoint != nil && !from.Before(checkpoint.CoveredFrom) && from.Before(checkpoint.Through) {
+		from = checkpoint.Through
+	}
+
+	processed := 0
+	for from.Before(endDate) {
+		to := from.Add(topupIngestionChunk)
+		if to.After(endDate) {
+			to = endDate
+		}
+		if ctx.Err() != nil {
+			return processed, context.Cause(ctx) // ErrTopupIngestionRunning once the lock is lost
+		}
+
+		count, through, ingestErr := s.ingestWindow(ctx, from, to)
+		processed += count
+		if errors.Is(context.Cause(ctx), ErrTopupIngestionRunning) {
+			slog.Warn("Topup ingestion stopped after losing its lock", "through", through, "processed", processed)
+			return processed, ErrTopupIngestionRunning
+		}
+		if through.After(from) {
+			if err := s.advanceIngestionCheckpoint(ctx, startDate, through); err != nil {
+				return processed, err
+			}
+		}
+		if ingestErr != nil {
+			slog.Error("Topup ingestion stopped", "error", ingestErr, "through", through, "processed", processed)
+			return processed, ingestErr
+		}
+		from = to
+	}
+
+	slog.Info("Topup ingestion finished", "start", startDate, "end", endDate, "processed", processed)
+	return processed, nil
+}
+
+// renewIngestionLock renews the ingestion lock every third of its TTL until the returned stop
+// function is called. The returned context is cancelled with ErrTopupIngestionRunning as its
+// cause when the lock is lost.
+func (s *TopupServiceImpl) renewIngestionLock(ctx context.Context, owner string) (context.Context, func()) {
+	runCtx, cancel := context.WithCancelCause(ctx)
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(topupIngestionLockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-runCtx.Done():
+				return
+			case <-ticker.C:
+				held, err := s.lockRepo.TryAcquire(runCtx, topupIngestionLockName, owner, topupIngestionLockTTL)
+				if err != nil {
+					slog.Error("Failed to renew topup ingestion lock", "error", err)
+					continue // The lease survives a couple of failed renewals
+				}
+				if !held {
+					slog.Warn("Topup ingestion lock lost, stopping the run", "owner", owner)
+					cancel(ErrTopupIngestionRunning)
+					return
+				}
+			}
+		}
+	}()
+	return runCtx, func() {
+		cancel(nil)
+		<-exited
+	}
+}
+
+// ingestWindow fetches and processes the recharges dated in [from, to), oldest first. It returns
+// how many were processed and the time before which every recharge was, which is short of to
+// when a fetch or a recharge failed.
+func (s *TopupServiceImpl) ingestWindow(ctx context.Context, from, to time.Time) (int, time.Time, error) {
+	responses, fetchErr := s.feed.GetTopups(ctx, from, to)
+	through := to
+	var partial *mtnapi.PartialResultError
+	if errors.As(fetchErr, &partial) {
+		through = partial.Through
+	} else if fetchErr != nil {
+		return 0, from, fmt.Errorf("failed to fetch topups from MTN: %w", fetchErr)
+	}
+	sort.SliceStable(responses, func(i, j int) bool { return responses[i].Date.Before(responses[j].Date) })
+
+	processed := 0
+	for _, response := range responses {
+		if !response.Successful() {
+			slog.Info("Skipping unsuccessful recharge", "transactionRef", response.TransactionRef, "status", response.Status)
+			continue
+		}
+		stored, _, err := s.recordTopup(ctx, &models.Topup{
+			MSISDN:         response.MSISDN,
+			Amount:         response.Amount,
+			Channel:        models.TopupChannelMTNFeed,
+			Date:           response.Date,
+			TransactionRef: response.TransactionRef,
+		})
+		if err == nil && stored.Processed {
+			continue // Ingested by an earlier run
+		}
+		if err == nil {
+			_, err = s.processTopup(ctx, stored)
+		}
+		if err != nil {
+			return processed, response.Date, fmt.Errorf("failed to ingest topup %s: %w", response.TransactionRef, err)
+		}
+		processed++
+	}
+	if fetchErr != nil {
+		return processed, through, fmt.Errorf("failed to fetch topups from MTN: %w", fetchErr)
+	}
+	return processed, through, nil
+}
+
+// recordTopup validates a topup and stores it unless its TransactionRef is already recorded.
+// It returns the stored topup and whether it was inserted.
+func (s *TopupServiceImpl) recordTopup(ctx context.Context, topup *models.Topup) (*models.Topup, bool, error) {
+	topup.MSISDN = strings.TrimSpace(topup.MSISDN)
+	topup.TransactionRef = strings.TrimSpace(topup.TransactionRef)
+	if err := validateTopup(topup.TransactionRef, topup.MSISDN, topup.Amount, topup.Date); err != nil {
+		return nil, false, err
+	}
+	stored, created, err := s.topupRepo.UpsertByTransactionRef(ctx, topup)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to record topup %s: %w", topup.TransactionRef, err)
+	}
+	return stored, created, nil
+}
+
+// validateTopup checks a topup has what recording and point allocation rely on
+func validateTopup(transactionRef, msisdn string, amount float64, date time.Time) error {
+	switch {
+	case transactionRef == "":
+		return fmt.Errorf("%w: transactionRef is required", ErrInvalidTopup)
+	case msisdn == "":
+		return fmt.Errorf("%w: msisdn is required", ErrInvalidTopup)
+	case amount <= 0:
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidTopup)
+	case date.IsZero():
+		return fmt.Errorf("%w: date is required", ErrInvalidTopup)
+	}
+	return nil
+}
+
+// processTopup allocates the points of a recorded topup to its subscriber, who is created
+// (not opted in) on their first topup, and marks it processed. Processed topups are left alone.
+func (s *TopupServiceImpl) processTopup(ctx context.Context, topup *models.Topup) (int, error) {
+	if topup.Processed {
+		return topup.PointsEarned, nil
+	}
+	user, err := s.findOrCreateUser(ctx, topup.MSISDN)
+	if err != nil {
+		return 0, err
+	}
+	points, err := s.drawService.AllocatePointsForTopup(ctx, user.ID, topup.Amount, topup.Date, topup.TransactionRef)
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate points: %w", err)
+	}
+	if err := s.topupRepo.MarkProcessed(ctx, topup.ID, points); err != nil {
+		return 0, fmt.Errorf("failed to mark topup processed: %w", err)
+	}
+	topup.Processed = true
+	topup.PointsEarned = points
+	return points, nil
+}
+
+// findOrCreateUser returns the user with the MSISDN, creating them if they are new. When a
+// concurrent caller creates the same user first, the unique msisdn index refuses the second
+// insert and the user it created is returned.
+func (s *TopupServiceImpl) findOrCreateUser(ctx context.Context, msisdn string) (*models.User, error) {
+	user, err := s.userRepo.FindByMSISDN(ctx, msisdn)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("failed to find user %s: %w", maskMsisdn(msisdn), err)
+	}
+	user = &models.User{ID: primitive.NewObjectID(), MSISDN: msisdn}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			existing, findErr := s.userRepo.FindByMSISDN(ctx, msisdn)
+			if findErr != nil {
+				return nil, fmt.Errorf("failed to re-read user %s created concurrently: %w", maskMsisdn(msisdn), findErr)
+			}
+			return existing, nil
+		}
+		return nil, fmt.Errorf("failed to create user %s: %w", maskMsisdn(msisdn), err)
+	}
+	slog.Info("Created user on first top-up", "msisdn", maskMsisdn(msisdn), "userId", user.ID)
+	return user, nil
+}
+
+// loadIngestionCheckpoint returns the stored checkpoint and its stored value, or nil for both
+// before the first ingestion
+func (s *TopupServiceImpl) loadIngestionCheckpoint(ctx context.Context) (*models.TopupIngestionCheckpoint, interface{}, error) {
+	config, err := s.systemConfigRepo.FindByKey(ctx, topupIngestionCheckpointKey)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to fetch topup ingestion checkpoint: %w", err)
+	}
+	// Stored as a JSON string, like the prize structures
+	jsonString, ok := config.Value.(string)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid topup ingestion checkpoint format in config %s (expected JSON string)", topupIngestionCheckpointKey)
+	}
+	var checkpoint models.TopupIngestionCheckpoint
+	if err := json.Unmarshal([]byte(jsonString), &checkpoint); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse topup ingestion checkpoint JSON: %w", err)
+	}
+	return &checkpoint, jsonString, nil
+}
+
+// advanceIngestionCheckpoint records that [runStart, through) has been ingested. The checkpoint
+// only ever covers one contiguous range, so [runStart, through) is merged into it only when
+// the two overlap or touch; a run that would leave a gap on either side is not recorded. The
+// merge is made with the stored checkpoint and written only if that is still the one stored,
+// so its range only ever grows, even when another run wrote it in the meantime.
+func (s *TopupServiceImpl) advanceIngestionCheckpoint(ctx context.Context, runStart, through time.Time) error {
+	for attempt := 1; attempt <= topupIngestionCheckpointAttempts; attempt++ {
+		checkpoint, stored, err := s.loadIngestionCheckpoint(ctx)
+		if err != nil {
+			return err
+		}
+		next := models.TopupIngestionCheckpoint{CoveredFrom: runStart, Through: through}
+		if checkpoint != nil {
+			if runStart.After(checkpoint.Through) || through.Before(checkpoint.CoveredFrom) {
+				return nil
+			}
+			if !through.After(checkpoint.Through) && !runStart.Before(checkpoint.CoveredFrom) {
+				return nil // Already covered
+			}
+			if checkpoint.CoveredFrom.Before(next.CoveredFrom) {
+				next.CoveredFrom = checkpoint.CoveredFrom
+			}
+			if checkpoint.Through.After(next.Through) {
+				next.Through = checkpoint.Through
+			}
+		}
+		next.UpdatedAt = time.Now()
+		jsonBytes, err := json.Marshal(next)
+		if err != nil {
+			return fmt.Errorf("failed to marshal topup ingestion checkpoint: %w", err)
+		}
+		swapped, err := s.systemConfigRepo.CompareAndSwapByKey(ctx, topupIngestionCheckpointKey, stored, string(jsonBytes))
+		if err != nil {
+			return fmt.Errorf("failed to store topup ingestion checkpoint: %w", err)
+		}
+		if swapped {
+			return nil
+		}
+		slog.Warn("Topup ingestion checkpoint changed concurrently, merging again", "attempt", attempt)
+	}
+	return fmt.Errorf("failed to store topup ingestion checkpoint: it kept changing concurrently")
+}
@@ -18,22 +18,35 @@ import (
 var _ TopupService = (*TopupServiceImpl)(nil)
 
 type TopupServiceImpl struct {
+	topupRepo            repositories.TopupRepository
+	webhookRepo          repositories.TopupWebhookEventRepository // Queue of recharges pushed by MTN
 	userRepo             repositories.UserRepository
 	pointTransactionRepo repositories.PointTransactionRepository
+	systemConfigRepo     repositories.SystemConfigRepository // Holds the ingestion checkpoint
+	lockRepo             repositories.LeaderLockRepository   // Keeps ingestion runs from overlapping
 	 drawService          DrawService // Inject DrawService for point allocation
+	feed                 TopupFeed   // MTN recharge feed for ProcessTopups
+	webhookQueued        chan struct{} // Wakes this replica's webhook worker when an event is queued
 }
 
-func NewTopupService(userRepo repositories.UserRepository, pointTransactionRepo repositories.PointTransactionRepository, drawService DrawService) *TopupServiceImpl {
+func NewTopupService(topupRepo repositories.TopupRepository, webhookRepo repositories.TopupWebhookEventRepository, userRepo repositories.UserRepository, pointTransactionRepo repositories.PointTransactionRepository, systemConfigRepo repositories.SystemConfigRepository, lockRepo repositories.LeaderLockRepository, drawService DrawService, feed TopupFeed) *TopupServiceImpl {
 	return &TopupServiceImpl{
+		topupRepo:            topupRepo,
+		webhookRepo:          webhookRepo,
 		userRepo:             userRepo,
 		pointTransactionRepo: pointTransactionRepo,
+		systemConfigRepo:     systemConfigRepo,
+		lockRepo:             lockRepo,
 		 drawService:          drawService,
+		feed:                 feed,
+		webhookQueued:        make(chan struct{}, 1),
 	}
 }
 
 // ProcessTopup processes a top-up event, finds the user, and allocates points.
-func (s *TopupServiceImpl) ProcessTopup(ctx context.Context, msisdn string, amount float64, transactionTime time.Time) error {
-	 slog.Info("Processing top-up", "msisdn", msisdn, "amount", amount, "time", transactionTime)
+// The points are allocated once per transactionRef.
+func (s *TopupServiceImpl) ProcessTopup(ctx context.Context, msisdn string, amount float64, transactionTime time.Time, transactionRef string) error {
+	 slog.Info("Processing top-up", "msisdn", msisdn, "amount", amount, "time", transactionTime, "transactionRef", transactionRef)
 
 	 // 1. Find the user by MSISDN
 	 user, err := s.userRepo.FindByMSISDN(ctx, msisdn)
@@ -49,7 +62,7 @@ func (s *TopupServiceImpl) ProcessTopup(ctx context.Context, msisdn string, amou
 
 	 // 2. Allocate points using the DrawService's method
 	 // The DrawService now contains the canonical point calculation logic
-	 pointsToAdd, err := s.drawService.AllocatePointsForTopup(ctx, user.ID, amount, transactionTime)
+	 pointsToAdd, err := s.drawService.AllocatePointsForTopup(ctx, user.ID, amount, transactionTime, transactionRef)
 	 if err != nil {
 		 // Error is already logged within AllocatePointsForTopup
 		 return fmt.Errorf("failed to allocate points for top-up: %w", err)
@@ -75,41 +88,63 @@ func calculatePoints(amount float64) int {
 
 
 
-// CreateTopup is required by the TopupService interface but is not yet implemented
-// as the TopupRepository is currently undefined.
+// CreateTopup records a topup and allocates its points. A topup whose TransactionRef is
+// already recorded is not credited again: topup is filled with the stored one and
+// ErrDuplicateTopup is returned.
 func (s *TopupServiceImpl) CreateTopup(ctx context.Context, topup *models.Topup) error {
-	 slog.Warn("CreateTopup called but not implemented")
-	 return errors.New("CreateTopup functionality is not yet implemented")
+	topup.ID = primitive.NilObjectID
+	topup.Processed = false
+	topup.PointsEarned = 0
+	if topup.Channel == "" {
+		topup.Channel = models.TopupChannelManual
+	}
+	if topup.Date.IsZero() {
+		topup.Date = time.Now()
+	}
+	stored, created, err := s.recordTopup(ctx, topup)
+	if err != nil {
+		return err
+	}
+	_, err = s.processTopup(ctx, stored) // Also completes a duplicate whose processing failed earlier
+	*topup = *stored
+	if err != nil {
+		return err
+	}
+	if !created {
+		return ErrDuplicateTopup
+	}
+	return nil
 }
 
-// GetTopupByID is required by the TopupService interface but is not yet implemented.
+// GetTopupByID retrieves a topup by ID
 func (s *TopupServiceImpl) GetTopupByID(ctx context.Context, id primitive.ObjectID) (*models.Topup, error) {
-	 slog.Warn("GetTopupByID called but not implemented")
-	 return nil, errors.New("GetTopupByID functionality is not yet implemented")
+	return s.topupRepo.FindByID(ctx, id)
 }
 
-// GetTopupsByMSISDN is required by the TopupService interface but is not yet implemented.
+// GetTopupsByMSISDN retrieves a subscriber's topups, newest first
 func (s *TopupServiceImpl) GetTopupsByMSISDN(ctx context.Context, msisdn string, page, limit int) ([]*models.Topup, error) {
-	 slog.Warn("GetTopupsByMSISDN called but not implemented")
-	 return nil, errors.New("GetTopupsByMSISDN functionality is not yet implemented")
+	page, limit = normalizePage(page, limit)
+	return s.topupRepo.FindByMSISDN(ctx, msisdn, page, limit)
 }
 
-// GetTopupsByDateRange is required by the TopupService interface but is not yet implemented.
+// GetTopupsByDateRange retrieves the topups dated between start and end, newest first
 func (s *TopupServiceImpl) GetTopupsByDateRange(ctx context.Context, start, end time.Time, page, limit int) ([]*models.Topup, error) {
-	 slog.Warn("GetTopupsByDateRange called but not implemented")
-	 return nil, errors.New("GetTopupsByDateRange functionality is not yet implemented")
+	page, limit = normalizePage(page, limit)
+	return s.topupRepo.FindByDateRange(ctx, start, end, page, limit)
 }
 
-// ProcessTopups is required by the TopupService interface but is not yet implemented.
-func (s *TopupServiceImpl) ProcessTopups(ctx context.Context, startDate, endDate time.Time) (int, error) {
-	 slog.Warn("ProcessTopups called but not implemented")
-	 return 0, errors.New("ProcessTopups functionality is not yet implemented")
-}
-
-// GetTopupCount is required by the TopupService interface but is not yet implemented.
+// GetTopupCount counts all recorded topups
 func (s *TopupServiceImpl) GetTopupCount(ctx context.Context) (int64, error) {
-	 slog.Warn("GetTopupCount called but not implemented")
-	 return 0, errors.New("GetTopupCount functionality is not yet implemented")
+	return s.topupRepo.Count(ctx)
 }
 
-
+// normalizePage defaults a page below 1 to the first and a limit below 1 to 10
+func normalizePage(page, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	return page, limit
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/pkg/mtnapi"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/exp/slog"
+)
+
+// TopupWebhookOptions configures the worker that processes recharges queued by the topup webhook
+type TopupWebhookOptions struct {
+	PollInterval time.Duration // How often the queue is checked for due events and expired leases
+	Lease        time.Duration // How long a claimed event is reserved for its worker
+	MaxAttempts  int           // Attempts before an event is given up as FAILED
+	RetryBackoff time.Duration // Wait before the second attempt, doubling with each further one
+	MaxBackoff   time.Duration // Cap on the wait between attempts
+}
+
+// EnqueueWebhookTopup validates a recharge MTN pushed and queues it for processing. It returns
+// false when the TransactionRef is already queued, so a redelivery is acknowledged without
+// being credited twice.
+func (s *TopupServiceImpl) EnqueueWebhookTopup(ctx context.Context, recharge mtnapi.TopupResponse) (bool, error) {
+	event := &models.TopupWebhookEvent{
+		TransactionRef: strings.TrimSpace(recharge.TransactionRef),
+		MSISDN:         strings.TrimSpace(recharge.MSISDN),
+		Amount:         recharge.Amount,
+		Date:           recharge.Date,
+	}
+	if err := validateTopup(event.TransactionRef, event.MSISDN, event.Amount, event.Date); err != nil {
+		return false, err
+	}
+	queued, err := s.webhookRepo.Enqueue(ctx, event)
+	if err != nil {
+		return false, err
+	}
+	if queued {
+		select {
+		case s.webhookQueued <- struct{}{}:
+		default: // The worker is already due to look
+		}
+	}
+	return queued, nil
+}
+
+// RunWebhookQueue blocks until ctx is cancelled, processing queued webhook recharges as they
+// arrive and on every poll. Every replica runs it; claims are atomic, and an event whose
+// worker died is picked up again once its lease expires.
+func (s *TopupServiceImpl) RunWebhookQueue(ctx context.Context, opts TopupWebhookOptions) {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = 2 * time.Minute
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 10
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = 30 * time.Second
+	}
+	if opts.MaxBackoff < opts.RetryBackoff {
+		opts.MaxBackoff = time.Hour
+	}
+	slog.Info("Topup webhook worker started", "pollInterval", opts.PollInterval, "maxAttempts", opts.MaxAttempts)
+	ticker := time.NewTicker(opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.drainWebhookQueue(ctx, opts)
+		select {
+		case <-ctx.Done():
+			slog.Info("Topup webhook worker stopped")
+			return
+		case <-ticker.C:
+		case <-s.webhookQueued:
+		}
+	}
+}
+
+// drainWebhookQueue processes due events until none is left
+func (s *TopupServiceImpl) drainWebhookQueue(ctx context.Context, opts TopupWebhookOptions) {
+	for ctx.Err() == nil {
+		event, err := s.webhookRepo.ClaimDue(ctx, time.Now(), opts.Lease)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return
+		}
+		if err != nil {
+			slog.Error("Topup webhook worker: failed to claim queued event", "error", err)
+			return
+		}
+		s.processWebhookEvent(ctx, event, opts)
+	}
+}
+
+// processWebhookEvent records and processes a queued recharge like any other topup. A recharge
+// already recorded through the MTN feed or an earlier attempt is not credited again.
+func (s *TopupServiceImpl) processWebhookEvent(ctx context.Context, event *models.TopupWebhookEvent, opts TopupWebhookOptions) {
+	stored, _, err := s.recordTopup(ctx, &models.Topup{
+		MSISDN:         event.MSISDN,
+		Amount:         event.Amount,
+		Channel:        models.TopupChannelWebhook,
+		Date:           event.Date,
+		TransactionRef: event.TransactionRef,
+	})
+	if err == nil {
+		_, err = s.processTopup(ctx, stored)
+	}
+	if err == nil {
+		if err := s.webhookRepo.MarkProcessed(ctx, event.TransactionRef, stored.ID, stored.PointsEarned); err != nil {
+			slog.Error("Topup webhook worker: failed to mark event processed", "error", err, "transactionRef", event.TransactionRef)
+		}
+		return
+	}
+
+	giveUp := event.Attempts >= opts.MaxAttempts || errors.Is(err, ErrInvalidTopup)
+	wait := opts.RetryBackoff
+	for i := 1; i < event.Attempts && wait < opts.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > opts.MaxBackoff {
+		wait = opts.MaxBackoff
+	}
+	if markErr := s.webhookRepo.MarkFailed(ctx, event.TransactionRef, err.Error(), time.Now().Add(wait), giveUp); markErr != nil {
+		slog.Error("Topup webhook worker: failed to record failed attempt", "error", markErr, "transactionRef", event.TransactionRef)
+	}
+	if giveUp {
+		slog.Error("Topup webhook worker: gave up on event", "error", err, "transactionRef", event.TransactionRef, "attempts", event.Attempts)
+		return
+	}
+	slog.Warn("Topup webhook worker: attempt failed, will retry", "error", err, "transactionRef", event.TransactionRef, "attempts", event.Attempts, "retryIn", wait)
+}
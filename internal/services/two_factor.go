@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/pkg/totp"
+	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	mfaTokenTTL       = 5 * time.Minute
+	totpSkew          = 1 // Accept the previous and next 30 second step for clock drift
+	recoveryCodeCount = 10
+)
+
+var (
+	// ErrInvalidMFAToken is returned when the login challenge token is invalid or has expired
+	ErrInvalidMFAToken = errors.New("invalid or expired login challenge; log in again")
+	// ErrInvalidTOTPCode is returned for a wrong, reused or expired two-factor code
+	ErrInvalidTOTPCode = errors.New("invalid two-factor code")
+	// ErrTOTPAlreadyEnabled is returned when enrolling an admin who has already enrolled
+	ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTOTPNotEnabled is returned when an operation needs an enrolled admin
+	ErrTOTPNotEnabled = errors.New("two-factor authentication is not enabled")
+	// ErrTOTPNotEnrolling is returned when confirming enrolment before starting it
+	ErrTOTPNotEnrolling = errors.New("two-factor enrolment has not been started")
+	// ErrTOTPRequired is returned when turning off two-factor authentication for a role that
+	// requires it, or refreshing a session of an admin who has yet to enrol
+	ErrTOTPRequired = errors.New("two-factor authentication is mandatory for this role")
+)
+
+// totpRequired reports whether admins with a role must use two-factor authentication
+func (s *authService) totpRequired(role string) bool {
+	for _, r := range s.totpRequiredRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// issueMFAToken signs the token that carries a login from the password step to the code step.
+// Its "typ" keeps JWTAuthMiddleware from accepting it as an access token.
+func (s *authService) issueMFAToken(adminUser *models.AdminUser) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub": adminUser.ID.Hex(),
+		"typ": "mfa",
+		"iat": now.Unix(),
+		"exp": now.Add(mfaTokenTTL).Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.jwtSecret))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate login challenge: %w", err)
+	}
+	return token, nil
+}
+
+// adminFromMFAToken returns the admin a login challenge token was issued to
+func (s *authService) adminFromMFAToken(ctx context.Context, mfaToken string) (*models.AdminUser, error) {
+	token, err := jwt.Parse(mfaToken, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(s.jwtSecret), nil
+	})
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != "mfa" {
+		return nil, ErrInvalidMFAToken
+	}
+	sub, _ := claims["sub"].(string)
+	adminID, err := primitive.ObjectIDFromHex(sub)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+	adminUser, err := s.adminUserRepo.FindByID(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+	if adminUser.Disabled {
+		return nil, ErrAccountDisabled
+	}
+	return adminUser, nil
+}
+
+// newRecoveryCodes returns recovery codes (formatted xxxxx-xxxxx) and their hashes
+func newRecoveryCodes() ([]string, []string, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+		}
+		code := strings.ToLower(encoding.EncodeToString(b))[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hashSecretToken(code))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode strips the separator and case a recovery code may be typed with
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// checkTOTP validates a code against secret and records its time step, so the same code
+// cannot be used twice. The time step is stored with a conditional update: of concurrent
+// requests carrying the same code, only the first is accepted.
+func (s *authService) checkTOTP(ctx context.Context, adminUser *models.AdminUser, secret, code string) error {
+	counter, ok, err := totp.Validate(secret, code, time.Now(), totpSkew)
+	if err != nil {
+		return fmt.Errorf("failed to validate two-factor code: %w", err)
+	}
+	if !ok || counter <= adminUser.TOTPLastCounter {
+		return ErrInvalidTOTPCode
+	}
+	consumed, err := s.adminUserRepo.ConsumeTOTPCounter(ctx, adminUser.ID, counter)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrInvalidTOTPCode
+	}
+	adminUser.TOTPLastCounter = counter
+	return nil
+}
+
+// checkSecondFactor accepts a TOTP code or an unused recovery code, which is then used up.
+// Either is consumed in the database, so adminUser need not be saved afterwards.
+func (s *authService) checkSecondFactor(ctx context.Context, adminUser *models.AdminUser, code string) error {
+	if err := s.checkTOTP(ctx, adminUser, adminUser.TOTPSecret, code); !errors.Is(err, ErrInvalidTOTPCode) {
+		return err
+	}
+	hash := hashSecretToken(normalizeRecoveryCode(code))
+	for i, stored := range adminUser.RecoveryCodes {
+		if stored != hash {
+			continue
+		}
+		consumed, err := s.adminUserRepo.ConsumeRecoveryCode(ctx, adminUser.ID, hash)
+		if err != nil {
+			return err
+		}
+		if !consumed {
+			return ErrInvalidTOTPCode // Used by a concurrent request
+		}
+		adminUser.RecoveryCodes = append(adminUser.RecoveryCodes[:i], adminUser.RecoveryCodes[i+1:]...)
+		log.Printf("[INFO] Admin %s used a recovery code; %d left", adminUser.ID.Hex(), len(adminUser.RecoveryCodes))
+		return nil
+	}
+	return ErrInvalidTOTPCode
+}
+
+// saveAdmin stores changes to an admin user
+func (s *authService) saveAdmin(ctx context.Context, adminUser *models.AdminUser) error {
+	adminUser.UpdatedAt = time.Now()
+	if err := s.adminUserRepo.Update(ctx, adminUser); err != nil {
+		return fmt.Errorf("failed to update admin user: %w", err)
+	}
+	return nil
+}
+
+// beginEnrollment generates a new pending secret
+func (s *authService) beginEnrollment(ctx context.Context, adminUser *models.AdminUser) (*models.TOTPEnrollment, error) {
+	if adminUser.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	adminUser.TOTPPendingSecret = secret
+	if err := s.saveAdmin(ctx, adminUser); err != nil {
+		return nil, err
+	}
+	return &models.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.totpIssuer, adminUser.Email, secret),
+	}, nil
+}
+
+// confirmEnrollment enables two-factor authentication once a code from the pending secret
+// checks out, and returns the recovery codes
+func (s *authService) confirmEnrollment(ctx context.Context, adminUser *models.AdminUser, code string) ([]string, error) {
+	if adminUser.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if adminUser.TOTPPendingSecret == "" {
+		return nil, ErrTOTPNotEnrolling
+	}
+	if err := s.checkTOTP(ctx, adminUser, adminUser.TOTPPendingSecret, code); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	adminUser.TOTPEnabled = true
+	adminUser.TOTPSecret = adminUser.TOTPPendingSecret
+	adminUser.TOTPPendingSecret = ""
+	adminUser.RecoveryCodes = hashes
+	if err := s.saveAdmin(ctx, adminUser); err != nil {
+		return nil, err
+	}
+	log.Printf("[INFO] Admin %s enabled two-factor authentication", adminUser.ID.Hex())
+	return codes, nil
+}
+
+// VerifyLogin completes a login that needs a second factor. For an admin who had to enrol,
+// the code confirms the enrolment and the result carries their recovery codes.
+func (s *authService) VerifyLogin(ctx context.Context, req *models.VerifyLoginRequest, clientIP string) (*models.LoginResult, error) {
+	adminUser, err := s.adminFromMFAToken(ctx, req.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+	// Wrong codes count towards the same limits as wrong passwords
+	if err := s.checkLoginAllowed(ctx, adminUser.Email, clientIP); err != nil {
+		return nil, err
+	}
+	var recoveryCodes []string
+	if adminUser.TOTPEnabled {
+		err = s.checkSecondFactor(ctx, adminUser, req.Code)
+	} else {
+		recoveryCodes, err = s.confirmEnrollment(ctx, adminUser, req.Code)
+	}
+	if errors.Is(err, ErrInvalidTOTPCode) {
+		log.Printf("[WARN] VerifyLogin: Invalid two-factor code for %s", adminUser.Email)
+		s.recordLoginFailure(ctx, adminUser.Email, clientIP)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.clearLoginFailures(ctx, adminUser.Email)
+
+	tokens, err := s.issueTokens(ctx, adminUser, primitive.NewObjectID())
+	if err != nil {
+		return nil, err
+	}
+	adminUser.Password = ""
+	return &models.LoginResult{Tokens: tokens, User: adminUser, RecoveryCodes: recoveryCodes}, nil
+}
+
+// BeginLoginEnrollment starts enrolment for an admin whose role requires two-factor
+// authentication, during login
+func (s *authService) BeginLoginEnrollment(ctx context.Context, mfaToken string) (*models.TOTPEnrollment, error) {
+	adminUser, err := s.adminFromMFAToken(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.beginEnrollment(ctx, adminUser)
+}
+
+// BeginTOTPEnrollment starts optional enrolment for a logged-in admin
+func (s *authService) BeginTOTPEnrollment(ctx context.Context, adminID primitive.ObjectID) (*models.TOTPEnrollment, error) {
+	adminUser, err := s.adminUserRepo.FindByID(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+	return s.beginEnrollment(ctx, adminUser)
+}
+
+// ConfirmTOTPEnrollment enables two-factor authentication for a logged-in admin
+func (s *authService) ConfirmTOTPEnrollment(ctx context.Context, adminID primitive.ObjectID, code string) ([]string, error) {
+	adminUser, err := s.adminUserRepo.FindByID(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+	return s.confirmEnrollment(ctx, adminUser, code)
+}
+
+// DisableTOTP turns two-factor authentication off, unless the admin's role requires it
+func (s *authService) DisableTOTP(ctx context.Context, adminID primitive.ObjectID, code string) error {
+	adminUser, err := s.adminUserRepo.FindByID(ctx, adminID)
+	if err != nil {
+		return err
+	}
+	if !adminUser.TOTPEnabled {
+		return ErrTOTPNotEnabled
+	}
+	if s.totpRequired(adminUser.Role) {
+		return ErrTOTPRequired
+	}
+	if err := s.checkSecondFactor(ctx, adminUser, code); err != nil {
+		return err
+	}
+	adminUser.TOTPEnabled = false
+	adminUser.TOTPSecret = ""
+	adminUser.RecoveryCodes = nil
+	if err := s.saveAdmin(ctx, adminUser); err != nil {
+		return err
+	}
+	log.Printf("[INFO] Admin %s disabled two-factor authentication", adminID.Hex())
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes; the old ones stop working
+func (s *authService) RegenerateRecoveryCodes(ctx context.Context, adminID primitive.ObjectID, code string) ([]string, error) {
+	adminUser, err := s.adminUserRepo.FindByID(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+	if !adminUser.TOTPEnabled {
+		return nil, ErrTOTPNotEnabled
+	}
+	if err := s.checkTOTP(ctx, adminUser, adminUser.TOTPSecret, code); err != nil {
+		return nil, err
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	adminUser.RecoveryCodes = hashes
+	if err := s.saveAdmin(ctx, adminUser); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// ResetTOTP removes the two-factor enrolment of an admin who lost their device. If their
+// role requires it, they enrol again at their next login.
+func (s *authService) ResetTOTP(ctx context.Context, adminID primitive.ObjectID, actor string) (*models.AdminUser, error) {
+	adminUser, err := s.adminUserRepo.FindByID(ctx, adminID)
+	if err != nil {
+		return nil, err
+	}
+	adminUser.TOTPEnabled = false
+	adminUser.TOTPSecret = ""
+	adminUser.TOTPPendingSecret = ""
+	adminUser.RecoveryCodes = nil
+	if err := s.saveAdmin(ctx, adminUser); err != nil {
+		return nil, err
+	}
+	log.Printf("[INFO] ResetTOTP: Two-factor enrolment of admin %s reset by %s", adminID.Hex(), actor)
+	adminUser.Password = ""
+	return adminUser, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
 	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/repositories"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // UserService defines the interface for user-related operations (Add other service interfaces as needed)
@@ -31,12 +32,14 @@ type UserService interface {
 // LegacyUserService handles user-related business logic // Renamed from UserService
 type LegacyUserService struct {
 	 userRepo repositories.UserRepository
+	 audit    AuditService // Records opt-outs made by admins
 }
 
 // NewLegacyUserService creates a new LegacyUserService // Renamed from NewUserService
-func NewLegacyUserService(userRepo repositories.UserRepository) *LegacyUserService { // Renamed return type
+func NewLegacyUserService(userRepo repositories.UserRepository, audit AuditService) *LegacyUserService { // Renamed return type
 	return &LegacyUserService{ // Renamed struct type
 		 userRepo: userRepo,
+		 audit:    audit,
 	}
 }
 
@@ -94,7 +97,14 @@ func (s *LegacyUserService) OptIn(ctx context.Context, msisdn string, channel st
 			 IsBlacklisted: false,
 			 LastActivity: time.Now(),
 		 }
-		 return s.userRepo.Create(ctx, user)
+		 err = s.userRepo.Create(ctx, user)
+		 if !mongo.IsDuplicateKeyError(err) {
+			 return err
+		 }
+		 // Created concurrently, e.g. by topup ingestion; opt that user in instead
+		 if user, err = s.userRepo.FindByMSISDN(ctx, msisdn); err != nil {
+			 return err
+		 }
 	 }
 
 	 // User exists, update opt-in status
@@ -112,10 +122,21 @@ func (s *LegacyUserService) OptOut(ctx context.Context, msisdn string) error {
 		 return err
 	 }
 
+	 before := *user
 	 user.OptInStatus = false
 	 user.OptOutDate = time.Now()
 	 user.LastActivity = time.Now()
-	 return s.userRepo.Update(ctx, user)
+	 if err := s.userRepo.Update(ctx, user); err != nil {
+		 return err
+	 }
+	 s.audit.Record(ctx, &models.AuditEvent{
+		 Action:     models.AuditActionUserOptedOut,
+		 TargetType: models.AuditTargetUser,
+		 TargetID:   user.ID.Hex(),
+		 Changes:    auditChanges(&before, user, "lastActivity", "updatedAt"),
+		 Details:    map[string]string{"msisdn": user.MSISDN},
+	 })
+	 return nil
 }
 
 // AddPoints adds points to a user
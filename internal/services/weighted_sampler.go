@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+)
+
+// weightedSampler draws users without replacement with probability proportional to their
+// points (minimum weight 1). It is backed by a Fenwick tree over the pool, so it needs one
+// int64 per user instead of one slice entry per point, and each pick is O(log n).
+//
+// Picking a random offset in [0, TotalWeight) and locating it by prefix sum selects the same
+// user as indexing the old expanded pool (each user repeated Points times, in pool order),
+// so results for a given RNG stream are unchanged.
+type weightedSampler struct {
+	users     []*models.User
+	tree      []int64 // 1-based Fenwick tree of the remaining weights
+	total     int64
+	remaining int
+}
+
+// userWeight returns the selection weight of a user: their points, but never less than 1
+func userWeight(user *models.User) int64 {
+	if user.Points <= 0 {
+		return 1
+	}
+	return int64(user.Points)
+}
+
+// newWeightedSampler builds a sampler over users in O(n). Entries sharing an MSISDN must be
+// adjacent (pools are sorted by MSISDN before selection) so a pick can remove all of them.
+func newWeightedSampler(users []*models.User) *weightedSampler {
+	n := len(users)
+	s := &weightedSampler{users: users, tree: make([]int64, n+1), remaining: n}
+	for i, user := range users {
+		w := userWeight(user)
+		s.total += w
+		idx := i + 1
+		s.tree[idx] += w
+		if parent := idx + (idx & -idx); parent <= n {
+			s.tree[parent] += s.tree[idx]
+		}
+	}
+	return s
+}
+
+// TotalWeight is the sum of weights of the users still in the sampler
+func (s *weightedSampler) TotalWeight() int64 { return s.total }
+
+// Len is the number of users still in the sampler
+func (s *weightedSampler) Len() int { return s.remaining }
+
+// prefix returns the sum of weights of positions [0, i)
+func (s *weightedSampler) prefix(i int) int64 {
+	var sum int64
+	for ; i > 0; i -= i & -i {
+		sum += s.tree[i]
+	}
+	return sum
+}
+
+// add adds delta to the weight at position i (0-based)
+func (s *weightedSampler) add(i int, delta int64) {
+	for idx := i + 1; idx < len(s.tree); idx += idx & -idx {
+		s.tree[idx] += delta
+	}
+}
+
+// find returns the position whose cumulative weight range contains offset
+func (s *weightedSampler) find(offset int64) int {
+	pos := 0
+	step := 1
+	for step*2 < len(s.tree) {
+		step *= 2
+	}
+	for ; step > 0; step /= 2 {
+		next := pos + step
+		if next < len(s.tree) && s.tree[next] <= offset {
+			pos = next
+			offset -= s.tree[next]
+		}
+	}
+	return pos // 0-based index of the matching entry
+}
+
+// remove zeroes the weight at position i if it is still present
+func (s *weightedSampler) remove(i int) {
+	w := s.prefix(i+1) - s.prefix(i)
+	if w == 0 {
+		return
+	}
+	s.add(i, -w)
+	s.total -= w
+	s.remaining--
+}
+
+// Pick selects a user using rng and removes every entry with the same MSISDN from the sampler
+func (s *weightedSampler) Pick(rng *drawRNG) (*models.User, error) {
+	if s.total <= 0 {
+		return nil, errors.New("cannot select winner from empty pool")
+	}
+	i := s.find(int64(rng.Intn(int(s.total))))
+	winner := s.users[i]
+	s.remove(i)
+	for j := i - 1; j >= 0 && s.users[j].MSISDN == winner.MSISDN; j-- {
+		s.remove(j)
+	}
+	for j := i + 1; j < len(s.users) && s.users[j].MSISDN == winner.MSISDN; j++ {
+		s.remove(j)
+	}
+	return winner, nil
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+)
+
+// testSeed is a fixed hex seed so sampler tests are reproducible
+const testSeed = "6d796e756d62612d646f6e2d77696e2d746573742d73656564"
+
+func newTestRNG(t testing.TB, digest string) *drawRNG {
+	t.Helper()
+	rng, err := newDrawRNG(testSeed, digest)
+	if err != nil {
+		t.Fatalf("newDrawRNG: %v", err)
+	}
+	return rng
+}
+
+// testPool returns n users with distinct, sorted MSISDNs and points cycling through 0..10
+func testPool(n int) []*models.User {
+	users := make([]models.User, n)
+	pool := make([]*models.User, n)
+	for i := range users {
+		users[i].MSISDN = fmt.Sprintf("234%010d", i)
+		users[i].Points = i % 11
+		pool[i] = &users[i]
+	}
+	return pool
+}
+
+func TestWeightedSamplerPicksWithoutReplacement(t *testing.T) {
+	pool := testPool(500)
+	sampler := newWeightedSampler(pool)
+	rng := newTestRNG(t, "without-replacement")
+
+	var wantTotal int64
+	for _, user := range pool {
+		wantTotal += userWeight(user)
+	}
+	if sampler.TotalWeight() != wantTotal {
+		t.Fatalf("TotalWeight = %d, want %d", sampler.TotalWeight(), wantTotal)
+	}
+
+	seen := make(map[string]bool)
+	for sampler.Len() > 0 {
+		before := sampler.TotalWeight()
+		user, err := sampler.Pick(rng)
+		if err != nil {
+			t.Fatalf("Pick with %d users left: %v", sampler.Len(), err)
+		}
+		if seen[user.MSISDN] {
+			t.Fatalf("%s was picked twice", user.MSISDN)
+		}
+		seen[user.MSISDN] = true
+		if got := before - sampler.TotalWeight(); got != userWeight(user) {
+			t.Fatalf("picking %s removed weight %d, want %d", user.MSISDN, got, userWeight(user))
+		}
+	}
+	if len(seen) != len(pool) {
+		t.Fatalf("picked %d users, want %d", len(seen), len(pool))
+	}
+	if sampler.TotalWeight() != 0 {
+		t.Fatalf("TotalWeight = %d after draining the sampler", sampler.TotalWeight())
+	}
+	if _, err := sampler.Pick(rng); err == nil {
+		t.Fatal("Pick on an empty sampler returned no error")
+	}
+}
+
+func TestWeightedSamplerRemovesAllEntriesOfAnMSISDN(t *testing.T) {
+	// Entries for the same subscriber are adjacent once the pool is sorted by MSISDN
+	pool := []*models.User{
+		{MSISDN: "2348030000001", Points: 5},
+		{MSISDN: "2348030000002", Points: 3},
+		{MSISDN: "2348030000002", Points: 4},
+		{MSISDN: "2348030000002", Points: 0},
+		{MSISDN: "2348030000003", Points: 2},
+	}
+	for run := 0; run < 50; run++ {
+		sampler := newWeightedSampler(pool)
+		rng := newTestRNG(t, fmt.Sprintf("msisdn-%d", run))
+		seen := make(map[string]bool)
+		for sampler.Len() > 0 {
+			user, err := sampler.Pick(rng)
+			if err != nil {
+				t.Fatalf("Pick: %v", err)
+			}
+			if seen[user.MSISDN] {
+				t.Fatalf("run %d: %s was picked twice", run, user.MSISDN)
+			}
+			seen[user.MSISDN] = true
+		}
+		if len(seen) != 3 {
+			t.Fatalf("run %d: picked %d subscribers, want 3", run, len(seen))
+		}
+	}
+}
+
+func TestWeightedSamplerFirstPickIsProportionalToWeight(t *testing.T) {
+	// Weights 1 (zero points), 1, 2, 4 and 8
+	pool := []*models.User{
+		{MSISDN: "2348030000001", Points: 0},
+		{MSISDN: "2348030000002", Points: 1},
+		{MSISDN: "2348030000003", Points: 2},
+		{MSISDN: "2348030000004", Points: 4},
+		{MSISDN: "2348030000005", Points: 8},
+	}
+	const draws = 64000
+	rng := newTestRNG(t, "proportional")
+	counts := make(map[string]int)
+	for i := 0; i < draws; i++ {
+		user, err := newWeightedSampler(pool).Pick(rng)
+		if err != nil {
+			t.Fatalf("Pick: %v", err)
+		}
+		counts[user.MSISDN]++
+	}
+
+	const totalWeight = 16
+	for _, user := range pool {
+		p := float64(userWeight(user)) / totalWeight
+		want := p * draws
+		stddev := math.Sqrt(draws * p * (1 - p))
+		if got := float64(counts[user.MSISDN]); math.Abs(got-want) > 5*stddev {
+			t.Errorf("%s (weight %d) picked %.0f times, want %.0f ± %.0f", user.MSISDN, userWeight(user), got, want, 5*stddev)
+		}
+	}
+}
+
+func TestWeightedSamplerMatchesExpandedPool(t *testing.T) {
+	// Each offset must select the user the expanded pool (one entry per point) held there
+	pool := testPool(40)
+	var expanded []*models.User
+	for _, user := range pool {
+		for w := int64(0); w < userWeight(user); w++ {
+			expanded = append(expanded, user)
+		}
+	}
+	sampler := newWeightedSampler(pool)
+	for offset, want := range expanded {
+		if got := pool[sampler.find(int64(offset))]; got != want {
+			t.Fatalf("offset %d selects %s, want %s", offset, got.MSISDN, want.MSISDN)
+		}
+	}
+}
+
+// samplerBytesPerUser is the memory budget of the sampler: one int64 Fenwick node per user
+const samplerBytesPerUser = 8
+
+func TestWeightedSamplerMemoryBudget(t *testing.T) {
+	const n = 1_000_000
+	pool := testPool(n)
+	rng := newTestRNG(t, "budget")
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	sampler := newWeightedSampler(pool)
+	for i := 0; i < 20; i++ {
+		if _, err := sampler.Pick(rng); err != nil {
+			t.Fatalf("Pick: %v", err)
+		}
+	}
+	runtime.ReadMemStats(&after)
+
+	budget := uint64(samplerBytesPerUser*(n+1)) + 64<<10 // Tree plus a little slack for the runtime
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > budget {
+		t.Fatalf("sampler over %d users allocated %d bytes, budget %d", n, allocated, budget)
+	}
+}
+
+var (
+	saturdayPoolOnce sync.Once
+	saturdayPool     []*models.User
+)
+
+// BenchmarkWeightedSamplerSaturdayDraw builds a sampler over 10M subscribers and picks a
+// jackpot winner, its alternates and the consolation winners, as a Saturday draw does.
+// The pool itself is built once outside the timer; B/op is the sampler's own memory,
+// which stays at samplerBytesPerUser per subscriber (~80 MB) however many points they hold.
+func BenchmarkWeightedSamplerSaturdayDraw(b *testing.B) {
+	if testing.Short() {
+		b.Skip("10M-subscriber pool skipped in short mode")
+	}
+	const n = 10_000_000
+	saturdayPoolOnce.Do(func() { saturdayPool = testPool(n) })
+	rng := newTestRNG(b, "saturday")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sampler := newWeightedSampler(saturdayPool)
+		for pick := 0; pick < 24; pick++ { // Jackpot, 3 alternates, 20 consolation prizes
+			if _, err := sampler.Pick(rng); err != nil {
+				b.Fatalf("Pick: %v", err)
+			}
+		}
+	}
+}
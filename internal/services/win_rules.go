@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/exp/slog"
+)
+
+// winFrequencyRulesKey is the SystemConfig key of the win-frequency rules
+const winFrequencyRulesKey = "win_frequency_rules"
+
+// defaultWinFrequencyRules is used when no rules are configured. It only keeps the long-standing
+// guarantee that a jackpot pick cannot also win a consolation prize in the same draw (REQFUNC039).
+func defaultWinFrequencyRules() models.WinFrequencyRules {
+	return models.WinFrequencyRules{WinWindowDays: 7, OnePrizePerDraw: true}
+}
+
+// validateWinFrequencyRules checks that every limit of the rules is usable
+func validateWinFrequencyRules(rules models.WinFrequencyRules) error {
+	if rules.MaxWinsPerWindow < 0 {
+		return fmt.Errorf("maxWinsPerWindow cannot be negative (got %d)", rules.MaxWinsPerWindow)
+	}
+	if rules.MaxWinsPerWindow > 0 && (rules.WinWindowDays < 1 || rules.WinWindowDays > 366) {
+		return fmt.Errorf("winWindowDays must be between 1 and 366 when a win cap is set (got %d)", rules.WinWindowDays)
+	}
+	if rules.JackpotCooldownDays < 0 || rules.JackpotCooldownDays > 366 {
+		return fmt.Errorf("jackpotCooldownDays must be between 0 and 366 (got %d)", rules.JackpotCooldownDays)
+	}
+	return nil
+}
+
+// GetWinFrequencyRules returns the configured win-frequency rules, or the defaults when none
+// are configured
+func (s *DrawServiceImpl) GetWinFrequencyRules(ctx context.Context) (*models.WinFrequencyRules, error) {
+	config, err := s.systemConfigRepo.FindByKey(ctx, winFrequencyRulesKey)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			rules := defaultWinFrequencyRules()
+			return &rules, nil
+		}
+		slog.Error("Failed to fetch win frequency rules config", "error", err, "key", winFrequencyRulesKey)
+		return nil, fmt.Errorf("failed to fetch win frequency rules config: %w", err)
+	}
+
+	// Stored as a JSON string, like the prize structures
+	jsonString, ok := config.Value.(string)
+	if !ok {
+		slog.Error("Invalid win frequency rules format in config (expected JSON string)", "key", winFrequencyRulesKey, "valueType", fmt.Sprintf("%T", config.Value))
+		return nil, fmt.Errorf("invalid win frequency rules format in config %s (expected JSON string)", winFrequencyRulesKey)
+	}
+	var rules models.WinFrequencyRules
+	if err := json.Unmarshal([]byte(jsonString), &rules); err != nil {
+		slog.Error("Failed to unmarshal win frequency rules JSON", "error", err, "key", winFrequencyRulesKey)
+		return nil, fmt.Errorf("failed to parse win frequency rules JSON: %w", err)
+	}
+	if err := validateWinFrequencyRules(rules); err != nil {
+		slog.Error("Invalid win frequency rules in config", "error", err, "key", winFrequencyRulesKey)
+		return nil, fmt.Errorf("invalid win frequency rules config: %w", err)
+	}
+	return &rules, nil
+}
+
+// UpdateWinFrequencyRules validates and stores the win-frequency rules. Draws already executed
+// keep the rules they were executed with.
+func (s *DrawServiceImpl) UpdateWinFrequencyRules(ctx context.Context, rules models.WinFrequencyRules) error {
+	if err := validateWinFrequencyRules(rules); err != nil {
+		return err
+	}
+	var previous interface{} // Left out of the audit diff when the stored rules cannot be read
+	if current, err := s.GetWinFrequencyRules(ctx); err == nil {
+		previous = current
+	}
+	jsonBytes, err := json.Marshal(rules)
+	if err != nil {
+		return fmt.Errorf("failed to marshal win frequency rules: %w", err)
+	}
+	if err := s.systemConfigRepo.UpsertByKey(ctx, winFrequencyRulesKey, string(jsonBytes)); err != nil {
+		slog.Error("Failed to upsert win frequency rules config", "error", err, "key", winFrequencyRulesKey)
+		return fmt.Errorf("failed to save win frequency rules config: %w", err)
+	}
+	slog.Info("Win frequency rules updated successfully", "rules", string(jsonBytes))
+	s.audit.Record(ctx, &models.AuditEvent{
+		Action:     models.AuditActionWinRules,
+		TargetType: models.AuditTargetDrawType,
+		TargetID:   "*", // The rules apply to every draw type
+		Changes:    auditChanges(previous, rules),
+	})
+	return nil
+}
+
+// pickFilter decides whether a user picked for a prize category may be accepted. It returns
+// the name of the rule the pick breaks, or "" to accept it.
+type pickFilter func(user *models.User, category string) (string, error)
+
+// acceptAllPicks is the pickFilter of selections without win-frequency rules
+func acceptAllPicks(*models.User, string) (string, error) { return "", nil }
+
+// winHistoryReader is the read-only part of WinnerRepository needed to apply the win rules
+type winHistoryReader interface {
+	FindByUserID(ctx context.Context, userID primitive.ObjectID) ([]*models.Winner, error)
+}
+
+// winRuleChecker applies win-frequency rules to the picks of one draw. Each user's win
+// history is fetched once and cached.
+type winRuleChecker struct {
+	ctx      context.Context
+	winners  winHistoryReader
+	rules    models.WinFrequencyRules
+	drawID   primitive.ObjectID
+	drawDate time.Time
+	history  map[primitive.ObjectID][]*models.Winner
+}
+
+// newWinRuleChecker creates a checker evaluating wins relative to a draw's date
+func newWinRuleChecker(ctx context.Context, winners winHistoryReader, rules models.WinFrequencyRules, draw *models.Draw) *winRuleChecker {
+	return &winRuleChecker{
+		ctx:      ctx,
+		winners:  winners,
+		rules:    rules,
+		drawID:   draw.ID,
+		drawDate: draw.DrawDate,
+		history:  make(map[primitive.ObjectID][]*models.Winner),
+	}
+}
+
+// accept implements pickFilter. Wins of the draw itself, wins dated after it and wins that
+// were later declared ineligible (voided draws, blacklisted winners) are not counted.
+func (c *winRuleChecker) accept(user *models.User, category string) (string, error) {
+	if c.rules.MaxWinsPerWindow == 0 && c.rules.JackpotCooldownDays == 0 {
+		return "", nil
+	}
+	if user.ID.IsZero() {
+		return "", nil // Legacy snapshot entry without a user reference
+	}
+	wins, ok := c.history[user.ID]
+	if !ok {
+		var err error
+		wins, err = c.winners.FindByUserID(c.ctx, user.ID)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch win history of %s: %w", maskMsisdn(user.MSISDN), err)
+		}
+		c.history[user.ID] = wins
+	}
+
+	capStart := c.drawDate.AddDate(0, 0, -c.rules.WinWindowDays)
+	cooldownStart := c.drawDate.AddDate(0, 0, -c.rules.JackpotCooldownDays)
+	recentWins := 0
+	for _, win := range wins {
+		if win.DrawID == c.drawID || win.ClaimStatus == models.ClaimStatusIneligible || win.WinDate.After(c.drawDate) {
+			continue
+		}
+		if c.rules.JackpotCooldownDays > 0 && win.PrizeCategory == models.JackpotCategory && win.WinDate.After(cooldownStart) {
+			return models.WinRuleJackpotCooldown, nil
+		}
+		if win.WinDate.After(capStart) {
+			recentWins++
+		}
+	}
+	if category != models.JackpotCategory && c.rules.MaxWinsPerWindow > 0 && recentWins >= c.rules.MaxWinsPerWindow {
+		return models.WinRuleCap, nil
+	}
+	return "", nil
+}
+
+// replayWinRuleRejections returns a pickFilter refusing exactly the picks a draw recorded as
+// refused, so verification reproduces the original selection
+func replayWinRuleRejections(rejections []models.WinRuleRejection) pickFilter {
+	if len(rejections) == 0 {
+		return acceptAllPicks
+	}
+	refused := make(map[string]string, len(rejections))
+	for _, r := range rejections {
+		refused[r.Category+":"+r.MSISDN] = r.Rule
+	}
+	return func(user *models.User, category string) (string, error) {
+		return refused[category+":"+user.MSISDN], nil
+	}
+}
+
+// drawOnePrizePerDraw reports whether a draw was executed with the one-prize-per-draw rule.
+// Draws executed before the rules were snapshotted always applied it.
+func drawOnePrizePerDraw(draw *models.Draw) bool {
+	return draw.WinRules == nil || draw.WinRules.OnePrizePerDraw
+}
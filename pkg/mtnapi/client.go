@@ -1,23 +1,53 @@
 package mtnapi
 
 import (
+	"bytes"
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 )
 
-// Client represents an MTN API client
+// Defaults applied by NewClient
+const (
+	DefaultMaxRetries   = 3
+	DefaultRetryBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff   = 30 * time.Second
+	DefaultPageSize     = 500
+	DefaultRangeWindow  = 24 * time.Hour
+
+	maxResponseBytes = 10 << 20 // Larger answers are treated as malformed
+	errorBodySnippet = 512
+)
+
+// Client represents an MTN API client. Requests are signed with APIKey and APISecret (see
+// Sign); failed requests are retried on network errors, 429 and 5xx answers.
 type Client struct {
-	BaseURL   string
+	BaseURL   string // Including any version prefix, e.g. https://api.mtn.com/v1
 	APIKey    string
 	APISecret string
 	MockAPI   bool
-	client    *http.Client
+
+	MaxRetries   int           // Retries after the first attempt
+	RetryBackoff time.Duration // Wait before the first retry, doubling with each further one
+	MaxBackoff   time.Duration // Cap on a single wait, including Retry-After
+	PageSize     int           // Topups requested per page
+	RangeWindow  time.Duration // GetTopups splits its date range into windows of this length
+
+	client *http.Client
 }
 
-// TopupResponse represents a topup response from the MTN API
+// TopupResponse represents a recharge record from the MTN API, as listed by GET /recharges
+// and pushed to the topup webhook
 type TopupResponse struct {
 	MSISDN         string    `json:"msisdn"`
 	Amount         float64   `json:"amount"`
@@ -26,26 +56,258 @@ type TopupResponse struct {
 	Status         string    `json:"status"`
 }
 
+// Successful reports whether the recharge went through; records without a status count as successful
+func (t TopupResponse) Successful() bool {
+	return t.Status == "" || strings.EqualFold(t.Status, "SUCCESS")
+}
+
+// Pagination is the paging block of a recharge listing
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"pageSize"`
+	TotalPages int `json:"totalPages"`
+}
+
+// RechargePage is the body of GET /recharges
+type RechargePage struct {
+	Data       []TopupResponse `json:"data"`
+	Pagination *Pagination     `json:"pagination"`
+}
+
+// SubscriberResponse is the body of GET /subscribers/{msisdn}
+type SubscriberResponse struct {
+	MSISDN string `json:"msisdn"`
+	Active bool   `json:"active"`
+}
+
+// errorBody is the body of an error answer
+type errorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
 // NewClient creates a new MTN API client
 func NewClient(baseURL, apiKey, apiSecret string, mockAPI bool) *Client {
 	return &Client{
-		BaseURL:   baseURL,
-		APIKey:    apiKey,
-		APISecret: apiSecret,
-		MockAPI:   mockAPI,
-		client: &http.Client{Timeout: 10 * time.Second},
+		BaseURL:      baseURL,
+		APIKey:       apiKey,
+		APISecret:    apiSecret,
+		MockAPI:      mockAPI,
+		MaxRetries:   DefaultMaxRetries,
+		RetryBackoff: DefaultRetryBackoff,
+		MaxBackoff:   DefaultMaxBackoff,
+		PageSize:     DefaultPageSize,
+		RangeWindow:  DefaultRangeWindow,
+		client:       &http.Client{Timeout: 10 * time.Second},
 	}
 }
 
-// GetTopups retrieves topups for a given date range
-func (c *Client) GetTopups(startDate, endDate time.Time) ([]TopupResponse, error) {
+// SetHTTPClient replaces the HTTP client, e.g. to change the timeout or transport
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.client = httpClient
+}
+
+// GetTopups retrieves the topups dated in [startDate, endDate). The range is fetched window by
+// window (see RangeWindow), page by page. If a request fails after part of the range was
+// fetched, the topups fetched so far are returned with a *PartialResultError.
+func (c *Client) GetTopups(ctx context.Context, startDate, endDate time.Time) ([]TopupResponse, error) {
 	if c.MockAPI {
 		return c.mockGetTopups(startDate, endDate)
 	}
+	if !startDate.Before(endDate) {
+		return nil, fmt.Errorf("mtn api: start %s is not before end %s", startDate.Format(time.RFC3339), endDate.Format(time.RFC3339))
+	}
+
+	window := c.RangeWindow
+	if window <= 0 {
+		window = DefaultRangeWindow
+	}
+	var topups []TopupResponse
+	for from := startDate; from.Before(endDate); from = from.Add(window) {
+		to := from.Add(window)
+		if to.After(endDate) {
+			to = endDate
+		}
+		windowTopups, err := c.getTopupWindow(ctx, from, to)
+		if err != nil {
+			if len(topups) == 0 && from.Equal(startDate) {
+				return nil, err
+			}
+			return topups, &PartialResultError{Through: from, Fetched: len(topups), Err: err}
+		}
+		topups = append(topups, windowTopups...)
+	}
+	return topups, nil
+}
+
+// getTopupWindow fetches every page of the topups dated in [from, to). A window is only
+// returned whole, so a caller never sees part of one.
+func (c *Client) getTopupWindow(ctx context.Context, from, to time.Time) ([]TopupResponse, error) {
+	pageSize := c.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	var topups []TopupResponse
+	for page, totalPages := 1, 1; page <= totalPages; page++ {
+		query := url.Values{
+			"from":     {from.UTC().Format(time.RFC3339)},
+			"to":       {to.UTC().Format(time.RFC3339)},
+			"page":     {strconv.Itoa(page)},
+			"pageSize": {strconv.Itoa(pageSize)},
+		}
+		body, err := c.do(ctx, http.MethodGet, "/recharges", query)
+		if err != nil {
+			return nil, err
+		}
+		var result RechargePage
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, &MalformedResponseError{Reason: "invalid recharge page JSON: " + err.Error(), Body: snippet(body)}
+		}
+		if result.Pagination == nil || result.Data == nil {
+			return nil, &MalformedResponseError{Reason: "recharge page without data or pagination", Body: snippet(body)}
+		}
+		if result.Pagination.Page != page || result.Pagination.TotalPages < 0 {
+			return nil, &MalformedResponseError{Reason: fmt.Sprintf("asked for page %d, got page %d of %d", page, result.Pagination.Page, result.Pagination.TotalPages), Body: snippet(body)}
+		}
+		for i, topup := range result.Data {
+			if err := validateTopup(topup, from, to); err != nil {
+				return nil, &MalformedResponseError{Reason: fmt.Sprintf("page %d record %d: %v", page, i, err), Body: snippet(body)}
+			}
+		}
+		topups = append(topups, result.Data...)
+		totalPages = result.Pagination.TotalPages
+	}
+	return topups, nil
+}
 
-	// In a real implementation, this would make an HTTP request to the MTN API
-	// For now, we'll just return an error
-	return nil, errors.New("real MTN API not implemented")
+// validateTopup checks a recharge record has what ingestion relies on
+func validateTopup(topup TopupResponse, from, to time.Time) error {
+	switch {
+	case topup.TransactionRef == "":
+		return errors.New("missing transactionRef")
+	case topup.MSISDN == "":
+		return fmt.Errorf("missing msisdn for %s", topup.TransactionRef)
+	case topup.Amount <= 0:
+		return fmt.Errorf("non-positive amount %.2f for %s", topup.Amount, topup.TransactionRef)
+	case topup.Date.Before(from) || !topup.Date.Before(to):
+		return fmt.Errorf("date %s of %s is outside the requested range", topup.Date.Format(time.RFC3339), topup.TransactionRef)
+	}
+	return nil
+}
+
+// do sends a signed request, retrying network errors, 429 and 5xx answers with exponential
+// backoff, and returns the body of the 2xx answer
+func (c *Client) do(ctx context.Context, method, path string, query url.Values) ([]byte, error) {
+	endpoint, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("mtn api: invalid base URL %q: %w", c.BaseURL, err)
+	}
+	endpoint.Path = endpoint.Path + path
+	endpoint.RawQuery = query.Encode()
+
+	for attempt := 0; ; attempt++ {
+		body, retryAfter, err := c.attempt(ctx, method, endpoint)
+		if err == nil {
+			return body, nil
+		}
+		var apiErr *APIError
+		retryable := !errors.As(err, &apiErr) || apiErr.Retryable() // Transport errors are retried
+		if errors.Is(err, ErrMalformedResponse) || ctx.Err() != nil || !retryable || attempt >= c.MaxRetries {
+			return nil, err
+		}
+
+		wait := c.backoff(attempt)
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		if c.MaxBackoff > 0 && wait > c.MaxBackoff {
+			wait = c.MaxBackoff
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt sends one signed request. It returns the body of a 2xx answer, or the error and
+// any Retry-After the server asked for.
+func (c *Client) attempt(ctx context.Context, method string, endpoint *url.URL) ([]byte, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, method, endpoint.String(), nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("mtn api: building request: %w", err)
+	}
+	nonce, err := newNonce()
+	if err != nil {
+		return nil, 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set(HeaderAPIKey, c.APIKey)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderNonce, nonce)
+	req.Header.Set(HeaderSignature, Sign(c.APISecret, method, endpoint.RequestURI(), timestamp, nonce, nil))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("mtn api: %s %s: %w", method, endpoint.Path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		return nil, 0, fmt.Errorf("mtn api: reading %s answer: %w", endpoint.Path, err) // e.g. a truncated body; retried
+	}
+	if len(body) > maxResponseBytes {
+		return nil, 0, &MalformedResponseError{Reason: fmt.Sprintf("answer larger than %d bytes", maxResponseBytes)}
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return body, 0, nil
+	}
+
+	apiErr := &APIError{StatusCode: resp.StatusCode, RequestID: resp.Header.Get("X-Request-ID")}
+	var parsed errorBody
+	if json.Unmarshal(body, &parsed) == nil && (parsed.Code != "" || parsed.Message != "") {
+		apiErr.Code, apiErr.Message = parsed.Code, parsed.Message
+	} else {
+		apiErr.Message = snippet(bytes.TrimSpace(body))
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return nil, apiErr.RetryAfter, apiErr
+}
+
+// backoff is the wait before retry number attempt+1: RetryBackoff doubled per attempt, with
+// up to 50% random jitter so that clients do not retry in step
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := c.RetryBackoff
+	for i := 0; i < attempt && (c.MaxBackoff <= 0 || wait < c.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return wait + time.Duration(rand.Int63n(int64(wait)/2+1))
+}
+
+// newNonce returns a random request nonce
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := crand.Read(b); err != nil {
+		return "", fmt.Errorf("mtn api: generating nonce: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// snippet returns the start of a body for error messages
+func snippet(body []byte) string {
+	if len(body) > errorBodySnippet {
+		return string(body[:errorBodySnippet]) + "..."
+	}
+	return string(body)
 }
 
 // mockGetTopups mocks the GetTopups method for testing
@@ -127,15 +389,29 @@ func (c *Client) mockGetTopups(startDate, endDate time.Time) ([]TopupResponse, e
 	return topups, nil
 }
 
-// VerifyMSISDN verifies if an MSISDN is valid
-func (c *Client) VerifyMSISDN(msisdn string) (bool, error) {
+// VerifyMSISDN verifies if an MSISDN is an active MTN subscriber. An unknown MSISDN (404) is
+// reported as invalid rather than as an error.
+func (c *Client) VerifyMSISDN(ctx context.Context, msisdn string) (bool, error) {
 	if c.MockAPI {
 		return c.mockVerifyMSISDN(msisdn)
 	}
 
-	// In a real implementation, this would make an HTTP request to the MTN API
-	// For now, we'll just return an error
-	return false, errors.New("real MTN API not implemented")
+	body, err := c.do(ctx, http.MethodGet, "/subscribers/"+msisdn, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	var subscriber SubscriberResponse
+	if err := json.Unmarshal(body, &subscriber); err != nil {
+		return false, &MalformedResponseError{Reason: "invalid subscriber JSON: " + err.Error(), Body: snippet(body)}
+	}
+	if subscriber.MSISDN != msisdn {
+		return false, &MalformedResponseError{Reason: fmt.Sprintf("asked for %s, got subscriber %q", msisdn, subscriber.MSISDN), Body: snippet(body)}
+	}
+	return subscriber.Active, nil
 }
 
 // mockVerifyMSISDN mocks the VerifyMSISDN method for testing
@@ -0,0 +1,317 @@
+package mtnapi_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/pkg/mtnapi"
+	"github.com/ArowuTest/bridgetunes-mtn-backend/pkg/mtnapi/mtnapitest"
+)
+
+const (
+	testAPIKey    = "test-key"
+	testAPISecret = "test-secret"
+)
+
+// day0 is the start of the fed range; topups are spread over day0 and the day after
+var day0 = time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+// newFeed starts a server holding n topups, one every 5 hours from day0
+func newFeed(t *testing.T, n int) *mtnapitest.Server {
+	t.Helper()
+	server := mtnapitest.NewServer(testAPIKey, testAPISecret)
+	t.Cleanup(server.Close)
+	for i := 0; i < n; i++ {
+		server.AddTopups(mtnapi.TopupResponse{
+			MSISDN:         fmt.Sprintf("2348030000%03d", i),
+			Amount:         float64(100 * (i + 1)),
+			TransactionRef: fmt.Sprintf("TXN%03d", i),
+			Date:           day0.Add(time.Duration(i) * 5 * time.Hour),
+			Status:         "SUCCESS",
+		})
+	}
+	return server
+}
+
+func transactionRefs(topups []mtnapi.TopupResponse) []string {
+	refs := make([]string, len(topups))
+	for i, topup := range topups {
+		refs[i] = topup.TransactionRef
+	}
+	return refs
+}
+
+func TestClientSignsEveryRequest(t *testing.T) {
+	var captured []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured = append(captured, r.Header.Clone())
+		if !mtnapi.ValidSignature(testAPISecret, r.Method, r.URL.RequestURI(), r.Header.Get(mtnapi.HeaderTimestamp), r.Header.Get(mtnapi.HeaderNonce), nil, r.Header.Get(mtnapi.HeaderSignature)) {
+			t.Errorf("request %s carries an invalid signature", r.URL.RequestURI())
+		}
+		if len(captured) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable) // Retried with a fresh nonce
+			return
+		}
+		w.Write([]byte(`{"msisdn": "2348030000001", "active": true}`))
+	}))
+	defer server.Close()
+
+	client := mtnapi.NewClient(server.URL, testAPIKey, testAPISecret, false)
+	client.RetryBackoff = time.Millisecond
+	if _, err := client.VerifyMSISDN(context.Background(), "2348030000001"); err != nil {
+		t.Fatalf("VerifyMSISDN: %v", err)
+	}
+
+	if len(captured) != 2 {
+		t.Fatalf("server saw %d requests, want 2", len(captured))
+	}
+	for i, header := range captured {
+		if header.Get(mtnapi.HeaderAPIKey) != testAPIKey {
+			t.Errorf("request %d: %s = %q", i, mtnapi.HeaderAPIKey, header.Get(mtnapi.HeaderAPIKey))
+		}
+		seconds, err := strconv.ParseInt(header.Get(mtnapi.HeaderTimestamp), 10, 64)
+		if err != nil || time.Since(time.Unix(seconds, 0)).Abs() > time.Minute {
+			t.Errorf("request %d: %s = %q, want the current Unix time", i, mtnapi.HeaderTimestamp, header.Get(mtnapi.HeaderTimestamp))
+		}
+		if header.Get(mtnapi.HeaderNonce) == "" {
+			t.Errorf("request %d has no %s", i, mtnapi.HeaderNonce)
+		}
+	}
+	if captured[0].Get(mtnapi.HeaderNonce) == captured[1].Get(mtnapi.HeaderNonce) {
+		t.Error("the retry reused the nonce of the first attempt")
+	}
+}
+
+func TestClientWithWrongSecretIsRejectedWithoutRetry(t *testing.T) {
+	server := newFeed(t, 1)
+	client := mtnapi.NewClient(server.URL, testAPIKey, "wrong-secret", false)
+	client.RetryBackoff = time.Millisecond
+
+	_, err := client.GetTopups(context.Background(), day0, day0.Add(24*time.Hour))
+	if !errors.Is(err, mtnapi.ErrUnauthorized) {
+		t.Fatalf("GetTopups error = %v, want ErrUnauthorized", err)
+	}
+	if server.Requests() != 1 {
+		t.Fatalf("server saw %d requests, want 1", server.Requests())
+	}
+}
+
+func TestGetTopupsPagesThroughEveryWindow(t *testing.T) {
+	tests := []struct {
+		name         string
+		topups       int
+		pageSize     int
+		wantRequests int
+	}{
+		// 5 topups on day0 and 5 on the day after (a topup every 5 hours)
+		{name: "one page per window", topups: 10, pageSize: 10, wantRequests: 2},
+		{name: "several pages per window", topups: 10, pageSize: 2, wantRequests: 6},
+		{name: "page size dividing the window evenly", topups: 10, pageSize: 5, wantRequests: 2},
+		{name: "empty feed", topups: 0, pageSize: 3, wantRequests: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFeed(t, tt.topups)
+			client := server.Client()
+			client.PageSize = tt.pageSize
+
+			topups, err := client.GetTopups(context.Background(), day0, day0.Add(48*time.Hour))
+			if err != nil {
+				t.Fatalf("GetTopups: %v", err)
+			}
+			refs := transactionRefs(topups)
+			if len(refs) != tt.topups {
+				t.Fatalf("got %d topups %v, want %d", len(refs), refs, tt.topups)
+			}
+			for i, ref := range refs {
+				if want := fmt.Sprintf("TXN%03d", i); ref != want {
+					t.Fatalf("topup %d is %s, want %s (in feed order)", i, ref, want)
+				}
+			}
+			if server.Requests() != tt.wantRequests {
+				t.Errorf("server saw %d requests, want %d", server.Requests(), tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		faults       []mtnapitest.Fault
+		wantErr      error
+		wantRequests int
+	}{
+		{
+			name:         "5xx then success",
+			faults:       []mtnapitest.Fault{{Status: http.StatusInternalServerError}, {Status: http.StatusBadGateway}},
+			wantRequests: 3,
+		},
+		{
+			name:         "429 with Retry-After then success",
+			faults:       []mtnapitest.Fault{{Status: http.StatusTooManyRequests, RetryAfter: "1", Body: `{"code": "RATE_LIMITED"}`}},
+			wantRequests: 2,
+		},
+		{
+			name:         "truncated answer then success",
+			faults:       []mtnapitest.Fault{{Truncate: true}},
+			wantRequests: 2,
+		},
+		{
+			name: "5xx until retries run out",
+			faults: []mtnapitest.Fault{
+				{Status: http.StatusServiceUnavailable}, {Status: http.StatusServiceUnavailable},
+				{Status: http.StatusServiceUnavailable}, {Status: http.StatusServiceUnavailable},
+			},
+			wantErr:      mtnapi.ErrServerError,
+			wantRequests: 1 + mtnapi.DefaultMaxRetries,
+		},
+		{
+			name:         "429 until retries run out",
+			faults:       []mtnapitest.Fault{{Status: http.StatusTooManyRequests}, {Status: http.StatusTooManyRequests}, {Status: http.StatusTooManyRequests}, {Status: http.StatusTooManyRequests}},
+			wantErr:      mtnapi.ErrRateLimited,
+			wantRequests: 1 + mtnapi.DefaultMaxRetries,
+		},
+		{
+			name:         "4xx is not retried",
+			faults:       []mtnapitest.Fault{{Status: http.StatusBadRequest, Body: `{"code": "INVALID_QUERY", "message": "bad range"}`}},
+			wantErr:      mtnapi.ErrBadRequest,
+			wantRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFeed(t, 3)
+			for i, fault := range tt.faults {
+				server.FailRequest(i+1, fault)
+			}
+			client := server.Client()
+
+			start := time.Now()
+			topups, err := client.GetTopups(context.Background(), day0, day0.Add(24*time.Hour))
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("GetTopups took %s; Retry-After must be capped by MaxBackoff", elapsed)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetTopups error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil || len(topups) != 3 {
+				t.Fatalf("GetTopups = %d topups, %v; want 3 topups", len(topups), err)
+			}
+			if server.Requests() != tt.wantRequests {
+				t.Errorf("server saw %d requests, want %d", server.Requests(), tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestGetTopupsRejectsMalformedPages(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "invalid JSON", body: `{"data": [`},
+		{name: "not an object", body: `"ok"`},
+		{name: "missing pagination", body: `{"data": []}`},
+		{name: "missing data", body: `{"pagination": {"page": 1, "pageSize": 500, "totalPages": 1}}`},
+		{name: "wrong page", body: `{"data": [], "pagination": {"page": 2, "pageSize": 500, "totalPages": 2}}`},
+		{name: "record without transactionRef", body: `{"data": [{"msisdn": "2348030000001", "amount": 100, "date": "2026-03-02T01:00:00Z"}], "pagination": {"page": 1, "pageSize": 500, "totalPages": 1}}`},
+		{name: "record outside the range", body: `{"data": [{"msisdn": "2348030000001", "amount": 100, "transactionRef": "TXN1", "date": "2026-03-05T01:00:00Z"}], "pagination": {"page": 1, "pageSize": 500, "totalPages": 1}}`},
+		{name: "non-positive amount", body: `{"data": [{"msisdn": "2348030000001", "amount": 0, "transactionRef": "TXN1", "date": "2026-03-02T01:00:00Z"}], "pagination": {"page": 1, "pageSize": 500, "totalPages": 1}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFeed(t, 3)
+			server.FailRequest(1, mtnapitest.Fault{Body: tt.body})
+			client := server.Client()
+
+			topups, err := client.GetTopups(context.Background(), day0, day0.Add(24*time.Hour))
+			var malformed *mtnapi.MalformedResponseError
+			if !errors.As(err, &malformed) || !errors.Is(err, mtnapi.ErrMalformedResponse) {
+				t.Fatalf("GetTopups error = %v, want a MalformedResponseError", err)
+			}
+			if topups != nil {
+				t.Errorf("GetTopups returned %d topups with the error", len(topups))
+			}
+			if server.Requests() != 1 {
+				t.Errorf("server saw %d requests, want 1 (malformed answers are not retried)", server.Requests())
+			}
+		})
+	}
+}
+
+func TestGetTopupsReturnsPartialResult(t *testing.T) {
+	server := newFeed(t, 10) // TXN000-004 on day0, TXN005-009 on the day after
+	client := server.Client()
+	client.PageSize = 2
+
+	// Day0 takes 3 pages; the first page of the second window is refused
+	server.FailRequest(4, mtnapitest.Fault{Status: http.StatusBadRequest})
+	topups, err := client.GetTopups(context.Background(), day0, day0.Add(48*time.Hour))
+
+	var partial *mtnapi.PartialResultError
+	if !errors.As(err, &partial) {
+		t.Fatalf("GetTopups error = %v, want a PartialResultError", err)
+	}
+	if !partial.Through.Equal(day0.Add(24*time.Hour)) || partial.Fetched != 5 {
+		t.Errorf("PartialResultError through %s with %d fetched, want %s with 5", partial.Through, partial.Fetched, day0.Add(24*time.Hour))
+	}
+	if !errors.Is(err, mtnapi.ErrBadRequest) {
+		t.Errorf("PartialResultError does not unwrap to the failure: %v", err)
+	}
+	if got := transactionRefs(topups); len(got) != 5 || got[0] != "TXN000" || got[4] != "TXN004" {
+		t.Errorf("partial topups = %v, want TXN000 to TXN004", got)
+	}
+}
+
+func TestGetTopupsFailingFirstWindowIsNotPartial(t *testing.T) {
+	server := newFeed(t, 10)
+	client := server.Client()
+	client.PageSize = 2
+
+	// The second page of the first window is refused: nothing before day0 is complete
+	server.FailRequest(2, mtnapitest.Fault{Status: http.StatusBadRequest})
+	topups, err := client.GetTopups(context.Background(), day0, day0.Add(48*time.Hour))
+
+	var partial *mtnapi.PartialResultError
+	if err == nil || errors.As(err, &partial) {
+		t.Fatalf("GetTopups error = %v, want a plain error", err)
+	}
+	if topups != nil {
+		t.Errorf("GetTopups returned %d topups of an incomplete window", len(topups))
+	}
+}
+
+func TestVerifyMSISDN(t *testing.T) {
+	server := newFeed(t, 0)
+	server.AddSubscriber("2348030000001", true)
+	server.AddSubscriber("2348030000002", false)
+	client := server.Client()
+
+	tests := []struct {
+		msisdn string
+		want   bool
+	}{
+		{msisdn: "2348030000001", want: true},
+		{msisdn: "2348030000002", want: false},
+		{msisdn: "2348030000003", want: false}, // Unknown: 404 is not an error
+	}
+	for _, tt := range tests {
+		got, err := client.VerifyMSISDN(context.Background(), tt.msisdn)
+		if err != nil || got != tt.want {
+			t.Errorf("VerifyMSISDN(%s) = %v, %v; want %v", tt.msisdn, got, err, tt.want)
+		}
+	}
+
+	server.FailRequest(1, mtnapitest.Fault{Body: `{"msisdn": "2348030000009", "active": true}`})
+	if _, err := client.VerifyMSISDN(context.Background(), "2348030000001"); !errors.Is(err, mtnapi.ErrMalformedResponse) {
+		t.Errorf("VerifyMSISDN with another subscriber's answer: error = %v, want ErrMalformedResponse", err)
+	}
+}
@@ -0,0 +1,91 @@
+package mtnapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Errors matched with errors.Is against the typed errors below
+var (
+	ErrUnauthorized      = errors.New("mtn api: credentials rejected")
+	ErrRateLimited       = errors.New("mtn api: rate limited")
+	ErrServerError       = errors.New("mtn api: server error")
+	ErrBadRequest        = errors.New("mtn api: request rejected")
+	ErrMalformedResponse = errors.New("mtn api: malformed response")
+)
+
+// APIError is a non-2xx answer from the MTN API
+type APIError struct {
+	StatusCode int
+	Code       string        // Error code from the body, if any
+	Message    string        // Error message from the body, or the start of the raw body
+	RequestID  string        // X-Request-ID of the response, for MTN support
+	RetryAfter time.Duration // From the Retry-After header; 0 when absent
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("mtn api: HTTP %d", e.StatusCode)
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.RequestID != "" {
+		msg += " (request " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// Is matches the sentinel error of the status code class
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServerError:
+		return e.StatusCode >= 500
+	case ErrBadRequest:
+		return e.StatusCode >= 400 && e.StatusCode < 500 && e.StatusCode != http.StatusTooManyRequests
+	}
+	return false
+}
+
+// Retryable reports whether the same request may succeed later
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// MalformedResponseError is a 2xx answer that could not be decoded or failed validation
+type MalformedResponseError struct {
+	Reason string
+	Body   string // Start of the raw body
+}
+
+func (e *MalformedResponseError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrMalformedResponse, e.Reason)
+}
+
+// Is makes errors.Is(err, ErrMalformedResponse) match
+func (e *MalformedResponseError) Is(target error) bool {
+	return target == ErrMalformedResponse
+}
+
+// PartialResultError is returned by GetTopups when a request failed after some of the range
+// was fetched. The topups returned with it cover [start, Through) completely.
+type PartialResultError struct {
+	Through time.Time // Everything before this time was fetched
+	Fetched int
+	Err     error
+}
+
+func (e *PartialResultError) Error() string {
+	return fmt.Sprintf("mtn api: fetched %d topups through %s before failing: %v", e.Fetched, e.Through.Format(time.RFC3339), e.Err)
+}
+
+func (e *PartialResultError) Unwrap() error {
+	return e.Err
+}
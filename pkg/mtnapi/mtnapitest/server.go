@@ -0,0 +1,239 @@
+// Package mtnapitest provides an in-process stand-in for the MTN recharge API, and signed
+// webhook deliveries, for tests and local development without MTN credentials.
+package mtnapitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/pkg/mtnapi"
+)
+
+// MaxClockSkew is how far a request timestamp may be from the server's clock
+const MaxClockSkew = 5 * time.Minute
+
+// Fault replaces the answer to one request
+type Fault struct {
+	Status     int    // HTTP status; 200 when zero
+	Body       string // Raw body, e.g. truncated or invalid JSON
+	RetryAfter string // Retry-After header, if any
+	// Truncate sends only the first half of the real answer while announcing its full length,
+	// so the client's read fails part-way. Status and Body are ignored.
+	Truncate bool
+}
+
+// Server mimics the MTN recharge API: GET /recharges pages through topups by date range and
+// GET /subscribers/{msisdn} looks up a subscriber. Every request must be signed with the
+// server's API key and secret.
+type Server struct {
+	*httptest.Server
+	APIKey    string
+	APISecret string
+
+	mu          sync.Mutex
+	topups      []mtnapi.TopupResponse
+	subscribers map[string]bool // MSISDN to active
+	faults      map[int]Fault   // Request number (from 1) to the fault answering it
+	requests    int
+	nonces      map[string]bool
+}
+
+// NewServer starts a stand-in server; Close it when done
+func NewServer(apiKey, apiSecret string) *Server {
+	s := &Server{
+		APIKey:      apiKey,
+		APISecret:   apiSecret,
+		subscribers: make(map[string]bool),
+		faults:      make(map[int]Fault),
+		nonces:      make(map[string]bool),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/recharges", s.handleRecharges)
+	mux.HandleFunc("/subscribers/", s.handleSubscriber)
+	s.Server = httptest.NewServer(s.authenticate(mux))
+	return s
+}
+
+// Client returns an mtnapi.Client for the server, with retry waits shortened for tests
+func (s *Server) Client() *mtnapi.Client {
+	client := mtnapi.NewClient(s.URL, s.APIKey, s.APISecret, false)
+	client.RetryBackoff = time.Millisecond
+	client.MaxBackoff = 10 * time.Millisecond
+	return client
+}
+
+// AddTopups adds recharge records to the feed
+func (s *Server) AddTopups(topups ...mtnapi.TopupResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.topups = append(s.topups, topups...)
+	sort.SliceStable(s.topups, func(i, j int) bool { return s.topups[i].Date.Before(s.topups[j].Date) })
+}
+
+// AddSubscriber registers an MSISDN for GET /subscribers
+func (s *Server) AddSubscriber(msisdn string, active bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers[msisdn] = active
+}
+
+// FailRequest makes the n-th request from now on (1 for the next one) get fault instead of its
+// real answer
+func (s *Server) FailRequest(n int, fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults[s.requests+n] = fault
+}
+
+// Requests returns the number of requests served so far, including rejected ones
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// authenticate counts the request, checks its key, timestamp, nonce and signature, and applies
+// any fault queued for it
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests++
+		fault, faulty := s.faults[s.requests]
+		delete(s.faults, s.requests)
+		s.mu.Unlock()
+
+		timestamp := r.Header.Get(mtnapi.HeaderTimestamp)
+		nonce := r.Header.Get(mtnapi.HeaderNonce)
+		if r.Header.Get(mtnapi.HeaderAPIKey) != s.APIKey ||
+			!mtnapi.ValidSignature(s.APISecret, r.Method, r.URL.RequestURI(), timestamp, nonce, nil, r.Header.Get(mtnapi.HeaderSignature)) {
+			writeError(w, http.StatusUnauthorized, "INVALID_SIGNATURE", "missing or invalid signature")
+			return
+		}
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil || time.Since(time.Unix(seconds, 0)).Abs() > MaxClockSkew {
+			writeError(w, http.StatusUnauthorized, "STALE_REQUEST", "timestamp outside the allowed clock skew")
+			return
+		}
+		s.mu.Lock()
+		replayed := s.nonces[nonce]
+		s.nonces[nonce] = true
+		s.mu.Unlock()
+		if replayed {
+			writeError(w, http.StatusUnauthorized, "REPLAYED_REQUEST", "nonce already used")
+			return
+		}
+
+		if !faulty {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if fault.Truncate {
+			recorder := httptest.NewRecorder()
+			next.ServeHTTP(recorder, r)
+			body := recorder.Body.Bytes()
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			w.WriteHeader(recorder.Code)
+			w.Write(body[:len(body)/2])
+			return
+		}
+		if fault.RetryAfter != "" {
+			w.Header().Set("Retry-After", fault.RetryAfter)
+		}
+		status := fault.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(fault.Body))
+	})
+}
+
+// handleRecharges serves GET /recharges?from=&to=&page=&pageSize=
+func (s *Server) handleRecharges(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", r.Method)
+		return
+	}
+	query := r.URL.Query()
+	from, errFrom := time.Parse(time.RFC3339, query.Get("from"))
+	to, errTo := time.Parse(time.RFC3339, query.Get("to"))
+	page, errPage := strconv.Atoi(query.Get("page"))
+	pageSize, errSize := strconv.Atoi(query.Get("pageSize"))
+	if errFrom != nil || errTo != nil || errPage != nil || errSize != nil || page < 1 || pageSize < 1 || !from.Before(to) {
+		writeError(w, http.StatusBadRequest, "INVALID_QUERY", "from, to, page and pageSize are required")
+		return
+	}
+
+	s.mu.Lock()
+	var matching []mtnapi.TopupResponse
+	for _, topup := range s.topups {
+		if !topup.Date.Before(from) && topup.Date.Before(to) {
+			matching = append(matching, topup)
+		}
+	}
+	s.mu.Unlock()
+
+	totalPages := (len(matching) + pageSize - 1) / pageSize
+	start := (page - 1) * pageSize
+	end := start + pageSize
+	if start > len(matching) {
+		start = len(matching)
+	}
+	if end > len(matching) {
+		end = len(matching)
+	}
+	writeJSON(w, http.StatusOK, mtnapi.RechargePage{
+		Data:       append([]mtnapi.TopupResponse{}, matching[start:end]...),
+		Pagination: &mtnapi.Pagination{Page: page, PageSize: pageSize, TotalPages: totalPages},
+	})
+}
+
+// handleSubscriber serves GET /subscribers/{msisdn}
+func (s *Server) handleSubscriber(w http.ResponseWriter, r *http.Request) {
+	msisdn := strings.TrimPrefix(r.URL.Path, "/subscribers/")
+	s.mu.Lock()
+	active, ok := s.subscribers[msisdn]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "SUBSCRIBER_NOT_FOUND", "unknown msisdn")
+		return
+	}
+	writeJSON(w, http.StatusOK, mtnapi.SubscriberResponse{MSISDN: msisdn, Active: active})
+}
+
+// NewWebhookRequest builds the POST MTN would push to a topup webhook at targetURL for recharge,
+// signed with secret as of at
+func NewWebhookRequest(targetURL, secret string, recharge mtnapi.TopupResponse, at time.Time) (*http.Request, error) {
+	body, err := json.Marshal(recharge)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, targetURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(mtnapi.HeaderTimestamp, timestamp)
+	req.Header.Set(mtnapi.HeaderSignature, mtnapi.SignWebhook(secret, timestamp, body))
+	return req, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]string{"code": code, "message": message})
+}
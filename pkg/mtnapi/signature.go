@@ -0,0 +1,54 @@
+package mtnapi
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Request signing headers. Every request carries the API key, the Unix time in seconds, a
+// random nonce and the signature computed by Sign.
+const (
+	HeaderAPIKey    = "X-API-Key"
+	HeaderTimestamp = "X-Timestamp"
+	HeaderNonce     = "X-Nonce"
+	HeaderSignature = "X-Signature"
+)
+
+// Sign returns the hex HMAC-SHA256, keyed with the API secret, of the request's method, path
+// with query string, timestamp, nonce and the SHA-256 of its body, one per line
+func Sign(secret, method, pathAndQuery, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	payload := strings.Join([]string{
+		strings.ToUpper(method),
+		pathAndQuery,
+		timestamp,
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidSignature reports whether signature is the one Sign computes, in constant time
+func ValidSignature(secret, method, pathAndQuery, timestamp, nonce string, body []byte, signature string) bool {
+	expected := Sign(secret, method, pathAndQuery, timestamp, nonce, body)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
+
+// SignWebhook returns the X-Signature of a recharge event MTN pushes: the hex HMAC-SHA256,
+// keyed with the webhook secret, of the X-Timestamp value and the raw body, one per line
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidWebhookSignature reports whether signature is the one SignWebhook computes, in constant time
+func ValidWebhookSignature(secret, timestamp string, body []byte, signature string) bool {
+	expected := SignWebhook(secret, timestamp, body)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
@@ -0,0 +1,129 @@
+package totp_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ArowuTest/bridgetunes-mtn-backend/pkg/totp"
+)
+
+// rfcSecret is the RFC 6238 SHA1 test seed "12345678901234567890" in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// rfcVectors are the RFC 6238 appendix B SHA1 vectors, truncated to the last six of their
+// eight digits as dynamic truncation with six digits yields
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestCodeMatchesRFC6238Vectors(t *testing.T) {
+	for _, vector := range rfcVectors {
+		code, err := totp.Code(rfcSecret, time.Unix(vector.unix, 0))
+		if err != nil {
+			t.Fatalf("Code at %d: %v", vector.unix, err)
+		}
+		if code != vector.code {
+			t.Errorf("Code at %d = %s, want %s", vector.unix, code, vector.code)
+		}
+	}
+}
+
+func TestCodeAcceptsLowerCaseSpacedSecrets(t *testing.T) {
+	secret := strings.ToLower(rfcSecret[:16]) + " " + rfcSecret[16:] + "===="
+	code, err := totp.Code(secret, time.Unix(59, 0))
+	if err != nil || code != "287082" {
+		t.Fatalf("Code = %q, %v; want 287082", code, err)
+	}
+	if _, err := totp.Code("not base32!", time.Unix(59, 0)); err == nil {
+		t.Fatal("Code with an invalid secret returned no error")
+	}
+}
+
+func TestValidateAcceptsCodesWithinTheSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := totp.Counter(now)
+	tests := []struct {
+		name   string
+		offset int64 // Steps between the code and now
+		skew   int
+		want   bool
+	}{
+		{"current step", 0, 0, true},
+		{"previous step without skew", -1, 0, false},
+		{"previous step", -1, 1, true},
+		{"next step", 1, 1, true},
+		{"two steps behind", -2, 1, false},
+		{"two steps ahead", 2, 1, false},
+		{"two steps behind, skew 2", -2, 2, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			code, err := totp.Code(rfcSecret, time.Unix((current+tc.offset)*totp.Period, 0))
+			if err != nil {
+				t.Fatalf("Code: %v", err)
+			}
+			counter, ok, err := totp.Validate(rfcSecret, code, now, tc.skew)
+			if err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			if ok != tc.want {
+				t.Fatalf("Validate = %v, want %v", ok, tc.want)
+			}
+			if ok && counter != current+tc.offset {
+				t.Fatalf("Validate matched step %d, want %d", counter, current+tc.offset)
+			}
+		})
+	}
+}
+
+func TestValidateRefusesMalformedCodes(t *testing.T) {
+	now := time.Unix(59, 0)
+	for _, code := range []string{"", "28708", "2870820", "abcdef", "94287082"} {
+		if _, ok, err := totp.Validate(rfcSecret, code, now, 1); ok || err != nil {
+			t.Errorf("Validate(%q) = %v, %v; want a refusal", code, ok, err)
+		}
+	}
+	if _, ok, err := totp.Validate(rfcSecret, "287 082", now, 0); !ok || err != nil {
+		t.Errorf("Validate with a spaced code = %v, %v; want a match", ok, err)
+	}
+}
+
+func TestProvisioningURIEscapesLabelAndIssuer(t *testing.T) {
+	uri := totp.ProvisioningURI("Bridgetunes MTN", "ops team/1@example.com", rfcSecret)
+
+	const wantPrefix = "otpauth://totp/Bridgetunes%20MTN:ops%20team%2F1@example.com?"
+	if !strings.HasPrefix(uri, wantPrefix) {
+		t.Fatalf("URI = %s, want prefix %s", uri, wantPrefix)
+	}
+	if strings.Contains(uri, "+") {
+		t.Fatalf("URI = %s, spaces must be escaped as %%20", uri)
+	}
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("URI does not parse: %v", err)
+	}
+	want := map[string]string{
+		"secret":    rfcSecret,
+		"issuer":    "Bridgetunes MTN",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	}
+	query := parsed.Query()
+	for key, value := range want {
+		if got := query.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}